@@ -5,32 +5,52 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
+	_ "time/tzdata" // タイムゾーンデータがない環境（コンテナなど）でもTIMEZONEを解釈できるようにする
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/dice/hxs_reservation_system/internal/commands"
 	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
 	"github.com/dice/hxs_reservation_system/internal/storage"
 	"github.com/joho/godotenv"
 )
 
 const (
 	saveInterval       = 5 * time.Minute
+	reminderInterval   = time.Minute
+	noShowInterval     = time.Minute
+	waitlistInterval   = time.Minute
 	logCleanupInterval = 24 * time.Hour
 	autoCompleteHour   = 3
 	autoCompleteMinute = 0
 	cleanupHour        = 3
 	cleanupMinute      = 10
 	retentionDays      = 30
+
+	defaultResourcesFile = "config/resources.json"
 )
 
 var (
-	store                 *storage.Storage
+	store                 storage.Repository
 	logger                *logging.Logger
 	guildID               string
 	allowedChannelID      string
+	storageBackend        string
+	storagePath           string
+	resourcesFile         string
+	preferencesPath       string
+	penaltiesPath         string
+	waitlistPath          string
+	reminderBefore        string
+	noShowAfter           string
+	timezone              string
+	openingHours          string
+	adminRoleIDs          []string
 	processedInteractions sync.Map
 )
 
@@ -41,6 +61,64 @@ func init() {
 
 	guildID = os.Getenv("GUILD_ID")
 	allowedChannelID = os.Getenv("ALLOWED_CHANNEL_ID")
+	storageBackend = os.Getenv("STORAGE_BACKEND")
+	storagePath = os.Getenv("STORAGE_PATH")
+	resourcesFile = os.Getenv("RESOURCES_FILE")
+	if resourcesFile == "" {
+		resourcesFile = defaultResourcesFile
+	}
+	preferencesPath = os.Getenv("PREFERENCES_PATH")
+	if preferencesPath == "" {
+		preferencesPath = storage.DefaultPreferencesPath
+	}
+	penaltiesPath = os.Getenv("PENALTIES_PATH")
+	if penaltiesPath == "" {
+		penaltiesPath = storage.DefaultPenaltiesPath
+	}
+	waitlistPath = os.Getenv("WAITLIST_PATH")
+	if waitlistPath == "" {
+		waitlistPath = storage.DefaultWaitlistPath
+	}
+	reminderBefore = os.Getenv("REMINDER_BEFORE")
+	noShowAfter = os.Getenv("NO_SHOW_AFTER")
+	timezone = os.Getenv("TIMEZONE")
+	openingHours = os.Getenv("OPENING_HOURS")
+	adminRoleIDs = parseIDList(os.Getenv("ADMIN_ROLE_IDS"))
+}
+
+// parseDurationSetting は時間の長さの設定を読み取る（未設定の場合は fallback、不正な値の場合は終了する）
+func parseDurationSetting(name, value string, fallback time.Duration, example string) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Fatalf("Failed to parse %s %q: expected a duration such as %s", name, value, example)
+	}
+	return d
+}
+
+// parseIntSetting は回数・件数の設定を読み取る（未設定の場合は fallback、不正な値の場合は終了する）
+func parseIntSetting(name, value string, fallback int, example string) int {
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Fatalf("Failed to parse %s %q: expected a number such as %s", name, value, example)
+	}
+	return n
+}
+
+// parseIDList はカンマ区切りのIDリストを分割する
+func parseIDList(value string) []string {
+	ids := []string{}
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
 }
 
 func main() {
@@ -79,11 +157,81 @@ func main() {
 }
 
 func initializeServices() {
-	store = storage.NewStorage()
+	loc, err := models.LoadLocation(timezone)
+	if err != nil {
+		log.Fatalf("Failed to load timezone: %v", err)
+	}
+	models.SetLocation(loc)
+	log.Printf("Timezone: %s", loc)
+
+	repo, err := storage.Open(storageBackend, storagePath)
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+	store = repo
 	if err := store.Load(); err != nil {
 		log.Fatalf("Failed to load reservations: %v", err)
 	}
-	log.Println("Reservations loaded successfully")
+	log.Printf("Reservations loaded successfully (backend: %s)", storageBackendName())
+
+	resources, err := storage.LoadResources(resourcesFile)
+	if err != nil {
+		log.Fatalf("Failed to load resources: %v", err)
+	}
+	commands.SetResources(resources)
+	log.Printf("Resources loaded successfully (%d room(s))", len(resources))
+
+	hours, err := models.ParseOpeningHours(openingHours)
+	if err != nil {
+		log.Fatalf("Failed to parse opening hours: %v", err)
+	}
+	commands.SetOpeningHours(hours)
+	log.Printf("Opening hours: %s", hours)
+
+	preferences := storage.NewPreferences(preferencesPath)
+	if err := preferences.Load(); err != nil {
+		log.Fatalf("Failed to load preferences: %v", err)
+	}
+	commands.SetPreferences(preferences)
+
+	lead := parseDurationSetting("REMINDER_BEFORE", reminderBefore, commands.DefaultReminderLead, "30m or 1h (0 disables reminders)")
+	commands.SetReminderLead(lead)
+	log.Printf("Reminders: %v before start", lead)
+
+	grace := parseDurationSetting("NO_SHOW_AFTER", noShowAfter, commands.DefaultNoShowGrace, "15m (0 disables the release)")
+	commands.SetNoShowGrace(grace)
+	log.Printf("No-show release: %v after start", grace)
+
+	penalties := storage.NewPenalties(penaltiesPath)
+	if err := penalties.Load(); err != nil {
+		log.Fatalf("Failed to load penalties: %v", err)
+	}
+	commands.SetPenalties(penalties)
+
+	policy := models.DefaultPenaltyPolicy
+	policy.LateCancelWithin = parseDurationSetting("PENALTY_LATE_CANCEL", os.Getenv("PENALTY_LATE_CANCEL"), policy.LateCancelWithin, "3h (0 does not count late cancellations)")
+	policy.Threshold = parseIntSetting("PENALTY_STRIKES", os.Getenv("PENALTY_STRIKES"), policy.Threshold, "3 (0 disables restrictions)")
+	policy.Window = parseDurationSetting("PENALTY_WINDOW", os.Getenv("PENALTY_WINDOW"), policy.Window, "720h")
+	policy.RestrictFor = parseDurationSetting("PENALTY_DURATION", os.Getenv("PENALTY_DURATION"), policy.RestrictFor, "336h")
+	policy.MaxActive = parseIntSetting("PENALTY_MAX_ACTIVE", os.Getenv("PENALTY_MAX_ACTIVE"), policy.MaxActive, "1 (0 blocks new reservations)")
+	commands.SetPenaltyPolicy(policy)
+	log.Printf("Penalties: %d strike(s) within %v restrict to %d reservation(s) for %v (late cancellation: %v before start)",
+		policy.Threshold, policy.Window, policy.MaxActive, policy.RestrictFor, policy.LateCancelWithin)
+
+	waitlist := storage.NewWaitlist(waitlistPath)
+	if err := waitlist.Load(); err != nil {
+		log.Fatalf("Failed to load waitlist: %v", err)
+	}
+	commands.SetWaitlist(waitlist)
+	offerFor := parseDurationSetting("WAITLIST_OFFER_FOR", os.Getenv("WAITLIST_OFFER_FOR"), commands.DefaultWaitlistOfferFor, "15m")
+	if offerFor <= 0 {
+		log.Fatalf("Failed to parse WAITLIST_OFFER_FOR %q: the offer must last longer than 0", os.Getenv("WAITLIST_OFFER_FOR"))
+	}
+	commands.SetWaitlistOfferFor(offerFor)
+	log.Printf("Waitlist: offers last %v", offerFor)
+
+	commands.SetAdminRoles(adminRoleIDs)
+	log.Printf("Admin roles configured (%d role(s))", len(adminRoleIDs))
 
 	logger = logging.NewLogger("./logs")
 	log.Println("Logger initialized successfully")
@@ -95,17 +243,24 @@ func setupHandlers(dg *discordgo.Session) {
 			return
 		}
 
-		if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+		switch i.Type {
+		case discordgo.InteractionApplicationCommandAutocomplete:
 			commands.HandleAutocomplete(s, i, store)
-			return
+		case discordgo.InteractionMessageComponent:
+			commands.HandleComponent(s, i, store, logger, allowedChannelID)
+		case discordgo.InteractionModalSubmit:
+			commands.HandleModalSubmit(s, i, store, logger, allowedChannelID)
+		default:
+			commands.HandleInteraction(s, i, store, logger, allowedChannelID)
 		}
-
-		commands.HandleInteraction(s, i, store, logger, allowedChannelID)
 	})
 }
 
 func startBackgroundTasks(dg *discordgo.Session) {
 	go periodicSave(dg)
+	go periodicReminders(dg)
+	go periodicNoShowRelease(dg)
+	go periodicWaitlist(dg)
 	go periodicLogCleanup()
 	go dailyAutoComplete()
 	go dailyCleanup()
@@ -125,6 +280,52 @@ func periodicSave(dg *discordgo.Session) {
 	}
 }
 
+// periodicReminders は開始が近い予約のリマインダーを定期的に送信する
+func periodicReminders(dg *discordgo.Session) {
+	ticker := time.NewTicker(reminderInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		count, err := commands.SendDueReminders(dg, store, logger)
+		if err != nil {
+			log.Printf("❌ Failed to send reminders: %v", err)
+			logger.LogError("ERROR", "periodicReminders", "Failed to send reminders", err, nil)
+		} else if count > 0 {
+			log.Printf("⏰ Sent %d reminder(s)", count)
+		}
+	}
+}
+
+// periodicNoShowRelease はチェックインのない予約を定期的に解放する
+func periodicNoShowRelease(dg *discordgo.Session) {
+	ticker := time.NewTicker(noShowInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		count, err := commands.ReleaseNoShows(dg, store, logger, allowedChannelID)
+		if err != nil {
+			log.Printf("❌ Failed to release no-show reservations: %v", err)
+			logger.LogError("ERROR", "periodicNoShowRelease", "Failed to release no-show reservations", err, nil)
+		} else if count > 0 {
+			log.Printf("👻 Released %d no-show reservation(s)", count)
+			updateBotStatus(dg, store)
+		}
+	}
+}
+
+// periodicWaitlist は空いた時間帯のキャンセル待ちへの案内と、期限切れの案内の整理を定期的に行う
+func periodicWaitlist(dg *discordgo.Session) {
+	ticker := time.NewTicker(waitlistInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		count, err := commands.ProcessWaitlist(dg, store, logger)
+		if err != nil {
+			log.Printf("❌ Failed to process waitlist: %v", err)
+			logger.LogError("ERROR", "periodicWaitlist", "Failed to process waitlist", err, nil)
+		} else if count > 0 {
+			log.Printf("🎟️ Sent %d waitlist offer(s)", count)
+		}
+	}
+}
+
 func periodicLogCleanup() {
 	ticker := time.NewTicker(logCleanupInterval)
 	defer ticker.Stop()
@@ -180,11 +381,12 @@ func logTaskResult(taskName string, count int, err error, itemName string) {
 	}
 }
 
+// waitUntilTime は部室のタイムゾーンで次に hour:minute になるまでの時間を返す
 func waitUntilTime(hour, minute int) time.Duration {
-	now := time.Now()
+	now := models.Now()
 	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
 	if !now.Before(next) {
-		next = next.Add(24 * time.Hour)
+		next = time.Date(now.Year(), now.Month(), now.Day()+1, hour, minute, 0, 0, now.Location())
 	}
 	duration := time.Until(next)
 	log.Printf("Next task scheduled at: %s (in %v)", next.Format("2006-01-02 15:04:05"), duration)
@@ -206,9 +408,20 @@ func shutdown() {
 		log.Println("✅ Reservations saved successfully")
 	}
 
+	if err := store.Close(); err != nil {
+		log.Printf("❌ Failed to close storage: %v", err)
+	}
+
 	printStats()
 }
 
+func storageBackendName() string {
+	if storageBackend == "" {
+		return storage.BackendJSON
+	}
+	return storageBackend
+}
+
 func printStats() {
 	stats := logger.GetStats()
 	log.Println("=== コマンド統計 ===")
@@ -224,10 +437,10 @@ func printStats() {
 	log.Printf("最終更新: %s", stats.LastUpdated.Format("2006-01-02 15:04:05"))
 }
 
-func updateBotStatus(s *discordgo.Session, store *storage.Storage) {
+func updateBotStatus(s *discordgo.Session, store storage.Repository) {
 	pendingCount := 0
 	for _, r := range store.GetAllReservations() {
-		if r.Status == "pending" {
+		if r.Status.IsActive() {
 			pendingCount++
 		}
 	}
@@ -300,6 +513,21 @@ func createCommand(s *discordgo.Session, cmd *discordgo.ApplicationCommand) erro
 }
 
 func getCommandDefinitions() []*discordgo.ApplicationCommand {
+	minOccurrences := 1.0
+	scopeChoices := []*discordgo.ApplicationCommandOptionChoice{
+		{Name: "この予約のみ", Value: "this"},
+		{Name: "この予約以降", Value: "following"},
+		{Name: "シリーズ全体", Value: "series"},
+	}
+	durationChoices := []*discordgo.ApplicationCommandOptionChoice{
+		{Name: "30分", Value: 30},
+		{Name: "1時間", Value: 60},
+		{Name: "1時間30分", Value: 90},
+		{Name: "2時間", Value: 120},
+		{Name: "3時間", Value: 180},
+		{Name: "4時間", Value: 240},
+	}
+
 	return []*discordgo.ApplicationCommand{
 		{
 			Name:        "reserve",
@@ -308,21 +536,101 @@ func getCommandDefinitions() []*discordgo.ApplicationCommand {
 				{
 					Type:         discordgo.ApplicationCommandOptionString,
 					Name:         "date",
-					Description:  "予約日（YYYY-MM-DD または YYYY/MM/DD、例: 2025-10-15 または 2025/10/15）",
+					Description:  "予約日（例: 2025/10/15、明日、来週火曜、金曜 15時）※開始時間と両方省略すると入力フォームを表示",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "start_time",
+					Description:  "開始時間（例: 14:00、15時半、3pm）※日付と両方省略すると入力フォームを表示",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "end_time",
+					Description:  "終了時間（例: 15:00、2時間）※省略時は開始時刻+1時間、開始より前なら翌日",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "duration",
+					Description:  "利用時間（例: 30m、1h30m、2時間）※end_time の代わりに指定",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "room",
+					Description:  "部屋（省略時は部室）",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "comment",
+					Description: "コメント（任意）",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "reserve-recurring",
+			Description: "毎週・隔週・毎月の繰り返し予約をまとめて作成します",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "date",
+					Description:  "初回の予約日（例: 2025/10/15、来週火曜）",
 					Required:     true,
 					Autocomplete: true,
 				},
 				{
 					Type:         discordgo.ApplicationCommandOptionString,
 					Name:         "start_time",
-					Description:  "開始時間（HH:MM形式、例: 14:00）",
+					Description:  "開始時間（例: 14:00、15時半、3pm）",
 					Required:     true,
 					Autocomplete: true,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "frequency",
+					Description: "繰り返しの間隔",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "毎週", Value: string(models.FrequencyWeekly)},
+						{Name: "隔週", Value: string(models.FrequencyBiweekly)},
+						{Name: "毎月", Value: string(models.FrequencyMonthly)},
+					},
+				},
 				{
 					Type:         discordgo.ApplicationCommandOptionString,
 					Name:         "end_time",
-					Description:  "終了時間（HH:MM形式、例: 15:00）※省略時は開始時刻+1時間",
+					Description:  "終了時間（例: 15:00、2時間）※省略時は開始時刻+1時間、開始より前なら翌日",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "until",
+					Description:  "最終日（この日まで繰り返す）※until か count のどちらかを指定",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "count",
+					Description: "回数 ※until か count のどちらかを指定",
+					Required:    false,
+					MinValue:    &minOccurrences,
+					MaxValue:    models.MaxOccurrences,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "room",
+					Description:  "部屋（省略時は部室）",
 					Required:     false,
 					Autocomplete: true,
 				},
@@ -345,6 +653,13 @@ func getCommandDefinitions() []*discordgo.ApplicationCommand {
 					Required:     true,
 					Autocomplete: true,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "scope",
+					Description: "繰り返し予約の取り消す範囲（省略時はこの予約のみ）",
+					Required:    false,
+					Choices:     scopeChoices,
+				},
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "comment",
@@ -372,6 +687,19 @@ func getCommandDefinitions() []*discordgo.ApplicationCommand {
 				},
 			},
 		},
+		{
+			Name:        "checkin",
+			Description: "予約にチェックインして利用中にします",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "reservation_id",
+					Description:  "予約ID（省略時は今チェックインできる自分の予約）",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+		},
 		{
 			Name:        "edit",
 			Description: "予約を編集します",
@@ -383,24 +711,45 @@ func getCommandDefinitions() []*discordgo.ApplicationCommand {
 					Required:     true,
 					Autocomplete: true,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "scope",
+					Description: "繰り返し予約の変更する範囲（省略時はこの予約のみ）",
+					Required:    false,
+					Choices:     scopeChoices,
+				},
 				{
 					Type:         discordgo.ApplicationCommandOptionString,
 					Name:         "date",
-					Description:  "新しい予約日（YYYY-MM-DD または YYYY/MM/DD）※変更しない場合は省略",
+					Description:  "新しい予約日（例: 2025/10/15、金曜 15時）※変更しない場合は省略",
 					Required:     false,
 					Autocomplete: true,
 				},
 				{
 					Type:         discordgo.ApplicationCommandOptionString,
 					Name:         "start_time",
-					Description:  "新しい開始時間（HH:MM形式）※変更しない場合は省略",
+					Description:  "新しい開始時間（例: 14:00、15時半）※変更しない場合は省略",
 					Required:     false,
 					Autocomplete: true,
 				},
 				{
 					Type:         discordgo.ApplicationCommandOptionString,
 					Name:         "end_time",
-					Description:  "新しい終了時間（HH:MM形式）※変更しない場合は省略",
+					Description:  "新しい終了時間（例: 15:00、2時間）※変更しない場合は省略",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "duration",
+					Description:  "新しい利用時間（例: 30m、1h30m、2時間）※end_time の代わりに指定",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "room",
+					Description:  "新しい部屋 ※変更しない場合は省略",
 					Required:     false,
 					Autocomplete: true,
 				},
@@ -415,11 +764,232 @@ func getCommandDefinitions() []*discordgo.ApplicationCommand {
 		{
 			Name:        "list",
 			Description: "すべての予約を表示します（自分だけに表示されます）",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "date",
+					Description:  "この日付以降の予約に絞り込み（例: 2025/10/15、来週月曜、任意）",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "until",
+					Description:  "この日付以前の予約に絞り込み（例: 2025/10/31、来週日曜、任意）",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "予約者で絞り込み（任意）",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "room",
+					Description:  "部屋で絞り込み（任意）",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "status",
+					Description: "状態で絞り込み（省略時は予約中のみ）",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "予約中", Value: "pending"},
+						{Name: "完了", Value: "completed"},
+						{Name: "キャンセル", Value: "cancelled"},
+						{Name: "無断欠席", Value: "no_show"},
+						{Name: "すべて", Value: "all"},
+					},
+				},
+			},
+		},
+		{
+			Name:        "today",
+			Description: "今日の予約を時間割で表示します（自分だけに表示されます）",
+		},
+		{
+			Name:        "week",
+			Description: "1週間（月曜〜日曜）の予約を時間割で表示します（自分だけに表示されます）",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "date",
+					Description:  "この日付を含む週を表示（省略時は今週）",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "room",
+					Description:  "部屋で絞り込み（省略時はすべての部屋）",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Name:        "calendar",
+			Description: "1週間（月曜〜日曜）の予約を画像で表示します（自分だけに表示されます）",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "date",
+					Description:  "この日付を含む週を表示（省略時は今週）",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "room",
+					Description:  "部屋で絞り込み（省略時はすべての部屋）",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "color_by",
+					Description: "色分けの基準（省略時はメンバーごと）",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "メンバーごと", Value: "member"},
+						{Name: "部屋ごと", Value: "room"},
+					},
+				},
+			},
+		},
+		{
+			Name:        "availability",
+			Description: "空き時間を表示します（自分だけに表示されます）",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "date",
+					Description:  "日付（省略時は今日）",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "until",
+					Description:  "この日付までまとめて表示（最大7日分）",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "duration",
+					Description: "必要な最短時間（省略時は30分）",
+					Required:    false,
+					Choices:     durationChoices,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "room",
+					Description:  "部屋（省略時は部室）",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
 		},
 		{
 			Name:        "my-reservations",
 			Description: "自分の予約を表示します（自分だけに表示されます）",
 		},
+		{
+			Name:        "history",
+			Description: "予約の変更履歴を表示します（自分だけに表示されます）",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "reservation_id",
+					Description:  "予約ID",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Name:        "waitlist",
+			Description: "埋まっている時間帯のキャンセル待ちに登録します（省略時は登録中の一覧を表示）",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "date",
+					Description:  "希望日（例: 2025/10/15、明日、金曜 15時）",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "start_time",
+					Description:  "開始時間（例: 14:00、15時半）",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "end_time",
+					Description:  "終了時間（例: 15:00）※省略時は開始時刻+1時間、開始より前なら翌日",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "room",
+					Description:  "部屋（省略時は部室）",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "comment",
+					Description: "予約に付けるコメント（任意）",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "reminders",
+			Description: "予約開始前のリマインダー（DM）の設定を表示・変更します",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "setting",
+					Description: "リマインダーを受け取るか（省略時は現在の設定を表示）",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "受け取る", Value: "on"},
+						{Name: "受け取らない", Value: "off"},
+					},
+				},
+			},
+		},
+		{
+			Name:        "penalties",
+			Description: "違反の記録を表示・リセットします（管理者のみ）",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "対象のユーザー（省略時は違反のあるユーザーの一覧）",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "action",
+					Description: "操作（省略時は表示）",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "表示", Value: "show"},
+						{Name: "リセット", Value: "reset"},
+					},
+				},
+			},
+		},
 		{
 			Name:        "help",
 			Description: "ヘルプメッセージを表示します（自分だけに表示されます）",
@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+const (
+	maxAlternatives        = 5 // 重複時に提案する候補の最大数
+	maxSameDayAlternatives = 3 // そのうち同じ日の候補の最大数（残りは前日・翌日）
+)
+
+// pendingEdit は編集の候補ボタンから使う編集内容
+type pendingEdit struct {
+	ReservationID string
+	Request       editRequest
+}
+
+// findAlternatives は重複した時間帯と同じ長さで空いている近くの時間帯を返す
+// 同じ日の前後から希望の開始時刻に近いものを優先し、前日・翌日の同じ時刻付近も候補にする
+// excludeID の予約（編集中の予約自身）は空きとして扱う
+func findAlternatives(store storage.Repository, resourceID, excludeID string, slot slotInput) ([]models.TimeSlot, error) {
+	requested := &models.Reservation{Date: slot.Date, EndDate: slot.EndDate, StartTime: slot.StartTime, EndTime: slot.EndTime}
+	start, err := requested.GetStartDateTime()
+	if err != nil {
+		return nil, err
+	}
+	end, err := requested.GetEndDateTime()
+	if err != nil {
+		return nil, err
+	}
+	length := end.Sub(start)
+
+	day, err := time.Parse("2006-01-02", slot.Date)
+	if err != nil {
+		return nil, err
+	}
+	reservations, err := store.QueryReservations(storage.Query{
+		ResourceID: resourceID,
+		Statuses:   models.ActiveStatuses,
+		DateFrom:   day.AddDate(0, 0, -1).Format("2006-01-02"),
+		DateTo:     day.AddDate(0, 0, 2).Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	others := make([]*models.Reservation, 0, len(reservations))
+	for _, r := range reservations {
+		if r.ID != excludeID {
+			others = append(others, r)
+		}
+	}
+	busy := models.BusySlots(others)
+
+	earliest := models.Now().Add(availabilityStep - 1).Truncate(availabilityStep)
+	distance := func(slot models.TimeSlot) time.Duration {
+		if d := slot.Start.Sub(start); d >= 0 {
+			return d
+		}
+		return start.Sub(slot.Start)
+	}
+
+	// 同じ日の近い候補を優先し、前日・翌日はそれぞれ最も近い候補を加え、足りない分は残りの近い候補で埋める
+	picked, rest := []models.TimeSlot{}, []models.TimeSlot{}
+	for _, offset := range []int{0, -1, 1} {
+		target := start.AddDate(0, 0, offset)
+		window, err := openingHours.Window(day.AddDate(0, 0, offset).Format("2006-01-02"))
+		if err != nil {
+			return nil, err
+		}
+		// 利用可能時間の外の予約でも、希望と同じ時間帯は候補に含める
+		if target.Before(window.Start) {
+			window.Start = target
+		}
+		if target.Add(length).After(window.End) {
+			window.End = target.Add(length)
+		}
+		if window.Start.Before(earliest) {
+			window.Start = earliest
+		}
+		if !window.Start.Before(window.End) {
+			continue
+		}
+
+		candidates := models.NearestSlots(models.FreeSlots(window, busy, length), length, target, availabilityStep)
+		sort.Slice(candidates, func(a, b int) bool {
+			return distance(candidates[a]) < distance(candidates[b])
+		})
+		limit := 1
+		if offset == 0 {
+			limit = maxSameDayAlternatives
+		}
+		for idx, candidate := range candidates {
+			if idx < limit {
+				picked = append(picked, candidate)
+			} else {
+				rest = append(rest, candidate)
+			}
+		}
+	}
+
+	sort.Slice(rest, func(a, b int) bool {
+		return distance(rest[a]) < distance(rest[b])
+	})
+	for _, candidate := range rest {
+		if len(picked) >= maxAlternatives {
+			break
+		}
+		picked = append(picked, candidate)
+	}
+	if len(picked) > maxAlternatives {
+		picked = picked[:maxAlternatives]
+	}
+
+	sort.Slice(picked, func(a, b int) bool {
+		return picked[a].Start.Before(picked[b].Start)
+	})
+	return picked, nil
+}
+
+// formatAlternative は候補の時間帯を "10/15 14:00 - 翌01:00" 形式にする
+func formatAlternative(slot models.TimeSlot) string {
+	end := slot.End.Format("15:04")
+	if slot.End.Format("2006-01-02") != slot.Start.Format("2006-01-02") {
+		end = "翌" + end
+	}
+	return fmt.Sprintf("%s %s - %s", slot.Start.Format("1/2"), slot.Start.Format("15:04"), end)
+}
+
+// alternativeComponents は候補の一覧のフィールドと、候補をそのまま予約するボタンを作成する
+// action のボタンには token と候補の日付・開始時間・終了時間を渡す
+func alternativeComponents(action, token string, alternatives []models.TimeSlot) (*discordgo.MessageEmbedField, []discordgo.MessageComponent) {
+	lines := []string{}
+	buttons := []discordgo.Button{}
+	for _, slot := range alternatives {
+		lines = append(lines, "• "+formatAlternative(slot))
+		buttons = append(buttons, discordgo.Button{
+			Label:    formatAlternative(slot),
+			Style:    discordgo.SuccessButton,
+			CustomID: newCustomID(action, token, slot.Start.Format("2006-01-02"), slot.Start.Format("15:04"), slot.End.Format("15:04")),
+		})
+	}
+	field := &discordgo.MessageEmbedField{
+		Name:   "💡 空いている近くの時間帯",
+		Value:  "近くに空いている時間帯はありません",
+		Inline: false,
+	}
+	if len(lines) > 0 {
+		field.Value = joinLines(lines) + "\nボタンを押すと、その時間で予約します。"
+	}
+	return field, buttonRows(buttons)
+}
+
+// offerAlternatives は重複時の候補を探し、候補のフィールドとボタンを作成する
+// 候補の検索や入力の保持に失敗した場合は、候補なしとして扱う
+func offerAlternatives(store storage.Repository, logger *logging.Logger, action string, pending interface{}, resourceID, excludeID string, slot slotInput) (*discordgo.MessageEmbedField, []discordgo.MessageComponent) {
+	alternatives, err := findAlternatives(store, resourceID, excludeID, slot)
+	if err != nil {
+		logger.LogError("ERROR", "offerAlternatives", "Failed to find alternative slots", err, map[string]interface{}{
+			"resource_id": resourceID,
+			"date":        slot.Date,
+		})
+		alternatives = nil
+	}
+
+	token := ""
+	if len(alternatives) > 0 {
+		if token, err = pendingInputs.put(pending); err != nil {
+			alternatives = nil
+		}
+	}
+	return alternativeComponents(action, token, alternatives)
+}
+
+// parseAlternativeArgs はボタンの引数（トークン, 日付, 開始時間, 終了時間）を分解する
+func parseAlternativeArgs(args []string) (token, date, startTime, endTime string, ok bool) {
+	if len(args) != 4 {
+		return "", "", "", "", false
+	}
+	return args[0], args[1], args[2], args[3], true
+}
+
+// handleReserveAlternativeButton は重複時の候補ボタンが押されたときに、その時間帯で予約を作成する
+func handleReserveAlternativeButton(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool, args []string) {
+	token, date, startTime, endTime, ok := parseAlternativeArgs(args)
+	if !ok {
+		respondError(s, i, "この操作は利用できません。もう一度コマンドを実行してください。")
+		return
+	}
+	value, found := pendingInputs.get(token)
+	req, isReserve := value.(reserveRequest)
+	if !found || !isReserve {
+		respondError(s, i, "候補の有効期限が切れました。もう一度 /reserve を実行してください。")
+		return
+	}
+
+	req.Date, req.StartTime, req.EndTime = date, startTime, endTime
+	if createReservation(s, i, store, logger, allowedChannelID, isDM, "reserve", req) {
+		// 同じ候補の一覧から重ねて予約できないようにする
+		pendingInputs.remove(token)
+	}
+}
+
+// handleEditAlternativeButton は編集の重複時の候補ボタンが押されたときに、その時間帯に予約を変更する
+func handleEditAlternativeButton(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool, args []string) {
+	token, date, startTime, endTime, ok := parseAlternativeArgs(args)
+	if !ok {
+		respondError(s, i, "この操作は利用できません。もう一度コマンドを実行してください。")
+		return
+	}
+	value, found := pendingInputs.get(token)
+	edit, isEdit := value.(pendingEdit)
+	if !found || !isEdit {
+		respondError(s, i, "候補の有効期限が切れました。もう一度 /edit を実行してください。")
+		return
+	}
+
+	reservation, err := store.GetReservation(edit.ReservationID)
+	if err != nil {
+		respondError(s, i, "指定された予約が見つかりません。")
+		return
+	}
+	a := getActor(i, isDM)
+	if !a.canModify(reservation) {
+		respondError(s, i, "他のユーザーの予約は編集できません。")
+		return
+	}
+	if reservation.Status != models.StatusPending {
+		respondError(s, i, "利用中・完了・キャンセルされた予約は編集できません。")
+		return
+	}
+
+	req := edit.Request
+	req.Date, req.StartTime, req.EndTime = date, startTime, endTime
+	if applyEdit(s, i, store, logger, allowedChannelID, isDM, a, reservation, req) {
+		pendingInputs.remove(token)
+	}
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestFindAlternatives(t *testing.T) {
+	store := newTestStore(t)
+	old := openingHours
+	SetOpeningHours(models.OpeningHours{Open: "09:00", Close: "22:00"})
+	t.Cleanup(func() { SetOpeningHours(old) })
+
+	now := models.Now()
+	prev := now.AddDate(0, 0, 9).Format("2006-01-02")
+	day := now.AddDate(0, 0, 10).Format("2006-01-02")
+	next := now.AddDate(0, 0, 11).Format("2006-01-02")
+	for _, r := range []*models.Reservation{
+		{ID: "busy", Date: day, EndDate: day, StartTime: "13:00", EndTime: "15:00", Status: models.StatusPending, ResourceID: models.DefaultResourceID},
+		{ID: "self", Date: day, EndDate: day, StartTime: "15:00", EndTime: "17:00", Status: models.StatusPending, ResourceID: models.DefaultResourceID},
+		{ID: "other-room", Date: prev, EndDate: prev, StartTime: "09:00", EndTime: "22:00", Status: models.StatusPending, ResourceID: "other"},
+	} {
+		if err := store.AddReservation(r); err != nil {
+			t.Fatalf("AddReservation failed: %v", err)
+		}
+	}
+	slot := slotInput{Date: day, EndDate: day, StartTime: "14:00", EndTime: "15:00"}
+
+	tests := []struct {
+		name      string
+		excludeID string
+		want      []string
+	}{
+		{
+			name: "前後と前日・翌日の候補",
+			want: []string{
+				prev + " 14:00",
+				day + " 12:00",
+				day + " 17:00",
+				next + " 14:00",
+			},
+		},
+		{
+			name:      "編集中の予約は空きとして扱う",
+			excludeID: "self",
+			want: []string{
+				prev + " 14:00",
+				day + " 12:00",
+				day + " 15:00",
+				next + " 14:00",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alternatives, err := findAlternatives(store, models.DefaultResourceID, tt.excludeID, slot)
+			if err != nil {
+				t.Fatalf("findAlternatives failed: %v", err)
+			}
+			if len(alternatives) != len(tt.want) {
+				t.Fatalf("Expected %d alternatives, got %d", len(tt.want), len(alternatives))
+			}
+			for idx, alternative := range alternatives {
+				if got := alternative.Start.Format("2006-01-02 15:04"); got != tt.want[idx] {
+					t.Errorf("Alternative %d: expected %s, got %s", idx, tt.want[idx], got)
+				}
+				if alternative.Duration().Hours() != 1 {
+					t.Errorf("Alternative %d: expected the same length as the request, got %s", idx, alternative.Duration())
+				}
+			}
+		})
+	}
+}
+
+func TestAlternativeComponentsCustomID(t *testing.T) {
+	window, _ := models.OpeningHours{Open: "23:00", Close: "24:00"}.Window("2030-01-10")
+	field, rows := alternativeComponents(actionReserveAlternative, "0123456789ab", []models.TimeSlot{window})
+	if field.Value == "" || len(rows) != 1 {
+		t.Fatalf("Expected a field and one row of buttons, got %d row(s)", len(rows))
+	}
+	if got := formatAlternative(window); got != "1/10 23:00 - 翌00:00" {
+		t.Errorf("Unexpected label %q", got)
+	}
+
+	button := rows[0].(discordgo.ActionsRow).Components[0].(discordgo.Button)
+	action, args := parseCustomID(button.CustomID)
+	token, date, startTime, endTime, ok := parseAlternativeArgs(args)
+	if action != actionReserveAlternative || !ok || token != "0123456789ab" || date != "2030-01-10" || startTime != "23:00" || endTime != "00:00" {
+		t.Errorf("Unexpected custom ID %q", button.CustomID)
+	}
+}
+
+func TestPendingStore(t *testing.T) {
+	store := newPendingStore()
+	token, err := store.put(reserveRequest{Comment: "面接"})
+	if err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	value, ok := store.get(token)
+	if req, isReserve := value.(reserveRequest); !ok || !isReserve || req.Comment != "面接" {
+		t.Errorf("Expected the stored request, got %v", value)
+	}
+
+	store.remove(token)
+	if _, ok := store.get(token); ok {
+		t.Error("Expected the removed token to be gone")
+	}
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// adminRoleIDs は管理者として扱うDiscordロールIDの集合
+var adminRoleIDs = map[string]bool{}
+
+// errForbidden は予約を操作する権限がない場合に返される
+var errForbidden = errors.New("not allowed to modify this reservation")
+
+// errNotActive は予約中・利用中でない（完了・キャンセル済み・無断欠席の）予約を変更しようとした場合に返される
+var errNotActive = errors.New("reservation is no longer active")
+
+// notActiveMessage は予約中・利用中でない予約を取り消し・完了・延長しようとしたときに表示する文面
+const notActiveMessage = "この予約は既に完了・キャンセル済み、または無断欠席として解放されています。"
+
+// SetAdminRoles は管理者として扱うDiscordロールIDを設定する
+func SetAdminRoles(roleIDs []string) {
+	roles := make(map[string]bool, len(roleIDs))
+	for _, id := range roleIDs {
+		if id != "" {
+			roles[id] = true
+		}
+	}
+	adminRoleIDs = roles
+}
+
+// actor はコマンドを実行したユーザー
+type actor struct {
+	UserID   string
+	Username string
+	Admin    bool // 管理者ロールを持っているか
+}
+
+// getActor はインタラクションから実行ユーザーと管理者かどうかを取得する
+// DMではロールが分からないため、管理者としては扱わない
+func getActor(i *discordgo.InteractionCreate, isDM bool) actor {
+	userID, username := getUserInfo(i, isDM)
+	return actor{UserID: userID, Username: username, Admin: isAdmin(i)}
+}
+
+// isAdmin はインタラクションを実行したメンバーが管理者ロールを持っているかを返す
+func isAdmin(i *discordgo.InteractionCreate) bool {
+	if i.Member == nil {
+		return false
+	}
+	for _, roleID := range i.Member.Roles {
+		if adminRoleIDs[roleID] {
+			return true
+		}
+	}
+	return false
+}
+
+// canModify は予約を変更できるか（予約者本人または管理者）を返す
+func (a actor) canModify(r *models.Reservation) bool {
+	return r.UserID == a.UserID || a.Admin
+}
+
+// overrides は管理者権限で他のユーザーの予約を操作するかを返す
+func (a actor) overrides(r *models.Reservation) bool {
+	return r.UserID != a.UserID && a.Admin
+}
+
+// authorize は予約を変更できない場合に errForbidden を返す（Mutate・UpdateIfFree の中で使う）
+func (a actor) authorize(r *models.Reservation) error {
+	if !a.canModify(r) {
+		return errForbidden
+	}
+	return nil
+}
+
+// logOverride は管理者による他のユーザーの予約の操作を記録する
+func (a actor) logOverride(logger *logging.Logger, action string, r *models.Reservation, details map[string]interface{}) {
+	if !a.overrides(r) {
+		return
+	}
+	logger.LogAdminAction(action, a.UserID, a.Username, r.ID, r.UserID, details)
+}
+
+// appendOverrideField は管理者が操作した場合に操作者のフィールドを追加する
+func (a actor) appendOverrideField(fields []*discordgo.MessageEmbedField, r *models.Reservation) []*discordgo.MessageEmbedField {
+	if !a.overrides(r) {
+		return fields
+	}
+	return append(fields, &discordgo.MessageEmbedField{
+		Name:   "🛡️ 管理者による操作",
+		Value:  fmt.Sprintf("<@%s>", a.UserID),
+		Inline: false,
+	})
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// useAdminRoles はテスト中だけ管理者ロールを設定する
+func useAdminRoles(t *testing.T, roleIDs ...string) {
+	t.Helper()
+	old := adminRoleIDs
+	SetAdminRoles(roleIDs)
+	t.Cleanup(func() { adminRoleIDs = old })
+}
+
+// guildInteraction は指定したロールを持つメンバーのインタラクションを作る
+func guildInteraction(userID string, roles ...string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Member: &discordgo.Member{
+			User:  &discordgo.User{ID: userID, Username: userID},
+			Roles: roles,
+		},
+	}}
+}
+
+func TestActorAuthorization(t *testing.T) {
+	useAdminRoles(t, "admin-role")
+	reservation := &models.Reservation{ID: "r1", UserID: "owner"}
+
+	tests := []struct {
+		name          string
+		actor         actor
+		wantModify    bool
+		wantOverrides bool
+	}{
+		{"owner", getActor(guildInteraction("owner"), false), true, false},
+		{"other member", getActor(guildInteraction("other", "member-role"), false), false, false},
+		{"admin", getActor(guildInteraction("admin", "member-role", "admin-role"), false), true, true},
+		{"admin owner", getActor(guildInteraction("owner", "admin-role"), false), true, false},
+		{"dm", getActor(&discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+			User: &discordgo.User{ID: "admin"},
+		}}, true), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.actor.canModify(reservation); got != tt.wantModify {
+				t.Errorf("canModify = %v, want %v", got, tt.wantModify)
+			}
+			if got := tt.actor.overrides(reservation); got != tt.wantOverrides {
+				t.Errorf("overrides = %v, want %v", got, tt.wantOverrides)
+			}
+			if err := tt.actor.authorize(reservation); (err == nil) != tt.wantModify {
+				t.Errorf("authorize = %v, want allowed %v", err, tt.wantModify)
+			}
+		})
+	}
+}
+
+func TestSetAdminRolesIgnoresEmptyIDs(t *testing.T) {
+	useAdminRoles(t, "", "admin-role")
+	if len(adminRoleIDs) != 1 || !adminRoleIDs["admin-role"] {
+		t.Errorf("Expected only admin-role, got %v", adminRoleIDs)
+	}
+	if isAdmin(guildInteraction("someone")) {
+		t.Error("Member without roles should not be admin")
+	}
+}
@@ -10,10 +10,11 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/dice/hxs_reservation_system/internal/models"
 	"github.com/dice/hxs_reservation_system/internal/storage"
+	"github.com/dice/hxs_reservation_system/internal/timeparse"
 )
 
 // HandleAutocomplete はオートコンプリートのリクエストを処理する
-func HandleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage) {
+func HandleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository) {
 	data := i.ApplicationCommandData()
 
 	// 現在フォーカスされているオプションを取得
@@ -35,20 +36,35 @@ func HandleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, st
 	commandName := data.Name
 
 	switch focusedOption.Name {
-	case "date":
+	case "date", "until":
 		choices = getDateSuggestions(focusedOption.StringValue())
 	case "start_time":
-		choices = getTimeSuggestions(focusedOption.StringValue(), "")
+		// 日付が分かっている場合は、予約で埋まっている時刻を除いて空き時間を表示する
+		// キャンセル待ちは埋まっている時間帯を指定するため、空き時間に絞り込まない
+		if target := getAutocompleteTarget(store, commandName, data.Options); target.HasDate && commandName != "waitlist" {
+			choices = getAvailableStartSuggestions(store, focusedOption.StringValue(), target)
+		} else {
+			choices = getTimeSuggestions(focusedOption.StringValue(), "")
+		}
 	case "end_time":
-		// end_timeの場合、start_timeを取得して考慮する
-		var startTime string
-		for _, opt := range data.Options {
-			if opt.Name == "start_time" {
-				startTime = opt.StringValue()
-				break
+		// 開始日時が分かっている場合は、次の予約までの終了時刻だけを表示する
+		if target := getAutocompleteTarget(store, commandName, data.Options); target.HasStart && commandName != "waitlist" {
+			choices = getAvailableEndSuggestions(store, focusedOption.StringValue(), target)
+		} else {
+			var startTime string
+			for _, opt := range data.Options {
+				if opt.Name == "start_time" {
+					startTime = opt.StringValue()
+					break
+				}
 			}
+			choices = getTimeSuggestions(focusedOption.StringValue(), startTime)
 		}
-		choices = getTimeSuggestions(focusedOption.StringValue(), startTime)
+	case "duration":
+		target := getAutocompleteTarget(store, commandName, data.Options)
+		choices = getDurationSuggestions(store, focusedOption.StringValue(), target)
+	case "room":
+		choices = getResourceSuggestions(focusedOption.StringValue())
 	case "reservation_id":
 		// ユーザーIDを取得
 		var userID string
@@ -59,8 +75,15 @@ func HandleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, st
 		}
 
 		// コマンドに応じて候補を生成
-		if commandName == "cancel" || commandName == "complete" || commandName == "edit" {
-			choices = getReservationSuggestions(store, userID, "pending", focusedOption.StringValue())
+		switch commandName {
+		case "edit", "checkin":
+			choices = getReservationSuggestions(store, userID, isAdmin(i), []models.ReservationStatus{models.StatusPending}, focusedOption.StringValue())
+		case "cancel", "complete":
+			// 利用中（チェックイン済み）の予約も完了・取り消しできる
+			choices = getReservationSuggestions(store, userID, isAdmin(i), models.ActiveStatuses, focusedOption.StringValue())
+		case "history":
+			// 履歴は完了・キャンセル済みの予約も対象
+			choices = getReservationSuggestions(store, userID, isAdmin(i), nil, focusedOption.StringValue())
 		}
 	}
 
@@ -82,18 +105,60 @@ func HandleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, st
 	}
 }
 
+// weekdayNamesJP は候補に表示する曜日（time.Weekday の順）
+var weekdayNamesJP = [...]string{"日", "月", "火", "水", "木", "金", "土"}
+
 // getDateSuggestions は日付の候補を生成する
+// 「来週火曜」「next fri 3pm」のように解釈できる入力は、解釈した日時を先頭の候補にする
 func getDateSuggestions(input string) []*discordgo.ApplicationCommandOptionChoice {
-	now := time.Now()
-	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
-	nowJST := now.In(jst)
+	suggestions := getCalendarDateSuggestions(input)
+	result, err := timeparse.ParseDateTime(input, models.Now())
+	if input == "" || err != nil || !result.HasDate {
+		return suggestions
+	}
+	value := result.Date.Format("2006/01/02")
+	name := fmt.Sprintf("%s（%s）", value, weekdayNamesJP[result.Date.Weekday()])
+	if result.HasTime {
+		value += " " + result.Clock
+		name += " " + result.Clock
+	}
+	return prependChoice(suggestions, &discordgo.ApplicationCommandOptionChoice{
+		Name:  truncateChoiceName(fmt.Sprintf("%s → %s", input, name)),
+		Value: value,
+	})
+}
+
+// prependChoice は候補を先頭に追加する（同じ値の候補は取り除く）
+func prependChoice(choices []*discordgo.ApplicationCommandOptionChoice, choice *discordgo.ApplicationCommandOptionChoice) []*discordgo.ApplicationCommandOptionChoice {
+	result := []*discordgo.ApplicationCommandOptionChoice{choice}
+	for _, c := range choices {
+		if c.Value != choice.Value {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// truncateChoiceName は候補の表示名を100文字に収める
+func truncateChoiceName(name string) string {
+	if len([]rune(name)) > 100 {
+		return string([]rune(name)[:97]) + "..."
+	}
+	return name
+}
+
+// getCalendarDateSuggestions は今日からの日付や、入力された年・月・日から日付の候補を生成する
+func getCalendarDateSuggestions(input string) []*discordgo.ApplicationCommandOptionChoice {
+	// 日付は部室のタイムゾーンで計算する
+	now := models.Now()
+	loc := models.Location()
 
 	// 入力が空の場合
 	if input == "" {
 		suggestions := []*discordgo.ApplicationCommandOptionChoice{
-			{Name: "今日", Value: nowJST.Format("2006/01/02")},
-			{Name: "明日", Value: nowJST.AddDate(0, 0, 1).Format("2006/01/02")},
-			{Name: "明後日", Value: nowJST.AddDate(0, 0, 2).Format("2006/01/02")},
+			{Name: "今日", Value: now.Format("2006/01/02")},
+			{Name: "明日", Value: now.AddDate(0, 0, 1).Format("2006/01/02")},
+			{Name: "明後日", Value: now.AddDate(0, 0, 2).Format("2006/01/02")},
 		}
 
 		// 3日後から30日後まで
@@ -102,12 +167,12 @@ func getDateSuggestions(input string) []*discordgo.ApplicationCommandOptionChoic
 				week := i / 7
 				suggestions = append(suggestions, &discordgo.ApplicationCommandOptionChoice{
 					Name:  fmt.Sprintf("%d週間後", week),
-					Value: nowJST.AddDate(0, 0, i).Format("2006/01/02"),
+					Value: now.AddDate(0, 0, i).Format("2006/01/02"),
 				})
 			} else {
 				suggestions = append(suggestions, &discordgo.ApplicationCommandOptionChoice{
-					Name:  nowJST.AddDate(0, 0, i).Format("2006/01/02"),
-					Value: nowJST.AddDate(0, 0, i).Format("2006/01/02"),
+					Name:  now.AddDate(0, 0, i).Format("2006/01/02"),
+					Value: now.AddDate(0, 0, i).Format("2006/01/02"),
 				})
 			}
 		}
@@ -117,11 +182,11 @@ func getDateSuggestions(input string) []*discordgo.ApplicationCommandOptionChoic
 	// 月の候補を生成（1-12の入力を月として優先的に扱う）
 	if len(input) <= 2 {
 		if monthNum, err := strconv.Atoi(input); err == nil && monthNum >= 1 && monthNum <= 12 {
-			year := nowJST.Year()
+			year := now.Year()
 			suggestions := []*discordgo.ApplicationCommandOptionChoice{}
 			for yearOffset := 0; yearOffset <= 1 && len(suggestions) < 25; yearOffset++ {
 				targetYear := year + yearOffset
-				daysInMonth := time.Date(targetYear, time.Month(monthNum+1), 0, 0, 0, 0, 0, jst).Day()
+				daysInMonth := time.Date(targetYear, time.Month(monthNum+1), 0, 0, 0, 0, 0, loc).Day()
 
 				for day := 1; day <= daysInMonth && len(suggestions) < 25; day++ {
 					dateStr := fmt.Sprintf("%d/%02d/%02d", targetYear, monthNum, day)
@@ -141,7 +206,7 @@ func getDateSuggestions(input string) []*discordgo.ApplicationCommandOptionChoic
 	// 年の候補を生成（13以上の2桁入力、または月候補がない場合）
 	if len(input) == 2 {
 		if yearNum, err := strconv.Atoi(input); err == nil {
-			currentYear := nowJST.Year()
+			currentYear := now.Year()
 			currentCentury := (currentYear / 100) * 100
 			fullYear := currentCentury + yearNum
 
@@ -169,8 +234,8 @@ func getDateSuggestions(input string) []*discordgo.ApplicationCommandOptionChoic
 	// 日の候補を生成
 	if len(input) <= 2 {
 		if dayNum, err := strconv.Atoi(input); err == nil && dayNum >= 1 && dayNum <= 31 {
-			year := nowJST.Year()
-			month := int(nowJST.Month())
+			year := now.Year()
+			month := int(now.Month())
 			suggestions := []*discordgo.ApplicationCommandOptionChoice{}
 
 			for monthOffset := 0; monthOffset <= 3 && len(suggestions) < 25; monthOffset++ {
@@ -182,7 +247,7 @@ func getDateSuggestions(input string) []*discordgo.ApplicationCommandOptionChoic
 					targetMonth -= 12
 				}
 
-				daysInMonth := time.Date(targetYear, time.Month(targetMonth+1), 0, 0, 0, 0, 0, jst).Day()
+				daysInMonth := time.Date(targetYear, time.Month(targetMonth+1), 0, 0, 0, 0, 0, loc).Day()
 
 				if dayNum <= daysInMonth {
 					dateStr := fmt.Sprintf("%d/%02d/%02d", targetYear, targetMonth, dayNum)
@@ -201,9 +266,9 @@ func getDateSuggestions(input string) []*discordgo.ApplicationCommandOptionChoic
 
 	// 通常のフィルタリング処理
 	allSuggestions := []*discordgo.ApplicationCommandOptionChoice{
-		{Name: "今日", Value: nowJST.Format("2006/01/02")},
-		{Name: "明日", Value: nowJST.AddDate(0, 0, 1).Format("2006/01/02")},
-		{Name: "明後日", Value: nowJST.AddDate(0, 0, 2).Format("2006/01/02")},
+		{Name: "今日", Value: now.Format("2006/01/02")},
+		{Name: "明日", Value: now.AddDate(0, 0, 1).Format("2006/01/02")},
+		{Name: "明後日", Value: now.AddDate(0, 0, 2).Format("2006/01/02")},
 	}
 
 	for i := 3; i <= 30; i++ {
@@ -211,12 +276,12 @@ func getDateSuggestions(input string) []*discordgo.ApplicationCommandOptionChoic
 			week := i / 7
 			allSuggestions = append(allSuggestions, &discordgo.ApplicationCommandOptionChoice{
 				Name:  fmt.Sprintf("%d週間後", week),
-				Value: nowJST.AddDate(0, 0, i).Format("2006/01/02"),
+				Value: now.AddDate(0, 0, i).Format("2006/01/02"),
 			})
 		} else {
 			allSuggestions = append(allSuggestions, &discordgo.ApplicationCommandOptionChoice{
-				Name:  nowJST.AddDate(0, 0, i).Format("2006/01/02"),
-				Value: nowJST.AddDate(0, 0, i).Format("2006/01/02"),
+				Name:  now.AddDate(0, 0, i).Format("2006/01/02"),
+				Value: now.AddDate(0, 0, i).Format("2006/01/02"),
 			})
 		}
 	}
@@ -240,31 +305,63 @@ func getDateSuggestions(input string) []*discordgo.ApplicationCommandOptionChoic
 }
 
 // getTimeSuggestions は時刻の候補を生成する
+// startTime が指定された場合（終了時間の候補）は、開始時刻から30分刻みで最大12時間後までを候補にし、
+// 日付を跨ぐ時刻には「翌」を付けて表示する
 func getTimeSuggestions(input string, startTime string) []*discordgo.ApplicationCommandOptionChoice {
-	// 9:00から21:00まで30分刻みで候補を生成
+	suggestions := getStepTimeSuggestions(input, startTime)
+
+	// 「15時半」「3pm」（終了時間では「2時間」も）のように解釈できる入力は、解釈した時刻を先頭の候補にする
+	if input == "" {
+		return suggestions
+	}
+	var clock string
+	var inputErr *inputError
+	if start, err := timeparse.ParseClock(startTime); startTime != "" && err == nil {
+		clock, inputErr = parseEndTimeInput(start, input)
+	} else {
+		clock, inputErr = parseClockInput(input, "時刻")
+	}
+	if inputErr != nil || clock == input {
+		// 解釈できない入力と HH:MM 形式の入力は、30分刻みの候補の絞り込みだけを行う
+		return suggestions
+	}
+	return prependChoice(suggestions, &discordgo.ApplicationCommandOptionChoice{
+		Name:  truncateChoiceName(fmt.Sprintf("%s → %s", input, clock)),
+		Value: clock,
+	})
+}
+
+// getStepTimeSuggestions は30分刻みの時刻の候補を生成する
+func getStepTimeSuggestions(input string, startTime string) []*discordgo.ApplicationCommandOptionChoice {
 	suggestions := []*discordgo.ApplicationCommandOptionChoice{}
-	for hour := 9; hour <= 21; hour++ {
-		for _, minute := range []int{0, 30} {
-			if hour == 21 && minute == 30 {
-				break // 21:00で終了
+	if clock, err := timeparse.ParseClock(startTime); startTime != "" && err == nil {
+		start, _ := time.Parse("15:04", clock)
+		for step := 1; step <= 24; step++ {
+			end := start.Add(time.Duration(step) * 30 * time.Minute)
+			timeStr := end.Format("15:04")
+			name := timeStr
+			if end.Day() != start.Day() {
+				name = "翌" + timeStr
 			}
-			timeStr := fmt.Sprintf("%02d:%02d", hour, minute)
 			suggestions = append(suggestions, &discordgo.ApplicationCommandOptionChoice{
-				Name:  timeStr,
+				Name:  name,
 				Value: timeStr,
 			})
 		}
-	}
-
-	// end_timeの場合、start_timeより後の時刻のみフィルタリング
-	if startTime != "" {
-		var filtered []*discordgo.ApplicationCommandOptionChoice
-		for _, choice := range suggestions {
-			if choice.Value.(string) > startTime {
-				filtered = append(filtered, choice)
+	} else {
+		// 9:00から21:00まで30分刻みで候補を生成
+		for hour := 9; hour <= 21; hour++ {
+			for _, minute := range []int{0, 30} {
+				if hour == 21 && minute == 30 {
+					break // 21:00で終了
+				}
+				timeStr := fmt.Sprintf("%02d:%02d", hour, minute)
+				suggestions = append(suggestions, &discordgo.ApplicationCommandOptionChoice{
+					Name:  timeStr,
+					Value: timeStr,
+				})
 			}
 		}
-		suggestions = filtered
 	}
 
 	// 入力がある場合、さらにフィルタリング
@@ -283,38 +380,325 @@ func getTimeSuggestions(input string, startTime string) []*discordgo.Application
 	return suggestions
 }
 
-// getReservationSuggestions はユーザーの予約候補を生成する
-func getReservationSuggestions(store *storage.Storage, userID string, status string, input string) []*discordgo.ApplicationCommandOptionChoice {
-	suggestions := []*discordgo.ApplicationCommandOptionChoice{}
-	reservations := store.GetUserReservations(userID)
+// autocompleteTarget は入力中のオプションから分かる予約の日付・開始日時と部屋
+type autocompleteTarget struct {
+	Day        time.Time // 予約日（部室のタイムゾーンの0時）
+	HasDate    bool      // 予約日が分かっているか
+	Start      time.Time // 開始日時（部室のタイムゾーン）
+	HasStart   bool      // 日付と開始時間が分かっているか
+	ResourceID string    // 部屋ID
+	ExcludeID  string    // 空き状況の判定から除く予約ID（/edit で編集中の予約）
+}
 
-	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
-	now := time.Now().In(jst)
+// at は予約日の指定した時刻（HH:MM形式）を返す
+func (t autocompleteTarget) at(clock string) time.Time {
+	parsed, _ := time.Parse("15:04", clock)
+	return time.Date(t.Day.Year(), t.Day.Month(), t.Day.Day(), parsed.Hour(), parsed.Minute(), 0, 0, models.Location())
+}
 
-	var filteredReservations []*models.Reservation
+// getAutocompleteTarget は入力済みのオプションから予約の日付・開始日時と部屋を求める
+// /edit では指定されていない項目に編集中の予約の値を使う
+func getAutocompleteTarget(store storage.Repository, commandName string, options []*discordgo.ApplicationCommandInteractionDataOption) autocompleteTarget {
+	values := map[string]string{}
+	for _, opt := range options {
+		if !opt.Focused && opt.Type == discordgo.ApplicationCommandOptionString {
+			values[opt.Name] = opt.StringValue()
+		}
+	}
+
+	target := autocompleteTarget{}
+	date, startTime := splitDateTimeInput(values["date"], values["start_time"])
+	if commandName == "edit" {
+		if r, err := store.GetReservation(values["reservation_id"]); err == nil {
+			if date == "" {
+				date = r.Date
+			}
+			if startTime == "" {
+				startTime = r.StartTime
+			}
+			target.ResourceID = r.GetResourceID()
+			target.ExcludeID = r.ID
+		}
+	} else {
+		target.ResourceID = defaultResourceID()
+	}
+	if room, ok := values["room"]; ok {
+		if resource, found := findResource(room); found {
+			target.ResourceID = resource.ID
+		}
+	}
+
+	if date == "" {
+		return target
+	}
+	day, inputErr := parseDateInput(date)
+	if inputErr != nil {
+		return target
+	}
+	target.Day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, models.Location())
+	target.HasDate = true
+
+	if startTime == "" {
+		return target
+	}
+	clock, inputErr := parseClockInput(startTime, "開始時間")
+	if inputErr != nil {
+		return target
+	}
+	target.Start = target.at(clock)
+	target.HasStart = true
+	return target
+}
+
+// getBusySlots は予約日とその翌日に同じ部屋で埋まっている時間帯を開始時刻順に返す（編集中の予約は除く）
+func getBusySlots(store storage.Repository, target autocompleteTarget) []models.TimeSlot {
+	reservations, err := store.QueryReservations(storage.Query{
+		ResourceID: target.ResourceID,
+		Statuses:   models.ActiveStatuses,
+		DateFrom:   target.Day.Format("2006-01-02"),
+		DateTo:     target.Day.AddDate(0, 0, 1).Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil
+	}
+	others := make([]*models.Reservation, 0, len(reservations))
 	for _, r := range reservations {
-		if r.Status == models.ReservationStatus(status) {
-			reservationDate, err := time.Parse("2006-01-02", r.Date)
-			if err != nil {
-				continue
+		if r.ID != target.ExcludeID {
+			others = append(others, r)
+		}
+	}
+	return models.BusySlots(others)
+}
+
+// nextBusyStart は at 以降で最初に始まる予約の開始日時を返す（見つからない場合は false）
+// at がすでに予約で埋まっている場合は at 自身を返す
+func nextBusyStart(busy []models.TimeSlot, at time.Time) (time.Time, bool) {
+	for _, b := range busy {
+		if b.End.After(at) {
+			if b.Start.Before(at) {
+				return at, true
 			}
+			return b.Start, true
+		}
+	}
+	return time.Time{}, false
+}
 
-			if !reservationDate.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, jst)) {
-				filteredReservations = append(filteredReservations, r)
+// freeLabel は at から次の予約（なければその日の利用可能時間の終わり）までの空き時間の表示を返す
+// at が予約で埋まっている場合は空文字を返す
+func freeLabel(busy []models.TimeSlot, at time.Time) string {
+	next, found := nextBusyStart(busy, at)
+	if found && !next.After(at) {
+		return ""
+	}
+	if window, err := openingHours.Window(at.Format("2006-01-02")); err == nil && at.Before(window.End) && (!found || window.End.Before(next)) {
+		return fmt.Sprintf("空き %s・閉室まで", formatDuration(window.End.Sub(at)))
+	}
+	if !found {
+		return "空き"
+	}
+	return fmt.Sprintf("空き %s", formatDuration(next.Sub(at)))
+}
+
+// getAvailableStartSuggestions は予約日の空き状況に応じた開始時間の候補を生成する
+// 予約で埋まっている時刻と過ぎた時刻は候補から除き、「14:00（空き 2時間）」のように次の予約までの空き時間を表示する
+// 「15時半」のように解釈できる入力は、埋まっていても「⚠️ 予約あり」と表示して先頭の候補にする
+func getAvailableStartSuggestions(store storage.Repository, input string, target autocompleteTarget) []*discordgo.ApplicationCommandOptionChoice {
+	busy := getBusySlots(store, target)
+	now := models.Now()
+
+	suggestions := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, choice := range getStepTimeSuggestions(input, "") {
+		clock := choice.Value.(string)
+		at := target.at(clock)
+		label := freeLabel(busy, at)
+		if label == "" || at.Before(now) {
+			continue
+		}
+		suggestions = append(suggestions, &discordgo.ApplicationCommandOptionChoice{
+			Name:  fmt.Sprintf("%s（%s）", clock, label),
+			Value: clock,
+		})
+	}
+
+	if input == "" {
+		return suggestions
+	}
+	clock, inputErr := parseClockInput(input, "時刻")
+	if inputErr != nil {
+		return suggestions
+	}
+	label := freeLabel(busy, target.at(clock))
+	if label == "" {
+		label = "⚠️ 予約あり"
+	}
+	name := fmt.Sprintf("%s（%s）", clock, label)
+	if clock != input {
+		name = fmt.Sprintf("%s → %s", input, name)
+	}
+	return prependChoice(suggestions, &discordgo.ApplicationCommandOptionChoice{
+		Name:  truncateChoiceName(name),
+		Value: clock,
+	})
+}
+
+// getAvailableEndSuggestions は開始日時から次の予約までに収まる終了時間の候補を生成する
+// 「15:30（1時間30分）」のように利用時間も表示し、日付を跨ぐ時刻には「翌」を付ける
+// 「2時間」のように解釈できる入力は、次の予約と重なっても「⚠️ 次の予約と重複」と表示して先頭の候補にする
+func getAvailableEndSuggestions(store storage.Repository, input string, target autocompleteTarget) []*discordgo.ApplicationCommandOptionChoice {
+	limit, hasLimit := nextBusyStart(getBusySlots(store, target), target.Start)
+	startClock := target.Start.Format("15:04")
+
+	endChoice := func(clock string) (*discordgo.ApplicationCommandOptionChoice, bool) {
+		end := target.at(clock)
+		if !end.After(target.Start) {
+			end = end.AddDate(0, 0, 1)
+		}
+		name := clock
+		if end.Day() != target.Start.Day() {
+			name = "翌" + clock
+		}
+		name = fmt.Sprintf("%s（%s）", name, formatDuration(end.Sub(target.Start)))
+		fits := !hasLimit || !end.After(limit)
+		if !fits {
+			name += " ⚠️ 次の予約と重複"
+		}
+		return &discordgo.ApplicationCommandOptionChoice{Name: name, Value: clock}, fits
+	}
+
+	suggestions := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, step := range getStepTimeSuggestions(input, startClock) {
+		if choice, fits := endChoice(step.Value.(string)); fits {
+			suggestions = append(suggestions, choice)
+		}
+	}
+
+	if input == "" {
+		return suggestions
+	}
+	clock, inputErr := parseEndTimeInput(startClock, input)
+	if inputErr != nil || clock == startClock {
+		return suggestions
+	}
+	choice, _ := endChoice(clock)
+	if clock != input {
+		choice.Name = fmt.Sprintf("%s → %s", input, choice.Name)
+	}
+	choice.Name = truncateChoiceName(choice.Name)
+	return prependChoice(suggestions, choice)
+}
+
+// commonDurations は利用時間の候補
+var commonDurations = []time.Duration{
+	30 * time.Minute,
+	time.Hour,
+	90 * time.Minute,
+	2 * time.Hour,
+	150 * time.Minute,
+	3 * time.Hour,
+	4 * time.Hour,
+}
+
+// getDurationSuggestions は利用時間の候補を生成する
+// 開始日時が分かっている場合は、次の予約までに収まる長さだけを終了時刻付きで候補にする
+// 「1h30m」「2時間」のように解釈できる入力は、解釈した長さを先頭の候補にする
+func getDurationSuggestions(store storage.Repository, input string, target autocompleteTarget) []*discordgo.ApplicationCommandOptionChoice {
+	var limit time.Time
+	hasLimit := false
+	if target.HasStart {
+		limit, hasLimit = nextBusyStart(getBusySlots(store, target), target.Start)
+	}
+	// 開始時刻がすでに予約で埋まっている場合は、どの長さでも重複する
+	if hasLimit && !limit.After(target.Start) {
+		return []*discordgo.ApplicationCommandOptionChoice{}
+	}
+
+	durationChoice := func(d time.Duration) *discordgo.ApplicationCommandOptionChoice {
+		name := formatDuration(d)
+		if target.HasStart {
+			end := target.Start.Add(d)
+			endStr := end.Format("15:04")
+			if end.Day() != target.Start.Day() {
+				endStr = "翌" + endStr
 			}
+			name = fmt.Sprintf("%s（%s-%s）", name, target.Start.Format("15:04"), endStr)
+			if hasLimit && end.After(limit) {
+				name += " ⚠️ 次の予約と重複"
+			}
+		}
+		return &discordgo.ApplicationCommandOptionChoice{Name: name, Value: formatDuration(d)}
+	}
+
+	suggestions := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, d := range commonDurations {
+		if hasLimit && target.Start.Add(d).After(limit) {
+			continue
+		}
+		suggestions = append(suggestions, durationChoice(d))
+	}
+
+	if input == "" {
+		return suggestions
+	}
+	if d, inputErr := parseDurationInput(input); inputErr == nil {
+		choice := durationChoice(d)
+		choice.Name = truncateChoiceName(fmt.Sprintf("%s → %s", input, choice.Name))
+		return prependChoice(suggestions, choice)
+	}
+	filtered := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, choice := range suggestions {
+		if strings.Contains(choice.Name, input) {
+			filtered = append(filtered, choice)
+		}
+	}
+	if len(filtered) > 0 {
+		return filtered
+	}
+	return suggestions
+}
+
+// getReservationSuggestions はユーザーの予約候補を生成する
+// 管理者にはすべてのユーザーの予約を予約者名付きで候補に出す
+// statuses が空の場合は、状態や日付に関係なくすべての予約を新しい順に候補に出す
+func getReservationSuggestions(store storage.Repository, userID string, admin bool, statuses []models.ReservationStatus, input string) []*discordgo.ApplicationCommandOptionChoice {
+	suggestions := []*discordgo.ApplicationCommandOptionChoice{}
+	var reservations []*models.Reservation
+	if admin {
+		reservations = store.GetAllReservations()
+	} else {
+		reservations = store.GetUserReservations(userID)
+	}
+
+	today := models.Today()
+
+	var filteredReservations []*models.Reservation
+	for _, r := range reservations {
+		// 日を跨ぐ予約は終了日まで候補に含める
+		if len(statuses) == 0 || (storage.Query{Statuses: statuses}.Matches(r) && r.GetEndDate() >= today) {
+			filteredReservations = append(filteredReservations, r)
 		}
 	}
 
 	sort.Slice(filteredReservations, func(i, j int) bool {
-		if filteredReservations[i].Date != filteredReservations[j].Date {
-			return filteredReservations[i].Date < filteredReservations[j].Date
+		a, b := filteredReservations[i], filteredReservations[j]
+		if len(statuses) == 0 {
+			a, b = b, a
+		}
+		if a.Date != b.Date {
+			return a.Date < b.Date
 		}
-		return filteredReservations[i].StartTime < filteredReservations[j].StartTime
+		return a.StartTime < b.StartTime
 	})
 
 	for _, r := range filteredReservations {
 		displayDate := strings.ReplaceAll(r.Date, "-", "/")
-		name := fmt.Sprintf("%s %s-%s", displayDate, r.StartTime, r.EndTime)
+		name := fmt.Sprintf("%s %s", displayDate, formatTimeRange(r))
+		if len(statuses) != 1 {
+			name = fmt.Sprintf("%s %s", getStatusEmoji(r.Status), name)
+		}
+		if hasMultipleResources() {
+			name = fmt.Sprintf("%s [%s]", name, resourceName(r.ResourceID))
+		}
 		if r.Comment != "" {
 			comment := r.Comment
 			if len(comment) > 20 {
@@ -322,6 +706,12 @@ func getReservationSuggestions(store *storage.Storage, userID string, status str
 			}
 			name = fmt.Sprintf("%s (%s)", name, comment)
 		}
+		if admin && r.UserID != userID {
+			name = fmt.Sprintf("%s - %s", name, r.Username)
+		}
+		if len([]rune(name)) > 100 {
+			name = string([]rune(name)[:97]) + "..."
+		}
 
 		if input == "" || strings.Contains(r.ID, input) || strings.Contains(name, input) {
 			suggestions = append(suggestions, &discordgo.ApplicationCommandOptionChoice{
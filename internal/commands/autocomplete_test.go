@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestDateSuggestionsPutParsedDateFirst(t *testing.T) {
+	tomorrow := models.Now().AddDate(0, 0, 1)
+	choices := getDateSuggestions("明日 15時")
+	if len(choices) == 0 || choices[0].Value != tomorrow.Format("2006/01/02")+" 15:00" {
+		t.Fatalf("Expected the parsed date and time first, got %+v", choices)
+	}
+	if !strings.HasPrefix(choices[0].Name, "明日 15時 → ") {
+		t.Errorf("Unexpected choice name %q", choices[0].Name)
+	}
+	for _, choice := range choices[1:] {
+		if choice.Value == choices[0].Value {
+			t.Errorf("Duplicated choice %+v", choice)
+		}
+	}
+}
+
+func TestTimeSuggestionsPutParsedTimeFirst(t *testing.T) {
+	tests := []struct {
+		input, startTime, want string
+	}{
+		{"15時半", "", "15:30"},
+		{"3pm", "", "15:00"},
+		{"2時間", "10:00", "12:00"},
+		{"夜9時", "18:00", "21:00"},
+	}
+	for _, tt := range tests {
+		choices := getTimeSuggestions(tt.input, tt.startTime)
+		if len(choices) == 0 || choices[0].Value != tt.want {
+			t.Errorf("getTimeSuggestions(%q, %q) first choice = %+v, want %s", tt.input, tt.startTime, choices, tt.want)
+		}
+	}
+
+	// HH:MM 形式の入力は30分刻みの候補の絞り込みのみ
+	if choices := getTimeSuggestions("14:", ""); len(choices) != 2 || choices[0].Value != "14:00" {
+		t.Errorf("Unexpected choices for a partial time: %+v", choices)
+	}
+}
+
+func TestDurationSuggestionsFitBeforeNextReservation(t *testing.T) {
+	store := newTestStore(t)
+	now := models.Now()
+	day := now.AddDate(0, 0, 10).Format("2006-01-02")
+	for _, r := range []*models.Reservation{
+		{ID: "next", Date: day, EndDate: day, StartTime: "15:30", EndTime: "17:00", Status: models.StatusPending, ResourceID: models.DefaultResourceID},
+		{ID: "other-room", Date: day, EndDate: day, StartTime: "14:00", EndTime: "15:00", Status: models.StatusPending, ResourceID: "other"},
+		{ID: "self", Date: day, EndDate: day, StartTime: "13:00", EndTime: "14:00", Status: models.StatusPending, ResourceID: models.DefaultResourceID},
+	} {
+		if err := store.AddReservation(r); err != nil {
+			t.Fatalf("AddReservation failed: %v", err)
+		}
+	}
+	option := func(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+	}
+	values := func(choices []*discordgo.ApplicationCommandOptionChoice) []string {
+		result := []string{}
+		for _, choice := range choices {
+			result = append(result, choice.Value.(string))
+		}
+		return result
+	}
+
+	// 14:00 開始なら 15:30 の予約までに収まる長さだけ
+	target := getAutocompleteTarget(store, "reserve", []*discordgo.ApplicationCommandInteractionDataOption{
+		option("date", day), option("start_time", "14時"),
+	})
+	choices := getDurationSuggestions(store, "", target)
+	if got := strings.Join(values(choices), ","); got != "30分,1時間,1時間30分" {
+		t.Errorf("Unexpected durations: %s", got)
+	}
+	if choices[2].Name != "1時間30分（14:00-15:30）" {
+		t.Errorf("Unexpected choice name %q", choices[2].Name)
+	}
+
+	// 入力した長さは収まらなくても先頭に警告付きで表示する
+	choices = getDurationSuggestions(store, "2h", target)
+	if choices[0].Value != "2時間" || !strings.Contains(choices[0].Name, "⚠️") {
+		t.Errorf("Expected the parsed duration first with a warning, got %+v", choices[0])
+	}
+
+	// 開始時刻が埋まっている場合は候補なし
+	busy := getAutocompleteTarget(store, "reserve", []*discordgo.ApplicationCommandInteractionDataOption{
+		option("date", day), option("start_time", "13:30"),
+	})
+	if choices := getDurationSuggestions(store, "", busy); len(choices) != 0 {
+		t.Errorf("Expected no durations for a taken start time, got %v", values(choices))
+	}
+
+	// /edit では編集中の予約の開始時刻を使い、その予約自身は除く
+	edit := getAutocompleteTarget(store, "edit", []*discordgo.ApplicationCommandInteractionDataOption{
+		option("reservation_id", "self"),
+	})
+	if got := strings.Join(values(getDurationSuggestions(store, "", edit)), ","); got != "30分,1時間,1時間30分,2時間,2時間30分" {
+		t.Errorf("Unexpected durations for /edit: %s", got)
+	}
+
+	// 開始日時が分からない場合はすべての長さ
+	if choices := getDurationSuggestions(store, "", autocompleteTarget{}); len(choices) != len(commonDurations) || choices[0].Name != "30分" {
+		t.Errorf("Unexpected durations without a start: %v", values(choices))
+	}
+}
+
+func TestAvailableTimeSuggestions(t *testing.T) {
+	store := newTestStore(t)
+	old := openingHours
+	SetOpeningHours(models.OpeningHours{Open: "09:00", Close: "22:00"})
+	t.Cleanup(func() { SetOpeningHours(old) })
+
+	day := models.Now().AddDate(0, 0, 10).Format("2006-01-02")
+	for _, r := range []*models.Reservation{
+		{ID: "self", Date: day, EndDate: day, StartTime: "13:00", EndTime: "14:00", Status: models.StatusPending, ResourceID: models.DefaultResourceID},
+		{ID: "next", Date: day, EndDate: day, StartTime: "15:30", EndTime: "17:00", Status: models.StatusPending, ResourceID: models.DefaultResourceID},
+		{ID: "done", Date: day, EndDate: day, StartTime: "19:00", EndTime: "20:00", Status: models.StatusCancelled, ResourceID: models.DefaultResourceID},
+	} {
+		if err := store.AddReservation(r); err != nil {
+			t.Fatalf("AddReservation failed: %v", err)
+		}
+	}
+	option := func(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+	}
+	names := func(choices []*discordgo.ApplicationCommandOptionChoice) map[string]string {
+		result := map[string]string{}
+		for _, choice := range choices {
+			result[choice.Value.(string)] = choice.Name
+		}
+		return result
+	}
+
+	// 開始時間: 埋まっている時刻を除き、次の予約（または閉室）までの空き時間を表示する
+	target := getAutocompleteTarget(store, "reserve", []*discordgo.ApplicationCommandInteractionDataOption{option("date", day)})
+	starts := names(getAvailableStartSuggestions(store, "", target))
+	for _, taken := range []string{"13:00", "13:30", "15:30", "16:30"} {
+		if name, ok := starts[taken]; ok {
+			t.Errorf("Expected %s to be hidden, got %q", taken, name)
+		}
+	}
+	expected := map[string]string{
+		"12:00": "12:00（空き 1時間）",
+		"14:00": "14:00（空き 1時間30分）",
+		"17:00": "17:00（空き 5時間・閉室まで）",
+		"21:00": "21:00（空き 1時間・閉室まで）",
+	}
+	for value, name := range expected {
+		if starts[value] != name {
+			t.Errorf("Start %s: expected %q, got %q", value, name, starts[value])
+		}
+	}
+
+	// 入力した時刻は埋まっていても先頭に表示する
+	choices := getAvailableStartSuggestions(store, "13時半", target)
+	if choices[0].Value != "13:30" || choices[0].Name != "13時半 → 13:30（⚠️ 予約あり）" {
+		t.Errorf("Unexpected first choice %+v", choices[0])
+	}
+
+	// 終了時間: 次の予約までに収まる時刻だけ
+	target = getAutocompleteTarget(store, "reserve", []*discordgo.ApplicationCommandInteractionDataOption{option("date", day), option("start_time", "14:00")})
+	choices = getAvailableEndSuggestions(store, "", target)
+	if len(choices) != 3 || choices[2].Value != "15:30" || choices[2].Name != "15:30（1時間30分）" {
+		t.Errorf("Unexpected end times: %+v", names(choices))
+	}
+	choices = getAvailableEndSuggestions(store, "3h", target)
+	if choices[0].Value != "17:00" || !strings.Contains(choices[0].Name, "⚠️") {
+		t.Errorf("Expected the parsed end time first with a warning, got %+v", choices[0])
+	}
+
+	// /edit では編集中の予約の日付を使い、その予約自身は空きとみなす
+	target = getAutocompleteTarget(store, "edit", []*discordgo.ApplicationCommandInteractionDataOption{option("reservation_id", "self")})
+	if name := names(getAvailableStartSuggestions(store, "", target))["13:00"]; name != "13:00（空き 2時間30分）" {
+		t.Errorf("Expected the reservation being edited to be ignored, got %q", name)
+	}
+}
@@ -0,0 +1,361 @@
+package commands
+
+import (
+	_ "embed"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// calendarFontData は予定表の画像で使うビットマップフォント（標準ライブラリだけで文字を描くために埋め込む）
+//
+//go:embed calendar_font.txt
+var calendarFontData string
+
+const (
+	glyphWidth  = 5 // 字形の幅（点）
+	glyphHeight = 7 // 字形の高さ（点）
+)
+
+// calendarFont は文字ごとの字形（1行を下位5ビットで表す）
+var calendarFont = parseCalendarFont(calendarFontData)
+
+// parseCalendarFont はフォントファイルを読み込む（「[文字]」の行の次の7行が字形）
+func parseCalendarFont(data string) map[rune][glyphHeight]uint8 {
+	font := map[rune][glyphHeight]uint8{}
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for idx := 0; idx < len(lines); idx++ {
+		header := []rune(lines[idx])
+		if len(header) != 3 || header[0] != '[' || header[2] != ']' || idx+glyphHeight >= len(lines) {
+			continue
+		}
+		var glyph [glyphHeight]uint8
+		for row := 0; row < glyphHeight; row++ {
+			for col, dot := range lines[idx+1+row] {
+				if dot == '#' && col < glyphWidth {
+					glyph[row] |= 1 << (glyphWidth - 1 - col)
+				}
+			}
+		}
+		font[header[1]] = glyph
+		idx += glyphHeight
+	}
+	return font
+}
+
+// 予定表の画像のレイアウト（ピクセル）
+const (
+	calendarScale       = 2   // 文字の拡大率
+	calendarMargin      = 16  // 外側の余白
+	calendarGutter      = 72  // 時刻を表示する左側の幅
+	calendarColumnWidth = 112 // 1日分の列の幅
+	calendarHourHeight  = 48  // 1時間分の高さ
+	calendarHeadHeight  = 44  // 曜日と日付のヘッダーの高さ
+	calendarLegendRow   = 26  // 凡例の1行の高さ
+	calendarSwatchSize  = 16  // 凡例の色見本の大きさ
+)
+
+// 色分けの基準（/calendar の color_by オプションの値）
+const (
+	calendarColorByMember = "member"
+	calendarColorByRoom   = "room"
+)
+
+var (
+	calendarBackground = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	calendarText       = color.RGBA{0x2E, 0x33, 0x38, 0xFF}
+	calendarHourLine   = color.RGBA{0xC4, 0xC9, 0xCE, 0xFF}
+	calendarHalfLine   = color.RGBA{0xEB, 0xED, 0xEF, 0xFF}
+	calendarToday      = color.RGBA{0xE8, 0xEB, 0xFD, 0xFF}
+	calendarNow        = color.RGBA{0xED, 0x42, 0x45, 0xFF}
+	calendarOutside    = color.RGBA{0xF6, 0xF6, 0xF7, 0xFF}
+)
+
+// calendarPalette は予約の色（凡例の順に使い、足りない場合は繰り返す）
+var calendarPalette = []color.RGBA{
+	{0x58, 0x65, 0xF2, 0xFF}, // Blurple
+	{0x57, 0xF2, 0x87, 0xFF}, // Green
+	{0xFE, 0xE7, 0x5C, 0xFF}, // Yellow
+	{0xEB, 0x45, 0x9E, 0xFF}, // Fuchsia
+	{0xFA, 0xA6, 0x1A, 0xFF}, // Orange
+	{0x1A, 0xBC, 0x9C, 0xFF}, // Teal
+	{0x9B, 0x59, 0xB6, 0xFF}, // Purple
+	{0xED, 0x42, 0x45, 0xFF}, // Red
+	{0x34, 0x98, 0xDB, 0xFF}, // Blue
+	{0x99, 0xAA, 0xB5, 0xFF}, // Gray
+}
+
+// calendarEntry は予定表の凡例の1項目（メンバーまたは部屋）
+type calendarEntry struct {
+	Key   string     // 予約のブロックと凡例に表示する記号（A, B, ...）
+	Name  string     // 表示名
+	Color color.RGBA // ブロックの色
+}
+
+// calendarKey は凡例の n 番目（0始まり）の記号を返す（A〜Z、AA〜）
+func calendarKey(n int) string {
+	key := ""
+	for n++; n > 0; n = (n - 1) / 26 {
+		key = string(rune('A'+(n-1)%26)) + key
+	}
+	return key
+}
+
+// calendarLegend は予約を色分けの基準ごとにまとめ、開始日時の早い順に凡例の記号と色を割り当てる
+// 予約ごとの凡例の番号も返す
+func calendarLegend(reservations []*models.Reservation, colorBy string) ([]calendarEntry, []int) {
+	entries := []calendarEntry{}
+	indexes := make([]int, len(reservations))
+	seen := map[string]int{}
+	for idx, r := range reservations {
+		id, name := r.UserID, r.Username
+		if colorBy == calendarColorByRoom {
+			id, name = r.GetResourceID(), resourceName(r.GetResourceID())
+		}
+		if name == "" {
+			name = id
+		}
+		n, found := seen[id]
+		if !found {
+			n = len(entries)
+			seen[id] = n
+			entries = append(entries, calendarEntry{
+				Key:   calendarKey(n),
+				Name:  name,
+				Color: calendarPalette[n%len(calendarPalette)],
+			})
+		}
+		indexes[idx] = n
+	}
+	return entries, indexes
+}
+
+// renderCalendar は1週間分の予約を時間割の画像にする
+// reservations は開始日時の順に並べておくこと（凡例の記号の順になる）
+func renderCalendar(columns []timetableColumn, reservations []*models.Reservation, colorBy string, title string, now time.Time) (*image.RGBA, []calendarEntry) {
+	busy := reservationSlots(reservations)
+	dayStarts, first, last := timetableSpan(columns, reservations, busy)
+	entries, indexes := calendarLegend(reservations, colorBy)
+
+	// 部屋を絞り込んでいない場合は、部屋ごとに列を分けて重ならないようにする
+	lanes, laneCount := map[string]int{}, 1
+	if len(columns) > 0 && columns[0].ResourceID == "" && hasMultipleResources() {
+		for idx, resource := range resources {
+			lanes[resource.ID] = idx
+		}
+		laneCount = len(resources)
+	}
+
+	gridTop := calendarMargin + glyphHeight*calendarScale + 12 + calendarHeadHeight
+	gridLeft := calendarMargin + calendarGutter
+	gridBottom := gridTop + int((last-first)*calendarHourHeight/time.Hour)
+	width := gridLeft + len(columns)*calendarColumnWidth + calendarMargin
+	legendLines := layoutLegend(entries, width-2*calendarMargin)
+	height := gridBottom + 16 + len(legendLines)*calendarLegendRow + calendarMargin
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, img.Bounds(), calendarBackground)
+	drawText(img, calendarMargin, calendarMargin, title, calendarText)
+
+	// ヘッダーと利用可能時間外の背景
+	for col, column := range columns {
+		x := gridLeft + col*calendarColumnWidth
+		if column.Date == now.Format("2006-01-02") {
+			fillRect(img, image.Rect(x, gridTop-calendarHeadHeight, x+calendarColumnWidth, gridBottom), calendarToday)
+		}
+		if window, err := openingHours.Window(column.Date); err == nil {
+			top := gridTop + int((window.Start.Sub(dayStarts[col])-first)*calendarHourHeight/time.Hour)
+			bottom := gridTop + int((window.End.Sub(dayStarts[col])-first)*calendarHourHeight/time.Hour)
+			if top > gridTop {
+				fillRect(img, image.Rect(x, gridTop, x+calendarColumnWidth, top), calendarOutside)
+			}
+			if bottom < gridBottom {
+				fillRect(img, image.Rect(x, bottom, x+calendarColumnWidth, gridBottom), calendarOutside)
+			}
+		}
+		centerText(img, x, calendarColumnWidth, gridTop-calendarHeadHeight+6, strings.ToUpper(dayStarts[col].Weekday().String()[:3]), calendarText)
+		centerText(img, x, calendarColumnWidth, gridTop-calendarHeadHeight+6+glyphHeight*calendarScale+6, dayStarts[col].Format("01/02"), calendarText)
+	}
+
+	// 時刻の罫線
+	for offset := first; offset <= last; offset += timetableStep {
+		y := gridTop + int((offset-first)*calendarHourHeight/time.Hour)
+		line := calendarHalfLine
+		if offset%time.Hour == 0 {
+			line = calendarHourLine
+			if offset < last {
+				drawText(img, calendarMargin, y+2, formatClock(offset), calendarText)
+			}
+		}
+		fillRect(img, image.Rect(gridLeft, y, gridLeft+len(columns)*calendarColumnWidth, y+1), line)
+	}
+	for col := 0; col <= len(columns); col++ {
+		x := gridLeft + col*calendarColumnWidth
+		fillRect(img, image.Rect(x, gridTop-calendarHeadHeight, x+1, gridBottom), calendarHourLine)
+	}
+
+	// 予約のブロック
+	for col, column := range columns {
+		from, to := dayStarts[col].Add(first), dayStarts[col].Add(last)
+		laneWidth := calendarColumnWidth / laneCount
+		for idx, slot := range busy {
+			r := reservations[idx]
+			if !columnMatches(column, r) || !slot.Start.Before(to) || !slot.End.After(from) {
+				continue
+			}
+			start, end := slot.Start, slot.End
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			x := gridLeft + col*calendarColumnWidth + lanes[r.GetResourceID()]*laneWidth
+			block := image.Rect(x+3, gridTop+int(start.Sub(from)*calendarHourHeight/time.Hour)+1, x+laneWidth-2, gridTop+int(end.Sub(from)*calendarHourHeight/time.Hour)-1)
+			entry := entries[indexes[idx]]
+			fillRect(img, block, entry.Color)
+			strokeRect(img, block, darken(entry.Color))
+
+			// 入りきる場合は記号と開始時刻を表示する
+			if block.Dy() < glyphHeight*calendarScale+6 {
+				continue
+			}
+			for _, label := range []string{entry.Key + " " + r.StartTime, entry.Key} {
+				if textWidth(label) <= block.Dx()-8 {
+					drawText(img, block.Min.X+4, block.Min.Y+4, label, calendarText)
+					break
+				}
+			}
+		}
+	}
+
+	// 現在時刻の線
+	for col := range columns {
+		from := dayStarts[col].Add(first)
+		if !now.Before(from) && now.Before(dayStarts[col].Add(last)) {
+			y := gridTop + int(now.Sub(from)*calendarHourHeight/time.Hour)
+			x := gridLeft + col*calendarColumnWidth
+			fillRect(img, image.Rect(x, y-1, x+calendarColumnWidth+1, y+1), calendarNow)
+		}
+	}
+
+	// 凡例
+	y := gridBottom + 16
+	for _, line := range legendLines {
+		x := calendarMargin
+		for _, n := range line {
+			entry := entries[n]
+			swatch := image.Rect(x, y, x+calendarSwatchSize, y+calendarSwatchSize)
+			fillRect(img, swatch, entry.Color)
+			strokeRect(img, swatch, darken(entry.Color))
+			label := legendLabel(entry)
+			drawText(img, x+calendarSwatchSize+6, y+1, label, calendarText)
+			x += legendItemWidth(label)
+		}
+		y += calendarLegendRow
+	}
+	return img, entries
+}
+
+// legendLabel は凡例に表示する文字列を返す（フォントにない文字は省き、長い名前は切り詰める）
+func legendLabel(entry calendarEntry) string {
+	name := []rune{}
+	for _, c := range strings.ToUpper(entry.Name) {
+		if _, ok := calendarFont[c]; ok {
+			name = append(name, c)
+		}
+	}
+	trimmed := strings.Join(strings.Fields(string(name)), " ")
+	if runes := []rune(trimmed); len(runes) > 14 {
+		trimmed = string(runes[:14])
+	}
+	if trimmed == "" {
+		return entry.Key
+	}
+	return entry.Key + " " + trimmed
+}
+
+// legendItemWidth は凡例の1項目の幅を返す
+func legendItemWidth(label string) int {
+	return calendarSwatchSize + 6 + textWidth(label) + 20
+}
+
+// layoutLegend は凡例の項目を幅に収まるように行に分ける
+func layoutLegend(entries []calendarEntry, width int) [][]int {
+	lines := [][]int{}
+	current, used := []int{}, 0
+	for n, entry := range entries {
+		itemWidth := legendItemWidth(legendLabel(entry))
+		if len(current) > 0 && used+itemWidth > width {
+			lines = append(lines, current)
+			current, used = []int{}, 0
+		}
+		current = append(current, n)
+		used += itemWidth
+	}
+	if len(current) > 0 {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// formatClock は0時からの長さを HH:MM 形式にする
+func formatClock(offset time.Duration) string {
+	return time.Time{}.Add(offset).Format("15:04")
+}
+
+// textWidth は文字列を描いたときの幅を返す
+func textWidth(text string) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return n*(glyphWidth+1)*calendarScale - calendarScale
+}
+
+// centerText は x から width の幅の中央に文字列を描く
+func centerText(img *image.RGBA, x, width, y int, text string, c color.RGBA) {
+	drawText(img, x+(width-textWidth(text))/2, y, text, c)
+}
+
+// drawText は埋め込みフォントで文字列を描く（フォントにない文字は ? で表す）
+func drawText(img *image.RGBA, x, y int, text string, c color.RGBA) {
+	for _, char := range strings.ToUpper(text) {
+		glyph, ok := calendarFont[char]
+		if !ok {
+			glyph = calendarFont['?']
+		}
+		for row := 0; row < glyphHeight; row++ {
+			for col := 0; col < glyphWidth; col++ {
+				if glyph[row]&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				dotX, dotY := x+col*calendarScale, y+row*calendarScale
+				fillRect(img, image.Rect(dotX, dotY, dotX+calendarScale, dotY+calendarScale), c)
+			}
+		}
+		x += (glyphWidth + 1) * calendarScale
+	}
+}
+
+// fillRect は矩形を塗りつぶす
+func fillRect(img *image.RGBA, rect image.Rectangle, c color.RGBA) {
+	draw.Draw(img, rect, &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
+
+// strokeRect は矩形の枠線を描く
+func strokeRect(img *image.RGBA, rect image.Rectangle, c color.RGBA) {
+	fillRect(img, image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+1), c)
+	fillRect(img, image.Rect(rect.Min.X, rect.Max.Y-1, rect.Max.X, rect.Max.Y), c)
+	fillRect(img, image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+1, rect.Max.Y), c)
+	fillRect(img, image.Rect(rect.Max.X-1, rect.Min.Y, rect.Max.X, rect.Max.Y), c)
+}
+
+// darken は枠線用に色を暗くする
+func darken(c color.RGBA) color.RGBA {
+	return color.RGBA{c.R / 4 * 3, c.G / 4 * 3, c.B / 4 * 3, c.A}
+}
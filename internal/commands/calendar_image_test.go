@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestCalendarFontCoversLabels(t *testing.T) {
+	for _, c := range "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ :-/?" {
+		if _, ok := calendarFont[c]; !ok {
+			t.Errorf("Glyph %q is missing from the embedded font", c)
+		}
+	}
+	if glyph := calendarFont['1']; glyph[0] != 0b00100 || glyph[6] != 0b01110 {
+		t.Errorf("Unexpected glyph for '1': %05b", glyph)
+	}
+}
+
+func TestCalendarKey(t *testing.T) {
+	tests := map[int]string{0: "A", 1: "B", 25: "Z", 26: "AA", 27: "AB", 52: "BA"}
+	for n, expected := range tests {
+		if got := calendarKey(n); got != expected {
+			t.Errorf("calendarKey(%d) = %q, expected %q", n, got, expected)
+		}
+	}
+}
+
+func TestCalendarLegend(t *testing.T) {
+	reservations := []*models.Reservation{
+		{UserID: "u1", Username: "Alice", ResourceID: "main"},
+		{UserID: "u2", Username: "山田", ResourceID: "main"},
+		{UserID: "u1", Username: "Alice", ResourceID: "sub"},
+	}
+	entries, indexes := calendarLegend(reservations, calendarColorByMember)
+	if len(entries) != 2 || entries[0].Key != "A" || entries[1].Name != "山田" {
+		t.Fatalf("Unexpected member legend: %+v", entries)
+	}
+	if indexes[0] != 0 || indexes[1] != 1 || indexes[2] != 0 {
+		t.Errorf("Unexpected member indexes: %v", indexes)
+	}
+	if entries[0].Color == entries[1].Color {
+		t.Errorf("Members should have different colors")
+	}
+
+	entries, indexes = calendarLegend(reservations, calendarColorByRoom)
+	if len(entries) != 2 || indexes[0] != 0 || indexes[1] != 0 || indexes[2] != 1 {
+		t.Errorf("Unexpected room legend: %+v %v", entries, indexes)
+	}
+
+	if label := legendLabel(calendarEntry{Key: "B", Name: "山田 Taro"}); label != "B TARO" {
+		t.Errorf("Unexpected legend label %q", label)
+	}
+	if label := legendLabel(calendarEntry{Key: "C", Name: "山田"}); label != "C" {
+		t.Errorf("Unexpected legend label %q", label)
+	}
+}
+
+func TestRenderCalendar(t *testing.T) {
+	old := openingHours
+	SetOpeningHours(models.OpeningHours{Open: "09:00", Close: "12:00"})
+	t.Cleanup(func() { SetOpeningHours(old) })
+
+	day, _ := time.Parse("2006-01-02", "2030-05-15")
+	columns := weekColumns(day, "main")
+	reservations := []*models.Reservation{
+		{UserID: "u1", Username: "Alice", Date: "2030-05-13", EndDate: "2030-05-13", StartTime: "10:00", EndTime: "11:00", Status: models.StatusPending, ResourceID: "main"},
+	}
+	img, entries := renderCalendar(columns, reservations, calendarColorByMember, "2030/05/13 - 2030/05/19", time.Date(2030, 5, 1, 0, 0, 0, 0, models.Location()))
+	if len(entries) != 1 {
+		t.Fatalf("Expected one legend entry, got %+v", entries)
+	}
+
+	gridTop := calendarMargin + glyphHeight*calendarScale + 12 + calendarHeadHeight
+	gridLeft := calendarMargin + calendarGutter
+	if expected := gridLeft + 7*calendarColumnWidth + calendarMargin; img.Bounds().Dx() != expected {
+		t.Errorf("Expected width %d, got %d", expected, img.Bounds().Dx())
+	}
+	// 09:00-12:00 の3時間分と凡例1行
+	if expected := gridTop + 3*calendarHourHeight + 16 + calendarLegendRow + calendarMargin; img.Bounds().Dy() != expected {
+		t.Errorf("Expected height %d, got %d", expected, img.Bounds().Dy())
+	}
+
+	// 月曜の 10:30 の位置（ブロックの右下寄り）は予約の色、火曜の同じ時刻は背景色
+	y := gridTop + calendarHourHeight*3/2 + calendarHourHeight/4
+	if got := img.RGBAAt(gridLeft+calendarColumnWidth-10, y); got != entries[0].Color {
+		t.Errorf("Expected the reservation color on Monday, got %v", got)
+	}
+	if got := img.RGBAAt(gridLeft+2*calendarColumnWidth-10, y); got != (color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}) {
+		t.Errorf("Expected the background on Tuesday, got %v", got)
+	}
+}
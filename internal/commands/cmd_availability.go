@@ -0,0 +1,288 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// openingHours は空き時間を探す1日の利用可能時間
+var openingHours = models.DefaultOpeningHours
+
+// SetOpeningHours は空き時間を探す1日の利用可能時間を設定する
+func SetOpeningHours(hours models.OpeningHours) {
+	openingHours = hours
+}
+
+const (
+	maxAvailabilityDays       = 7                // 一度に空き時間を探せる日数
+	defaultAvailabilityLength = 30 * time.Minute // 最短時間の指定がない場合に表示する空き時間の長さ
+	defaultPrefillLength      = time.Hour        // ボタンから予約する場合の既定の予約時間
+	availabilityStep          = 15 * time.Minute // 今日の空き時間の開始時刻を切り上げる単位
+)
+
+// availableDay は1日分の空き時間
+type availableDay struct {
+	Date  string
+	Slots []models.TimeSlot
+}
+
+// handleAvailability は指定した期間の空き時間を表示する
+func handleAvailability(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool) {
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	today := models.Today()
+	from, to := today, ""
+	if opt, ok := optionMap["date"]; ok {
+		parsed, inputErr := parseDateInput(opt.StringValue())
+		if inputErr != nil {
+			respondError(s, i, inputErr.Message)
+			return
+		}
+		from = parsed.Format("2006-01-02")
+	}
+	to = from
+	if opt, ok := optionMap["until"]; ok {
+		parsed, inputErr := parseDateInput(opt.StringValue())
+		if inputErr != nil {
+			respondError(s, i, inputErr.Message)
+			return
+		}
+		to = parsed.Format("2006-01-02")
+	}
+
+	if to < today {
+		respondError(s, i, "過去の日付の空き時間は表示できません。")
+		return
+	}
+	if from < today {
+		from = today
+	}
+	if to < from {
+		respondError(s, i, "終了日は開始日以降の日付を指定してください。")
+		return
+	}
+	dates := dateRange(from, to)
+	if len(dates) > maxAvailabilityDays {
+		respondError(s, i, fmt.Sprintf("空き時間を探せるのは%d日分までです。期間を短くしてください。", maxAvailabilityDays))
+		return
+	}
+
+	minDuration := defaultAvailabilityLength
+	prefillLength := defaultPrefillLength
+	if opt, ok := optionMap["duration"]; ok {
+		minDuration = time.Duration(opt.IntValue()) * time.Minute
+		prefillLength = minDuration
+	}
+
+	room := ""
+	if opt, ok := optionMap["room"]; ok {
+		room = opt.StringValue()
+	}
+	resourceID, inputErr := parseResourceOption(room)
+	if inputErr != nil {
+		respondError(s, i, inputErr.Message)
+		return
+	}
+
+	days, err := findAvailability(store, resourceID, dates, minDuration)
+	if err != nil {
+		respondError(s, i, "空き時間の取得に失敗しました")
+		logger.LogError("ERROR", "handleAvailability", "Failed to query reservations", err, map[string]interface{}{
+			"resource_id": resourceID,
+			"from":        from,
+			"to":          to,
+		})
+		return
+	}
+
+	description := fmt.Sprintf("%s 以上の空き時間（利用可能時間 %s）", formatDuration(minDuration), openingHours)
+	if hasMultipleResources() {
+		description = fmt.Sprintf("🚪 %s\n%s", resourceName(resourceID), description)
+	}
+
+	fields := []*discordgo.MessageEmbedField{}
+	buttons := []discordgo.Button{}
+	for _, day := range days {
+		lines := []string{}
+		for _, slot := range day.Slots {
+			lines = append(lines, fmt.Sprintf("• %s（%s）", formatSlot(slot), formatDuration(slot.Duration())))
+			if button, ok := availabilityButton(resourceID, day.Date, slot, prefillLength, len(days) > 1); ok && len(buttons) < 25 {
+				buttons = append(buttons, button)
+			}
+		}
+		value := "空きはありません"
+		if len(lines) > 0 {
+			value = joinLines(lines)
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("📅 %s", formatDate(day.Date)),
+			Value:  value,
+			Inline: false,
+		})
+	}
+	if len(buttons) > 0 {
+		description += "\nボタンを押すと、その時間で予約を作成できます。"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "🔵 空き時間",
+		Description: description,
+		Fields:      fields,
+		Color:       0x5865F2,
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "部室予約システム  |  availability",
+		},
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: buttonRows(buttons),
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// findAvailability は部屋の各日の空き時間を利用可能時間の範囲で求める
+// 今日の空き時間は現在時刻以降のみを対象とする
+func findAvailability(store storage.Repository, resourceID string, dates []string, minDuration time.Duration) ([]availableDay, error) {
+	if len(dates) == 0 {
+		return nil, nil
+	}
+	// 前日から日を跨ぐ予約も終了日で検索されるため、期間の初日から検索すればよい
+	reservations, err := store.QueryReservations(storage.Query{
+		ResourceID: resourceID,
+		Statuses:   models.ActiveStatuses,
+		DateFrom:   dates[0],
+		DateTo:     dates[len(dates)-1],
+	})
+	if err != nil {
+		return nil, err
+	}
+	busy := models.BusySlots(reservations)
+
+	earliest := models.Now().Add(availabilityStep - 1).Truncate(availabilityStep)
+	days := make([]availableDay, 0, len(dates))
+	for _, date := range dates {
+		window, err := openingHours.Window(date)
+		if err != nil {
+			return nil, err
+		}
+		if window.Start.Before(earliest) {
+			window.Start = earliest
+		}
+		day := availableDay{Date: date}
+		if window.Start.Before(window.End) {
+			day.Slots = models.FreeSlots(window, busy, minDuration)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// dateRange は from から to までの日付（YYYY-MM-DD形式）を返す
+func dateRange(from, to string) []string {
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil
+	}
+	dates := []string{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		dates = append(dates, day.Format("2006-01-02"))
+	}
+	return dates
+}
+
+// formatSlot は空き時間を "10:00 - 12:00" 形式にする（閉館が 24:00 の場合は 24:00 と表示する）
+func formatSlot(slot models.TimeSlot) string {
+	end := slot.End.Format("15:04")
+	if end == "00:00" && slot.End.After(slot.Start) {
+		end = "24:00"
+	}
+	return fmt.Sprintf("%s - %s", slot.Start.Format("15:04"), end)
+}
+
+// availabilityButton は空き時間から予約フォームを開くボタンを作成する
+// 終了時刻には開始時刻から length 後（空き時間の終わりまで）を入れておく
+func availabilityButton(resourceID, date string, slot models.TimeSlot, length time.Duration, withDate bool) (discordgo.Button, bool) {
+	end := slot.Start.Add(length)
+	if end.After(slot.End) {
+		end = slot.End
+	}
+	customID := newCustomID(actionAvailabilityReserve, resourceID, date, slot.Start.Format("15:04"), end.Format("15:04"))
+	if len(customID) > customIDMaxLength {
+		return discordgo.Button{}, false
+	}
+
+	label := formatSlot(slot)
+	if withDate {
+		label = fmt.Sprintf("%s %s", slot.Start.Format("1/2"), label)
+	}
+	return discordgo.Button{
+		Label:    label,
+		Style:    discordgo.PrimaryButton,
+		CustomID: customID,
+	}, true
+}
+
+// handleAvailabilityReserveButton は空き時間のボタンが押されたときに、日時を入力済みの予約フォームを表示する
+// args: 部屋ID, 日付, 開始時間, 終了時間
+func handleAvailabilityReserveButton(s *discordgo.Session, i *discordgo.InteractionCreate, args []string) {
+	if len(args) != 4 {
+		respondError(s, i, "この操作は利用できません。もう一度コマンドを実行してください。")
+		return
+	}
+	resourceID, date, startTime, endTime := args[0], args[1], args[2], args[3]
+
+	title := "予約の作成"
+	if hasMultipleResources() {
+		title = fmt.Sprintf("予約の作成（%s）", resourceName(resourceID))
+		if len([]rune(title)) > 45 {
+			title = "予約の作成"
+		}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: newCustomID(actionAvailabilityReserve, resourceID),
+			Title:    title,
+			Components: []discordgo.MessageComponent{
+				textInputRow("date", "予約日", formatDate(date), "YYYY/MM/DD", discordgo.TextInputShort, true, 10),
+				textInputRow("start_time", "開始時間", startTime, "HH:MM", discordgo.TextInputShort, true, 5),
+				textInputRow("end_time", "終了時間", endTime, "HH:MM（開始より前の時刻は翌日）", discordgo.TextInputShort, true, 5),
+				textInputRow("comment", "コメント（任意）", "", "", discordgo.TextInputParagraph, false, 200),
+			},
+		},
+	})
+}
+
+// handleAvailabilityReserveModal は空き時間の予約フォームの送信を /reserve と同じ手順で予約にする
+// args: 部屋ID
+func handleAvailabilityReserveModal(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool, args []string, values map[string]string) {
+	if len(args) != 1 {
+		respondError(s, i, "この操作は利用できません。もう一度コマンドを実行してください。")
+		return
+	}
+	createReservation(s, i, store, logger, allowedChannelID, isDM, "availability", reserveRequest{
+		Date:      values["date"],
+		StartTime: values["start_time"],
+		EndTime:   values["end_time"],
+		Room:      args[0],
+		Comment:   values["comment"],
+	})
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// newTestStore はテスト用のJSONストレージを作成する
+func newTestStore(t *testing.T) storage.Repository {
+	t.Helper()
+	store := storage.NewStorageWithPath(filepath.Join(t.TempDir(), "reservations.json"))
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return store
+}
+
+func TestFindAvailability(t *testing.T) {
+	store := newTestStore(t)
+	old := openingHours
+	SetOpeningHours(models.OpeningHours{Open: "09:00", Close: "24:00"})
+	t.Cleanup(func() { SetOpeningHours(old) })
+
+	day := models.Now().AddDate(0, 0, 10).Format("2006-01-02")
+	next := models.Now().AddDate(0, 0, 11).Format("2006-01-02")
+	for _, r := range []*models.Reservation{
+		{ID: "a", Date: day, EndDate: day, StartTime: "09:00", EndTime: "12:00", Status: models.StatusPending, ResourceID: models.DefaultResourceID},
+		{ID: "b", Date: day, EndDate: next, StartTime: "20:00", EndTime: "10:00", Status: models.StatusPending, ResourceID: models.DefaultResourceID},
+		{ID: "c", Date: day, EndDate: day, StartTime: "13:00", EndTime: "19:00", Status: models.StatusPending, ResourceID: "other"},
+	} {
+		if err := store.AddReservation(r); err != nil {
+			t.Fatalf("AddReservation failed: %v", err)
+		}
+	}
+
+	days, err := findAvailability(store, models.DefaultResourceID, []string{day, next}, time.Hour)
+	if err != nil {
+		t.Fatalf("findAvailability failed: %v", err)
+	}
+	want := map[string][]string{
+		day:  {"12:00 - 20:00"},
+		next: {"10:00 - 24:00"},
+	}
+	for _, d := range days {
+		if len(d.Slots) != len(want[d.Date]) {
+			t.Fatalf("%s: expected %v, got %d slot(s)", d.Date, want[d.Date], len(d.Slots))
+		}
+		for idx, slot := range d.Slots {
+			if got := formatSlot(slot); got != want[d.Date][idx] {
+				t.Errorf("%s: expected %s, got %s", d.Date, want[d.Date][idx], got)
+			}
+		}
+	}
+}
+
+func TestFindAvailabilitySkipsPastTimeToday(t *testing.T) {
+	store := newTestStore(t)
+	old := openingHours
+	SetOpeningHours(models.OpeningHours{Open: "00:00", Close: "24:00"})
+	t.Cleanup(func() { SetOpeningHours(old) })
+
+	days, err := findAvailability(store, models.DefaultResourceID, []string{models.Today()}, 0)
+	if err != nil {
+		t.Fatalf("findAvailability failed: %v", err)
+	}
+	for _, slot := range days[0].Slots {
+		if slot.Start.Before(models.Now()) {
+			t.Errorf("Expected slots to start after now, got %s", formatSlot(slot))
+		}
+		if slot.Start.Minute()%15 != 0 {
+			t.Errorf("Expected slot start to be rounded to 15 minutes, got %s", formatSlot(slot))
+		}
+	}
+}
+
+func TestAvailabilityButtonCustomID(t *testing.T) {
+	window, _ := models.OpeningHours{Open: "10:00", Close: "11:30"}.Window("2030-01-10")
+	button, ok := availabilityButton("main", "2030-01-10", window, 2*time.Hour, false)
+	if !ok {
+		t.Fatal("Expected a button")
+	}
+	action, args := parseCustomID(button.CustomID)
+	if action != actionAvailabilityReserve || len(args) != 4 || args[2] != "10:00" || args[3] != "11:30" {
+		t.Errorf("Unexpected custom ID %q", button.CustomID)
+	}
+}
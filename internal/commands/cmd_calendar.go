@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// calendarFileName は予定表の画像の添付ファイル名
+const calendarFileName = "calendar.png"
+
+// handleCalendar は指定した日を含む週（月曜〜日曜）の予約を画像にして表示する
+func handleCalendar(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool) {
+	options := i.ApplicationCommandData().Options
+	day, resourceID, inputErr := parseWeekOptions(options)
+	if inputErr != nil {
+		respondError(s, i, inputErr.Message)
+		return
+	}
+	colorBy := calendarColorByMember
+	for _, opt := range options {
+		if opt.Name == "color_by" {
+			colorBy = opt.StringValue()
+		}
+	}
+
+	columns := weekColumns(day, resourceID)
+	from, to := columns[0].Date, columns[len(columns)-1].Date
+	reservations, err := store.QueryReservations(storage.Query{
+		ResourceID: resourceID,
+		Statuses:   models.ActiveStatuses,
+		DateFrom:   from,
+		DateTo:     to,
+	})
+	if err != nil {
+		respondError(s, i, "予約の取得に失敗しました")
+		logger.LogError("ERROR", "handleCalendar", "Failed to query reservations", err, map[string]interface{}{
+			"from": from,
+			"to":   to,
+		})
+		return
+	}
+	sortByStart(reservations)
+
+	title := fmt.Sprintf("%s - %s", formatDate(from), formatDate(to))
+	img, entries := renderCalendar(columns, reservations, colorBy, title, models.Now())
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		respondError(s, i, "予定表の画像の作成に失敗しました")
+		logger.LogError("ERROR", "handleCalendar", "Failed to encode calendar image", err, nil)
+		return
+	}
+
+	description := title
+	if resourceID != "" {
+		description += "　🚪 " + resourceName(resourceID)
+	} else if hasMultipleResources() {
+		description += "　🚪 すべての部屋"
+	}
+	if len(reservations) == 0 {
+		description += "\nこの週の予約はありません"
+	}
+
+	// 画像のフォントは英数字のみのため、凡例の名前はここに表示する
+	fields := []*discordgo.MessageEmbedField{}
+	if len(entries) > 0 {
+		lines := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			lines = append(lines, fmt.Sprintf("`%s` %s", entry.Key, entry.Name))
+		}
+		name := "🎨 凡例（メンバー）"
+		if colorBy == calendarColorByRoom {
+			name = "🎨 凡例（部屋）"
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   name,
+			Value:  joinLines(lines),
+			Inline: false,
+		})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "🗓️ 週間予定表",
+		Description: description,
+		Fields:      fields,
+		Image: &discordgo.MessageEmbedImage{
+			URL: "attachment://" + calendarFileName,
+		},
+		Color:     0x5865F2,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "部室予約システム  |  calendar",
+		},
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Files: []*discordgo.File{{
+				Name:        calendarFileName,
+				ContentType: "image/png",
+				Reader:      &buf,
+			}},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -11,7 +11,7 @@ import (
 )
 
 // handleCancel は予約キャンセルコマンドを処理する
-func handleCancel(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, logger *logging.Logger, allowedChannelID string, isDM bool) {
+func handleCancel(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool) {
 	options := i.ApplicationCommandData().Options
 	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
 	for _, opt := range options {
@@ -19,24 +19,63 @@ func handleCancel(s *discordgo.Session, i *discordgo.InteractionCreate, store *s
 	}
 
 	reservationID := optionMap["reservation_id"].StringValue()
+	a := getActor(i, isDM)
 
 	comment := ""
 	if opt, ok := optionMap["comment"]; ok {
 		comment = opt.StringValue()
 	}
 
-	// 予約を取得
-	reservation, err := store.GetReservation(reservationID)
-	if err != nil {
+	// 繰り返し予約の場合は scope に応じてまとめて取り消す
+	scope := getScopeOption(optionMap)
+	if scope != scopeThis {
+		reservation, err := store.GetReservation(reservationID)
+		if err != nil {
+			respondError(s, i, "予約が見つかりませんでした。予約IDを確認してください。")
+			return
+		}
+		if !a.canModify(reservation) {
+			respondError(s, i, "他のユーザーの予約は取り消せません。")
+			return
+		}
+		if reservation.IsRecurring() {
+			cancelSeries(s, i, store, logger, allowedChannelID, a, reservation, scope, comment)
+			return
+		}
+	}
+
+	// 予約をキャンセル済みに更新（権限の確認と変更をストレージのロック内で行う）
+	now := models.Now()
+	late := false
+	reservation, err := store.Mutate(reservationID, func(r *models.Reservation) error {
+		if err := a.authorize(r); err != nil {
+			return err
+		}
+		if !r.Status.IsActive() {
+			return errNotActive
+		}
+		late = isLateCancel(a, r, now)
+		before := r.Clone()
+		r.Status = models.StatusCancelled
+		r.UpdatedAt = time.Now()
+		entry := newHistoryEntry(a, "cancel", models.HistoryCancelled)
+		entry.Note = comment
+		r.RecordHistory(before, entry)
+		return nil
+	})
+	if err == storage.ErrNotFound {
 		respondError(s, i, "予約が見つかりませんでした。予約IDを確認してください。")
 		return
 	}
-
-	// 予約をキャンセル済みに更新
-	reservation.Status = models.StatusCancelled
-	reservation.UpdatedAt = time.Now()
-
-	if err := store.UpdateReservation(reservation); err != nil {
+	if err == errForbidden {
+		respondError(s, i, "他のユーザーの予約は取り消せません。")
+		return
+	}
+	if err == errNotActive {
+		respondError(s, i, notActiveMessage)
+		return
+	}
+	if err != nil {
 		respondError(s, i, "予約の更新に失敗しました")
 		logger.LogError("ERROR", "handlers.handleCancel", "Failed to update reservation", err, map[string]interface{}{
 			"reservation_id": reservationID,
@@ -52,10 +91,30 @@ func handleCancel(s *discordgo.Session, i *discordgo.InteractionCreate, store *s
 		return
 	}
 
-	// 応答
-	respondEmbed(s, i, "🔴 予約を取り消しました", fmt.Sprintf("予約ID: `%s`", reservationID), 0xED4245, true)
+	a.logOverride(logger, "cancel", reservation, map[string]interface{}{"comment": comment})
 
-	// チャンネルの全員に通知
+	// 応答（開始直前の取り消しは違反として記録し、本人に伝える）
+	description := fmt.Sprintf("予約ID: `%s`", reservationID)
+	if late && recordStrike(logger, reservation, models.StrikeLateCancel, now) {
+		description += "\n\n" + lateCancelNotice(reservation.UserID, now)
+	}
+	respondEmbed(s, i, "🔴 予約を取り消しました", description, 0xED4245, true)
+
+	// 予約追加の通知を更新し、チャンネルの全員に通知
+	refreshReservationNotice(s, reservation, "")
+	sendCancelNotice(s, allowedChannelID, a, reservation, comment)
+
+	// 空いた時間帯をキャンセル待ちのユーザーに案内する
+	notifyWaitlist(s, store, logger)
+
+	// Botステータスを更新
+	if UpdateStatusCallback != nil {
+		UpdateStatusCallback()
+	}
+}
+
+// sendCancelNotice は予約の取り消しを通知チャンネルに送信する
+func sendCancelNotice(s *discordgo.Session, allowedChannelID string, a actor, reservation *models.Reservation, comment string) {
 	cancelEmbed := &discordgo.MessageEmbed{
 		Title: "🔴 予約が取り消されました",
 		Fields: []*discordgo.MessageEmbedField{
@@ -66,12 +125,12 @@ func handleCancel(s *discordgo.Session, i *discordgo.InteractionCreate, store *s
 			},
 			{
 				Name:   "📅 日付",
-				Value:  formatDate(reservation.Date),
+				Value:  formatDateRange(reservation),
 				Inline: true,
 			},
 			{
 				Name:   "🕐 時間",
-				Value:  fmt.Sprintf("%s - %s", reservation.StartTime, reservation.EndTime),
+				Value:  formatTimeRange(reservation),
 				Inline: true,
 			},
 		},
@@ -81,6 +140,8 @@ func handleCancel(s *discordgo.Session, i *discordgo.InteractionCreate, store *s
 			Text: "部室予約システム  |  cancel",
 		},
 	}
+	cancelEmbed.Fields = appendResourceField(cancelEmbed.Fields, reservation.ResourceID)
+	cancelEmbed.Fields = a.appendOverrideField(cancelEmbed.Fields, reservation)
 	if comment != "" {
 		cancelEmbed.Fields = append(cancelEmbed.Fields, &discordgo.MessageEmbedField{
 			Name:   "💬 コメント",
@@ -88,8 +149,142 @@ func handleCancel(s *discordgo.Session, i *discordgo.InteractionCreate, store *s
 			Inline: false,
 		})
 	}
-	// DMから実行された場合も、指定チャンネルに通知
-	s.ChannelMessageSendEmbed(allowedChannelID, cancelEmbed)
+	// DMから実行された場合も、指定チャンネル（部屋ごとの通知先があればそちら）に通知
+	s.ChannelMessageSendEmbed(notificationChannel(reservation.ResourceID, allowedChannelID), cancelEmbed)
+}
+
+// cancelSeries は繰り返し予約のうち scope に含まれる予約中の予約をまとめて取り消す
+func cancelSeries(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, a actor, reservation *models.Reservation, scope, comment string) {
+	targets, err := seriesTargets(store, reservation, scope)
+	if err != nil {
+		respondError(s, i, "予約の取得に失敗しました")
+		logger.LogError("ERROR", "handlers.cancelSeries", "Failed to query series", err, map[string]interface{}{
+			"series_id": reservation.SeriesID,
+		})
+		return
+	}
+	if len(targets) == 0 {
+		respondError(s, i, "取り消せる予約がありません。")
+		return
+	}
+
+	now := models.Now()
+	var cancelledLines []string
+	var lateCancelled []*models.Reservation
+	failed := false
+	for _, target := range targets {
+		late := false
+		// 一覧の取得後に取り消し・完了・解放された回は対象外にする（状態の確認もロック内で行う）
+		cancelled, err := store.Mutate(target.ID, func(r *models.Reservation) error {
+			if err := a.authorize(r); err != nil {
+				return err
+			}
+			if !r.Status.IsActive() {
+				return errNotActive
+			}
+			late = isLateCancel(a, r, now)
+			before := r.Clone()
+			r.Status = models.StatusCancelled
+			r.UpdatedAt = time.Now()
+			entry := newHistoryEntry(a, "cancel", models.HistoryCancelled)
+			entry.Note = comment
+			r.RecordHistory(before, entry)
+			return nil
+		})
+		if err == errNotActive {
+			continue
+		}
+		if err != nil {
+			logger.LogError("ERROR", "handlers.cancelSeries", "Failed to update reservation", err, map[string]interface{}{
+				"reservation_id": target.ID,
+				"series_id":      reservation.SeriesID,
+			})
+			failed = true
+			continue
+		}
+		cancelledLines = append(cancelledLines, formatOccurrence(cancelled))
+		if late {
+			lateCancelled = append(lateCancelled, cancelled)
+		}
+		refreshReservationNotice(s, cancelled, "")
+	}
+
+	if len(cancelledLines) == 0 && !failed {
+		respondError(s, i, "取り消せる予約がありません。")
+		return
+	}
+	if len(cancelledLines) == 0 {
+		respondError(s, i, "予約の更新に失敗しました")
+		return
+	}
+
+	if err := store.Save(); err != nil {
+		respondError(s, i, "予約の保存に失敗しました")
+		logger.LogError("ERROR", "handlers.cancelSeries", "Failed to save reservations", err, map[string]interface{}{
+			"series_id": reservation.SeriesID,
+		})
+		return
+	}
+
+	a.logOverride(logger, "cancel", reservation, map[string]interface{}{
+		"scope":     scope,
+		"series_id": reservation.SeriesID,
+		"count":     len(cancelledLines),
+		"comment":   comment,
+	})
+
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "🔁 範囲",
+			Value:  scopeLabel(scope),
+			Inline: true,
+		},
+	}
+	fields = appendResourceField(fields, reservation.ResourceID)
+	fields = a.appendOverrideField(fields, reservation)
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:   fmt.Sprintf("📅 取り消した予約（%d件）", len(cancelledLines)),
+		Value:  joinLines(cancelledLines),
+		Inline: false,
+	})
+
+	// 応答（開始直前の回は違反として記録し、本人に伝える）
+	description := ""
+	for _, r := range lateCancelled {
+		if recordStrike(logger, r, models.StrikeLateCancel, now) {
+			description = lateCancelNotice(r.UserID, now)
+		}
+	}
+	respondEmbedWithFields(s, i, "🔴 繰り返し予約を取り消しました", description, fields, 0xED4245, true)
+
+	// チャンネルの全員に通知
+	publicFields := append([]*discordgo.MessageEmbedField{
+		{
+			Name:   "👤 予約者",
+			Value:  fmt.Sprintf("<@%s>", reservation.UserID),
+			Inline: false,
+		},
+	}, fields...)
+	if comment != "" {
+		publicFields = append(publicFields, &discordgo.MessageEmbedField{
+			Name:   "💬 コメント",
+			Value:  comment,
+			Inline: false,
+		})
+	}
+	cancelEmbed := &discordgo.MessageEmbed{
+		Title:     "🔴 繰り返し予約が取り消されました",
+		Fields:    publicFields,
+		Color:     0xED4245, // Discord Red
+		Timestamp: time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "部室予約システム  |  cancel",
+		},
+	}
+	s.ChannelMessageSendEmbed(notificationChannel(reservation.ResourceID, allowedChannelID), cancelEmbed)
+
+	// 空いた時間帯をキャンセル待ちのユーザーに案内する
+	notifyWaitlist(s, store, logger)
 
 	// Botステータスを更新
 	if UpdateStatusCallback != nil {
@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// errCheckInClosed はチェックインできる時間帯・状態でないことを表す
+var errCheckInClosed = errors.New("check-in is not available for this reservation")
+
+// handleCheckIn はチェックインコマンドを処理する
+// 予約IDを省略した場合は、今チェックインできる自分の予約（開始が最も早いもの）を対象にする
+func handleCheckIn(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool) {
+	a := getActor(i, isDM)
+	now := models.Now()
+
+	reservationID := ""
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "reservation_id" {
+			reservationID = opt.StringValue()
+		}
+	}
+
+	if reservationID == "" {
+		reservations, err := store.QueryReservations(storage.Query{
+			UserID:   a.UserID,
+			Statuses: []models.ReservationStatus{models.StatusPending},
+			DateFrom: now.Format("2006-01-02"),
+			DateTo:   now.Add(models.CheckInOpensBefore).Format("2006-01-02"),
+		})
+		if err != nil {
+			respondError(s, i, "予約の取得に失敗しました")
+			logger.LogError("ERROR", "handleCheckIn", "Failed to query reservations", err, map[string]interface{}{
+				"user_id": a.UserID,
+			})
+			return
+		}
+		target := checkInCandidate(reservations, now)
+		if target == nil {
+			respondError(s, i, fmt.Sprintf("今チェックインできる予約がありません。\nチェックインは予約開始の %s 前から終了までできます。", formatDuration(models.CheckInOpensBefore)))
+			return
+		}
+		reservationID = target.ID
+	}
+
+	reservation, err := checkInReservation(store, a, reservationID, newHistoryEntry(a, "checkin", models.HistoryCheckedIn), now)
+	if err == storage.ErrNotFound {
+		respondError(s, i, "予約が見つかりませんでした。予約IDを確認してください。")
+		return
+	}
+	if err == errForbidden {
+		respondError(s, i, "他のユーザーの予約にはチェックインできません。")
+		return
+	}
+	if err == errCheckInClosed {
+		respondError(s, i, checkInClosedMessage(store, reservationID, now))
+		return
+	}
+	if err != nil {
+		respondError(s, i, "予約の更新に失敗しました")
+		logger.LogError("ERROR", "handleCheckIn", "Failed to update reservation", err, map[string]interface{}{
+			"reservation_id": reservationID,
+		})
+		return
+	}
+
+	if err := store.Save(); err != nil {
+		respondError(s, i, "予約の保存に失敗しました")
+		logger.LogError("ERROR", "handleCheckIn", "Failed to save reservations", err, map[string]interface{}{
+			"reservation_id": reservationID,
+		})
+		return
+	}
+
+	a.logOverride(logger, "checkin", reservation, nil)
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{reservationEmbed(reservation, "🟣 チェックインしました", 0x9B59B6, true)},
+			Components: reservationButtons(reservation),
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	// 予約追加の通知を「利用中」に更新
+	refreshReservationNotice(s, reservation, "")
+}
+
+// handleReservationCheckInButton は「チェックイン」ボタンが押されたときに予約を利用中にする
+func handleReservationCheckInButton(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool, args []string) {
+	a := getActor(i, isDM)
+	forbidden := "他のユーザーの予約にはチェックインできません。"
+	target := reservationButtonTarget(s, i, store, a, args, forbidden)
+	if target == nil {
+		return
+	}
+
+	now := models.Now()
+	reservation, err := checkInReservation(store, a, target.ID, newButtonHistoryEntry(a, actionReservationCheckIn, models.HistoryCheckedIn), now)
+	if err == errForbidden {
+		respondError(s, i, forbidden)
+		return
+	}
+	if err == errCheckInClosed {
+		respondError(s, i, checkInClosedMessage(store, target.ID, now))
+		return
+	}
+	if err != nil {
+		respondError(s, i, "予約の更新に失敗しました")
+		logger.LogError("ERROR", "handleReservationCheckInButton", "Failed to update reservation", err, map[string]interface{}{
+			"reservation_id": target.ID,
+		})
+		return
+	}
+
+	if err := store.Save(); err != nil {
+		respondError(s, i, "予約の保存に失敗しました")
+		logger.LogError("ERROR", "handleReservationCheckInButton", "Failed to save reservations", err, map[string]interface{}{
+			"reservation_id": reservation.ID,
+		})
+		return
+	}
+
+	a.logOverride(logger, "checkin", reservation, map[string]interface{}{"source": models.ButtonSource(actionReservationCheckIn)})
+
+	updatePressedMessage(s, i, reservation)
+	refreshReservationNotice(s, reservation, i.Message.ID)
+}
+
+// checkInReservation は予約を利用中にする（権限と時間帯の確認と変更をストレージのロック内で行う）
+func checkInReservation(store storage.Repository, a actor, reservationID string, entry models.HistoryEntry, now time.Time) (*models.Reservation, error) {
+	return store.Mutate(reservationID, func(r *models.Reservation) error {
+		if err := a.authorize(r); err != nil {
+			return err
+		}
+		if !r.CanCheckIn(now) {
+			return errCheckInClosed
+		}
+		before := r.Clone()
+		r.Status = models.StatusCheckedIn
+		r.UpdatedAt = time.Now()
+		r.RecordHistory(before, entry)
+		return nil
+	})
+}
+
+// checkInCandidate は now の時点でチェックインできる予約のうち、開始が最も早いものを返す（ない場合は nil）
+func checkInCandidate(reservations []*models.Reservation, now time.Time) *models.Reservation {
+	candidates := []*models.Reservation{}
+	for _, r := range reservations {
+		if r.CanCheckIn(now) {
+			candidates = append(candidates, r)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].Date+candidates[a].StartTime < candidates[b].Date+candidates[b].StartTime
+	})
+	return candidates[0]
+}
+
+// checkInClosedMessage はチェックインできなかった理由を予約の状態と時間帯から返す
+func checkInClosedMessage(store storage.Repository, reservationID string, now time.Time) string {
+	r, err := store.GetReservation(reservationID)
+	if err != nil {
+		return "予約が見つかりませんでした。"
+	}
+	switch r.Status {
+	case models.StatusCheckedIn:
+		return "この予約はすでにチェックインしています。"
+	case models.StatusPending:
+	default:
+		return "予約中の予約のみチェックインできます。"
+	}
+	start, err := r.GetStartDateTime()
+	if err == nil && now.Before(start.Add(-models.CheckInOpensBefore)) {
+		return fmt.Sprintf("チェックインは予約開始の %s 前（%s）からできます。",
+			formatDuration(models.CheckInOpensBefore), start.Add(-models.CheckInOpensBefore).Format("01/02 15:04"))
+	}
+	return "終了した予約にはチェックインできません。"
+}
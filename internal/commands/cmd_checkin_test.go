@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestCheckInReservation(t *testing.T) {
+	store := newTestStore(t)
+	r := &models.Reservation{ID: "a", UserID: "owner", Date: "2030-05-15", EndDate: "2030-05-15", StartTime: "14:00", EndTime: "16:00", Status: models.StatusPending, ResourceID: models.DefaultResourceID}
+	if err := store.AddReservation(r); err != nil {
+		t.Fatalf("AddReservation failed: %v", err)
+	}
+	owner := actor{UserID: "owner"}
+	entry := newHistoryEntry(owner, "checkin", models.HistoryCheckedIn)
+
+	early := time.Date(2030, 5, 15, 13, 30, 0, 0, models.Location())
+	if _, err := checkInReservation(store, owner, "a", entry, early); err != errCheckInClosed {
+		t.Errorf("Expected check-in to be closed 30 minutes before start, got %v", err)
+	}
+	if got := checkInClosedMessage(store, "a", early); got != "チェックインは予約開始の 15分 前（05/15 13:45）からできます。" {
+		t.Errorf("Unexpected message %q", got)
+	}
+
+	now := time.Date(2030, 5, 15, 13, 50, 0, 0, models.Location())
+	if _, err := checkInReservation(store, actor{UserID: "other"}, "a", entry, now); err != errForbidden {
+		t.Errorf("Expected other users to be forbidden, got %v", err)
+	}
+	checkedIn, err := checkInReservation(store, owner, "a", entry, now)
+	if err != nil {
+		t.Fatalf("checkInReservation failed: %v", err)
+	}
+	if checkedIn.Status != models.StatusCheckedIn || len(checkedIn.History) != 1 || checkedIn.History[0].Action != models.HistoryCheckedIn {
+		t.Errorf("Expected a checked-in reservation with history, got %+v", checkedIn)
+	}
+	if _, err := checkInReservation(store, owner, "a", entry, now); err != errCheckInClosed {
+		t.Errorf("Expected a second check-in to fail, got %v", err)
+	}
+	if got := checkInClosedMessage(store, "a", now); got != "この予約はすでにチェックインしています。" {
+		t.Errorf("Unexpected message %q", got)
+	}
+}
+
+func TestCheckInCandidate(t *testing.T) {
+	reservations := []*models.Reservation{
+		{ID: "later", Date: "2030-05-15", StartTime: "15:00", EndTime: "16:00", Status: models.StatusPending},
+		{ID: "now", Date: "2030-05-15", StartTime: "14:00", EndTime: "15:00", Status: models.StatusPending},
+		{ID: "tomorrow", Date: "2030-05-16", StartTime: "14:00", EndTime: "15:00", Status: models.StatusPending},
+	}
+
+	now := time.Date(2030, 5, 15, 14, 50, 0, 0, models.Location())
+	if got := checkInCandidate(reservations, now); got == nil || got.ID != "now" {
+		t.Errorf("Expected the reservation in progress, got %+v", got)
+	}
+	if got := checkInCandidate(reservations, time.Date(2030, 5, 15, 12, 0, 0, 0, models.Location())); got != nil {
+		t.Errorf("Expected no candidate, got %+v", got)
+	}
+}
+
+func TestNoShowNotice(t *testing.T) {
+	reservations := []*models.Reservation{
+		{ID: "late", UserID: "member", Date: "2030-05-15", EndDate: "2030-05-15", StartTime: "14:00", EndTime: "16:00", Status: models.StatusPending, ResourceID: models.DefaultResourceID},
+		{ID: "here", UserID: "member", Date: "2030-05-15", EndDate: "2030-05-15", StartTime: "14:00", EndTime: "16:00", Status: models.StatusCheckedIn, ResourceID: models.DefaultResourceID},
+	}
+	now := time.Date(2030, 5, 15, 14, 20, 30, 0, models.Location())
+
+	due := dueNoShows(reservations, now, 15*time.Minute)
+	if len(due) != 1 || due[0].ID != "late" {
+		t.Fatalf("Expected only the reservation without check-in, got %+v", due)
+	}
+
+	embed, components := noShowNotice(due[0], now)
+	if embed.Fields[1].Value != "14:20 - 16:00" {
+		t.Errorf("Expected the freed slot to run from now until the end, got %q", embed.Fields[1].Value)
+	}
+	if len(components) != 1 {
+		t.Fatalf("Expected a reserve button, got %d row(s)", len(components))
+	}
+	button := components[0].(discordgo.ActionsRow).Components[0].(discordgo.Button)
+	if want := newCustomID(actionAvailabilityReserve, models.DefaultResourceID, "2030-05-15", "14:20", "16:00"); button.CustomID != want {
+		t.Errorf("Expected custom ID %q, got %q", want, button.CustomID)
+	}
+}
@@ -11,7 +11,7 @@ import (
 )
 
 // handleComplete は予約完了コマンドを処理する
-func handleComplete(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, logger *logging.Logger, allowedChannelID string, isDM bool) {
+func handleComplete(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool) {
 	options := i.ApplicationCommandData().Options
 	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
 	for _, opt := range options {
@@ -19,24 +19,42 @@ func handleComplete(s *discordgo.Session, i *discordgo.InteractionCreate, store
 	}
 
 	reservationID := optionMap["reservation_id"].StringValue()
+	a := getActor(i, isDM)
 
 	comment := ""
 	if opt, ok := optionMap["comment"]; ok {
 		comment = opt.StringValue()
 	}
 
-	// 予約を取得
-	reservation, err := store.GetReservation(reservationID)
-	if err != nil {
+	// 予約を完了に更新（権限の確認と変更をストレージのロック内で行う）
+	reservation, err := store.Mutate(reservationID, func(r *models.Reservation) error {
+		if err := a.authorize(r); err != nil {
+			return err
+		}
+		if !r.Status.IsActive() {
+			return errNotActive
+		}
+		before := r.Clone()
+		r.Status = models.StatusCompleted
+		r.UpdatedAt = time.Now()
+		entry := newHistoryEntry(a, "complete", models.HistoryCompleted)
+		entry.Note = comment
+		r.RecordHistory(before, entry)
+		return nil
+	})
+	if err == storage.ErrNotFound {
 		respondError(s, i, "予約が見つかりませんでした。予約IDを確認してください。")
 		return
 	}
-
-	// 予約を完了に更新
-	reservation.Status = models.StatusCompleted
-	reservation.UpdatedAt = time.Now()
-
-	if err := store.UpdateReservation(reservation); err != nil {
+	if err == errForbidden {
+		respondError(s, i, "他のユーザーの予約は完了にできません。")
+		return
+	}
+	if err == errNotActive {
+		respondError(s, i, notActiveMessage)
+		return
+	}
+	if err != nil {
 		respondError(s, i, "予約の更新に失敗しました")
 		logger.LogError("ERROR", "handlers.handleComplete", "Failed to update reservation", err, map[string]interface{}{
 			"reservation_id": reservationID,
@@ -52,10 +70,26 @@ func handleComplete(s *discordgo.Session, i *discordgo.InteractionCreate, store
 		return
 	}
 
+	a.logOverride(logger, "complete", reservation, map[string]interface{}{"comment": comment})
+
 	// 応答
 	respondEmbed(s, i, "🔵 予約を完了にしました", fmt.Sprintf("予約ID: `%s`", reservationID), 0x5865F2, true)
 
-	// チャンネルの全員に通知
+	// 予約追加の通知を更新し、チャンネルの全員に通知
+	refreshReservationNotice(s, reservation, "")
+	sendCompleteNotice(s, allowedChannelID, a, reservation, comment)
+
+	// 空いた時間帯をキャンセル待ちのユーザーに案内する
+	notifyWaitlist(s, store, logger)
+
+	// Botステータスを更新
+	if UpdateStatusCallback != nil {
+		UpdateStatusCallback()
+	}
+}
+
+// sendCompleteNotice は予約の完了を通知チャンネルに送信する
+func sendCompleteNotice(s *discordgo.Session, allowedChannelID string, a actor, reservation *models.Reservation, comment string) {
 	completeEmbed := &discordgo.MessageEmbed{
 		Title: "🔵 予約が終わりました",
 		Fields: []*discordgo.MessageEmbedField{
@@ -66,12 +100,12 @@ func handleComplete(s *discordgo.Session, i *discordgo.InteractionCreate, store
 			},
 			{
 				Name:   "📅 日付",
-				Value:  formatDate(reservation.Date),
+				Value:  formatDateRange(reservation),
 				Inline: true,
 			},
 			{
 				Name:   "🕐 時間",
-				Value:  fmt.Sprintf("%s - %s", reservation.StartTime, reservation.EndTime),
+				Value:  formatTimeRange(reservation),
 				Inline: true,
 			},
 		},
@@ -81,6 +115,8 @@ func handleComplete(s *discordgo.Session, i *discordgo.InteractionCreate, store
 			Text: "部室予約システム  |  complete",
 		},
 	}
+	completeEmbed.Fields = appendResourceField(completeEmbed.Fields, reservation.ResourceID)
+	completeEmbed.Fields = a.appendOverrideField(completeEmbed.Fields, reservation)
 	if comment != "" {
 		completeEmbed.Fields = append(completeEmbed.Fields, &discordgo.MessageEmbedField{
 			Name:   "💬 コメント",
@@ -88,11 +124,6 @@ func handleComplete(s *discordgo.Session, i *discordgo.InteractionCreate, store
 			Inline: false,
 		})
 	}
-	// DMから実行された場合も、指定チャンネルに通知
-	s.ChannelMessageSendEmbed(allowedChannelID, completeEmbed)
-
-	// Botステータスを更新
-	if UpdateStatusCallback != nil {
-		UpdateStatusCallback()
-	}
+	// DMから実行された場合も、指定チャンネル（部屋ごとの通知先があればそちら）に通知
+	s.ChannelMessageSendEmbed(notificationChannel(reservation.ResourceID, allowedChannelID), completeEmbed)
 }
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -12,7 +13,7 @@ import (
 )
 
 // handleEdit は予約編集コマンドを処理する
-func handleEdit(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, logger *logging.Logger, allowedChannelID string, isDM bool) {
+func handleEdit(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool) {
 	options := i.ApplicationCommandData().Options
 	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
 	for _, opt := range options {
@@ -20,7 +21,7 @@ func handleEdit(s *discordgo.Session, i *discordgo.InteractionCreate, store *sto
 	}
 
 	// ユーザー情報を取得
-	userID, username := getUserInfo(i, isDM)
+	a := getActor(i, isDM)
 
 	// 予約IDを取得
 	reservationID := optionMap["reservation_id"].StringValue()
@@ -32,18 +33,36 @@ func handleEdit(s *discordgo.Session, i *discordgo.InteractionCreate, store *sto
 		return
 	}
 
-	// 予約の所有者チェック
-	if reservation.UserID != userID {
+	// 予約の所有者チェック（管理者は他のユーザーの予約も編集できる）
+	if !a.canModify(reservation) {
 		respondError(s, i, "他のユーザーの予約は編集できません。")
 		return
 	}
 
 	// ステータスチェック
 	if reservation.Status != models.StatusPending {
-		respondError(s, i, "完了またはキャンセルされた予約は編集できません。")
+		respondError(s, i, "利用中・完了・キャンセルされた予約は編集できません。")
 		return
 	}
 
+	req, change, inputErr := parseEditOptions(reservation, optionMap)
+	if inputErr != nil {
+		respondError(s, i, inputErr.Message)
+		return
+	}
+
+	// 繰り返し予約の場合は scope に応じてまとめて編集する
+	if scope := getScopeOption(optionMap); scope != scopeThis && reservation.IsRecurring() {
+		editSeries(s, i, store, logger, allowedChannelID, isDM, a, reservation, scope, change)
+		return
+	}
+
+	applyEdit(s, i, store, logger, allowedChannelID, isDM, a, reservation, req)
+}
+
+// parseEditOptions は /edit のオプションを検証し、この予約の変更後の値と、繰り返し予約の各回に適用する変更内容を返す
+// 指定されていない項目は現在の値を保持する
+func parseEditOptions(reservation *models.Reservation, optionMap map[string]*discordgo.ApplicationCommandInteractionDataOption) (editRequest, seriesChange, *inputError) {
 	// 変更前の情報を保持
 	oldDate := reservation.Date
 	oldStartTime := reservation.StartTime
@@ -58,62 +77,61 @@ func handleEdit(s *discordgo.Session, i *discordgo.InteractionCreate, store *sto
 
 	hasChanges := false
 
-	// 日付の変更
+	// 日付の変更（「金曜 15時」のように時刻も含む場合は、開始時間の指定がなければ開始時間も変更する）
+	dateStr, startStr := "", ""
 	if opt, ok := optionMap["date"]; ok {
-		dateStr := opt.StringValue()
-		// 日付を正規化
-		dateStr = normalizeDate(dateStr)
-
-		// 日付の形式を検証
-		var parsedDate time.Time
-		if t, err := time.Parse("2006-01-02", dateStr); err != nil {
-			if t2, err2 := time.Parse("2006/01/02", dateStr); err2 == nil {
-				dateStr = t2.Format("2006-01-02")
-				parsedDate = t2
-			} else {
-				respondError(s, i, "日付の形式が正しくありません（YYYY-MM-DD または YYYY/MM/DD 形式で入力してください）")
-				return
-			}
-		} else {
-			parsedDate = t
+		dateStr = opt.StringValue()
+	}
+	if opt, ok := optionMap["start_time"]; ok {
+		startStr = opt.StringValue()
+	}
+	dateStr, startStr = splitDateTimeInput(dateStr, startStr)
+
+	if dateStr != "" {
+		parsedDate, inputErr := parseDateInput(dateStr)
+		if inputErr != nil {
+			return editRequest{}, seriesChange{}, inputErr
 		}
 
-		// 過去の日付チェック
-		jst := time.FixedZone("Asia/Tokyo", 9*60*60)
-		now := time.Now().In(jst)
-		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, jst)
-		if parsedDate.Before(today) {
-			respondError(s, i, "過去の日付には変更できません。")
-			return
+		// 過去の日付チェック（部室のタイムゾーンでの今日と比較）
+		if parsedDate.Format("2006-01-02") < models.Today() {
+			return editRequest{}, seriesChange{}, newInputError("過去の日付には変更できません。")
 		}
 
-		newDate = dateStr
+		newDate = parsedDate.Format("2006-01-02")
 		hasChanges = true
 	}
 
 	// 開始時間の変更
-	if opt, ok := optionMap["start_time"]; ok {
-		timeStr := opt.StringValue()
-		// 時刻を正規化
-		timeStr = normalizeTime(timeStr)
-
-		if _, err := time.Parse("15:04", timeStr); err != nil {
-			respondError(s, i, "開始時間の形式が正しくありません（HH:MM形式で入力してください）")
-			return
+	if startStr != "" {
+		timeStr, inputErr := parseClockInput(startStr, "開始時間")
+		if inputErr != nil {
+			return editRequest{}, seriesChange{}, inputErr
 		}
 		newStartTime = timeStr
 		hasChanges = true
 	}
 
-	// 終了時間の変更
+	// 終了時間の変更（「2時間」のような長さは開始時刻からの利用時間とみなす）
+	_, hasEndTime := optionMap["end_time"]
+	_, hasDuration := optionMap["duration"]
+	if hasEndTime && hasDuration {
+		return editRequest{}, seriesChange{}, newInputError("終了時間と利用時間はどちらか一方だけ指定してください。")
+	}
 	if opt, ok := optionMap["end_time"]; ok {
-		timeStr := opt.StringValue()
-		// 時刻を正規化
-		timeStr = normalizeTime(timeStr)
+		timeStr, inputErr := parseEndTimeInput(newStartTime, opt.StringValue())
+		if inputErr != nil {
+			return editRequest{}, seriesChange{}, inputErr
+		}
+		newEndTime = timeStr
+		hasChanges = true
+	}
 
-		if _, err := time.Parse("15:04", timeStr); err != nil {
-			respondError(s, i, "終了時間の形式が正しくありません（HH:MM形式で入力してください）")
-			return
+	// 利用時間の変更（変更後の開始時刻から数える）
+	if opt, ok := optionMap["duration"]; ok {
+		timeStr, inputErr := endTimeFromDuration(newStartTime, opt.StringValue())
+		if inputErr != nil {
+			return editRequest{}, seriesChange{}, inputErr
 		}
 		newEndTime = timeStr
 		hasChanges = true
@@ -125,38 +143,110 @@ func handleEdit(s *discordgo.Session, i *discordgo.InteractionCreate, store *sto
 		hasChanges = true
 	}
 
+	// 部屋の変更
+	oldResourceID := reservation.GetResourceID()
+	newResourceID := oldResourceID
+	if opt, ok := optionMap["room"]; ok {
+		resource, found := findResource(opt.StringValue())
+		if !found {
+			return editRequest{}, seriesChange{}, newInputError("指定された部屋が見つかりません。候補から選択してください。")
+		}
+		newResourceID = resource.ID
+		hasChanges = true
+	}
+
 	// 変更がない場合
 	if !hasChanges {
-		respondError(s, i, "変更する項目を少なくとも1つ指定してください。")
-		return
+		return editRequest{}, seriesChange{}, newInputError("変更する項目を少なくとも1つ指定してください。")
 	}
 
-	// 時刻の整合性チェック
-	if newEndTime <= newStartTime {
-		respondError(s, i, "終了時間は開始時間より後である必要があります。")
-		return
+	// 時刻の整合性チェック（終了時刻が開始時刻より前の場合は翌日に終わる予約とみなす）
+	if newEndTime == newStartTime {
+		return editRequest{}, seriesChange{}, newInputError("終了時間は開始時間と異なる時刻である必要があります。")
 	}
 
-	// 重複チェック用に一時的な予約オブジェクトを作成
-	tempReservation := &models.Reservation{
-		ID:        reservationID, // 自分の予約は除外するためにIDを設定
-		UserID:    userID,
-		Username:  username,
-		Date:      newDate,
-		StartTime: newStartTime,
-		EndTime:   newEndTime,
-		Comment:   newComment,
-		Status:    models.StatusPending,
+	// 繰り返し予約の各回には、指定された項目だけを適用する
+	// 開始時間は日付の入力から取り出した時刻（例: "金曜 15時"）も含めて判定する
+	change := seriesChange{}
+	if dateStr != "" {
+		from, _ := time.Parse("2006-01-02", oldDate)
+		to, _ := time.Parse("2006-01-02", newDate)
+		change.dateShift = int(to.Sub(from).Hours() / 24)
 	}
+	if startStr != "" {
+		change.startTime = newStartTime
+	}
+	if hasEndTime || hasDuration {
+		change.endTime = newEndTime
+	}
+	if _, ok := optionMap["comment"]; ok {
+		change.comment = &newComment
+	}
+	if _, ok := optionMap["room"]; ok {
+		change.resourceID = newResourceID
+	}
+
+	return editRequest{
+		Date:       newDate,
+		StartTime:  newStartTime,
+		EndTime:    newEndTime,
+		Comment:    newComment,
+		ResourceID: newResourceID,
+	}, change, nil
+}
+
+// editRequest は予約の変更後の値（検証済み）
+type editRequest struct {
+	Date       string
+	StartTime  string
+	EndTime    string
+	Comment    string
+	ResourceID string
+}
 
-	// 時間の重複をチェック（自分の予約以外と）
-	overlappingReservation, err := store.CheckOverlap(tempReservation)
+// applyEdit は予約を変更後の値で更新し、実行者と通知チャンネルに結果を送信する（更新できた場合は true を返す）
+// 重複する予約がある場合は更新せず、近くの空いている時間帯を候補のボタンとして表示する
+func applyEdit(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool, a actor, reservation *models.Reservation, req editRequest) bool {
+	userID, username := a.UserID, a.Username
+	reservationID := reservation.ID
+	newDate, newStartTime, newEndTime := req.Date, req.StartTime, req.EndTime
+	newComment, newResourceID := req.Comment, req.ResourceID
+
+	// 保存済みの予約に変更を適用し、重複チェックと更新を不可分に実行（自分の予約以外との重複を確認）
+	// 権限とステータスもロック内で確認し、リマインダーの送信記録など編集中に行われた他の変更は保持する
+	updated, overlappingReservation, err := store.UpdateIfFree(reservationID, func(r *models.Reservation) error {
+		if err := a.authorize(r); err != nil {
+			return err
+		}
+		if r.Status != models.StatusPending {
+			return errNotActive
+		}
+		reservation = r.Clone()
+		r.Date = newDate
+		r.EndDate = models.ResolveEndDate(newDate, newStartTime, newEndTime)
+		r.StartTime = newStartTime
+		r.EndTime = newEndTime
+		r.Comment = newComment
+		r.ResourceID = newResourceID
+		r.UpdatedAt = time.Now()
+		r.ResetReminder(reservation)
+		r.RecordHistory(reservation, newHistoryEntry(a, "edit", models.HistoryEdited))
+		return nil
+	})
+	if err == errForbidden {
+		respondError(s, i, "他のユーザーの予約は編集できません。")
+		return false
+	}
+	if err == errNotActive {
+		respondError(s, i, "利用中・完了・キャンセルされた予約は編集できません。")
+		return false
+	}
 	if err != nil {
-		respondError(s, i, "予約の重複チェックに失敗しました")
-		logger.LogError("ERROR", "handleEdit", "Failed to check overlap", err, map[string]interface{}{
+		respondError(s, i, "予約の更新に失敗しました。")
+		logger.LogError("ERROR", "handleEdit", "Failed to update reservation", err, map[string]interface{}{
 			"reservation_id": reservationID,
 		})
-		return
+		return false
 	}
 
 	if overlappingReservation != nil {
@@ -173,10 +263,16 @@ func handleEdit(s *discordgo.Session, i *discordgo.InteractionCreate, store *sto
 			},
 			{
 				Name:   "🕐 時間",
-				Value:  fmt.Sprintf("%s - %s", overlappingReservation.StartTime, overlappingReservation.EndTime),
+				Value:  formatTimeRange(overlappingReservation),
 				Inline: true,
 			},
 		}
+		fields = appendResourceField(fields, overlappingReservation.ResourceID)
+
+		// 同じ長さで空いている近くの時間帯を提案する（編集中の予約自身は空きとして扱う）
+		slot := slotInput{Date: newDate, EndDate: models.ResolveEndDate(newDate, newStartTime, newEndTime), StartTime: newStartTime, EndTime: newEndTime}
+		alternativesField, components := offerAlternatives(store, logger, actionEditAlternative, pendingEdit{ReservationID: reservationID, Request: req}, newResourceID, reservationID, slot)
+		fields = append(fields, alternativesField)
 
 		embed := &discordgo.MessageEmbed{
 			Title:       "🔴 予約を編集できませんでした",
@@ -190,30 +286,27 @@ func handleEdit(s *discordgo.Session, i *discordgo.InteractionCreate, store *sto
 		}
 
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Type: resultResponseType(i),
 			Data: &discordgo.InteractionResponseData{
-				Embeds: []*discordgo.MessageEmbed{embed},
-				Flags:  discordgo.MessageFlagsEphemeral,
+				Embeds:     []*discordgo.MessageEmbed{embed},
+				Components: components,
+				Flags:      discordgo.MessageFlagsEphemeral,
 			},
 		})
-		return
+		return false
 	}
 
-	// 予約を更新
-	reservation.Date = newDate
-	reservation.StartTime = newStartTime
-	reservation.EndTime = newEndTime
-	reservation.Comment = newComment
-
 	if err := store.Save(); err != nil {
 		respondError(s, i, "予約の更新に失敗しました。")
 		logger.LogError("ERROR", "handleEdit", "Failed to save reservation", err, map[string]interface{}{
 			"reservation_id": reservationID,
 		})
-		return
+		return false
 	}
 
-	// 成功メッセージ
+	// 成功メッセージ（変更前の値はロック内で取得した保存済みの予約から表示する）
+	oldDate, oldStartTime, oldEndTime := reservation.Date, reservation.StartTime, reservation.EndTime
+	oldComment, oldResourceID := reservation.Comment, reservation.GetResourceID()
 	fields := []*discordgo.MessageEmbedField{
 		{
 			Name:   "🆔 予約ID",
@@ -233,7 +326,14 @@ func handleEdit(s *discordgo.Session, i *discordgo.InteractionCreate, store *sto
 	if oldStartTime != newStartTime || oldEndTime != newEndTime {
 		fields = append(fields, &discordgo.MessageEmbedField{
 			Name:   "🕐 時間",
-			Value:  fmt.Sprintf("%s-%s → %s-%s", oldStartTime, oldEndTime, newStartTime, newEndTime),
+			Value:  fmt.Sprintf("%s → %s", formatTimeRange(reservation), formatTimeRange(updated)),
+			Inline: false,
+		})
+	}
+	if oldResourceID != newResourceID {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "🚪 部屋",
+			Value:  fmt.Sprintf("%s → %s", resourceName(oldResourceID), resourceName(newResourceID)),
 			Inline: false,
 		})
 	}
@@ -253,13 +353,43 @@ func handleEdit(s *discordgo.Session, i *discordgo.InteractionCreate, store *sto
 		})
 	}
 
-	respondEmbedWithFields(s, i, "🟡 予約を編集しました", "", fields, 0xFEE75C, true)
+	a.logOverride(logger, "edit", reservation, map[string]interface{}{
+		"date":       newDate,
+		"start_time": newStartTime,
+		"end_time":   newEndTime,
+		"room":       newResourceID,
+		"comment":    newComment,
+	})
+	fields = a.appendOverrideField(fields, reservation)
+
+	// 続けて延長・取り消しなどができるように操作ボタンを付ける
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: resultResponseType(i),
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:     "🟡 予約を編集しました",
+				Fields:    fields,
+				Color:     0xFEE75C, // Discord Yellow
+				Timestamp: time.Now().Format(time.RFC3339),
+			}},
+			Components: reservationButtons(updated),
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	// 予約追加の通知を変更後の内容に更新
+	refreshReservationNotice(s, updated, "")
 
 	// 公開通知(変更がある場合)
+	noticeChannelID := notificationChannel(newResourceID, allowedChannelID)
 	if !isDM {
+		description := fmt.Sprintf("<@%s> さんが予約を編集しました", userID)
+		if a.overrides(reservation) {
+			description = fmt.Sprintf("管理者 <@%s> さんが <@%s> さんの予約を編集しました", userID, reservation.UserID)
+		}
 		editEmbed := &discordgo.MessageEmbed{
 			Title:       "🟡 予約が編集されました",
-			Description: fmt.Sprintf("<@%s> さんが予約を編集しました", userID),
+			Description: description,
 			Fields:      fields,
 			Color:       0xFEE75C, // Discord Yellow
 			Timestamp:   time.Now().Format(time.RFC3339),
@@ -267,8 +397,8 @@ func handleEdit(s *discordgo.Session, i *discordgo.InteractionCreate, store *sto
 				Text: "部室予約システム  |  edit",
 			},
 		}
-		s.ChannelMessageSendEmbed(allowedChannelID, editEmbed)
-	} else if allowedChannelID != "" {
+		s.ChannelMessageSendEmbed(noticeChannelID, editEmbed)
+	} else if noticeChannelID != "" {
 		// DMから実行された場合も、指定チャンネルに通知
 		editEmbed := &discordgo.MessageEmbed{
 			Title:       "🟡 予約が編集されました",
@@ -280,8 +410,217 @@ func handleEdit(s *discordgo.Session, i *discordgo.InteractionCreate, store *sto
 				Text: "部室予約システム  |  edit",
 			},
 		}
-		s.ChannelMessageSendEmbed(allowedChannelID, editEmbed)
+		s.ChannelMessageSendEmbed(noticeChannelID, editEmbed)
+	}
+
+	// 空いた時間帯をキャンセル待ちのユーザーに案内する
+	notifyWaitlist(s, store, logger)
+
+	// Botステータスを更新
+	if UpdateStatusCallback != nil {
+		UpdateStatusCallback()
+	}
+	return true
+}
+
+// seriesChange は繰り返し予約の各回に適用する変更内容（ゼロ値の項目は変更しない）
+type seriesChange struct {
+	dateShift  int     // 日付をずらす日数
+	startTime  string  // 新しい開始時間（HH:MM形式）
+	endTime    string  // 新しい終了時間（HH:MM形式）
+	comment    *string // 新しいコメント
+	resourceID string  // 新しい部屋ID
+}
+
+// apply は予約のコピーに変更内容を適用する
+func (c seriesChange) apply(r *models.Reservation) *models.Reservation {
+	updated := r.Clone()
+	if c.dateShift != 0 {
+		if date, err := time.Parse("2006-01-02", r.Date); err == nil {
+			updated.Date = date.AddDate(0, 0, c.dateShift).Format("2006-01-02")
+		}
+	}
+	if c.startTime != "" {
+		updated.StartTime = c.startTime
+	}
+	if c.endTime != "" {
+		updated.EndTime = c.endTime
+	}
+	if c.comment != nil {
+		updated.Comment = *c.comment
+	}
+	updated.EndDate = models.ResolveEndDate(updated.Date, updated.StartTime, updated.EndTime)
+	if c.resourceID != "" {
+		updated.ResourceID = c.resourceID
 	}
+	updated.ResetReminder(r)
+	updated.UpdatedAt = time.Now()
+	return updated
+}
+
+// editSeries は繰り返し予約のうち scope に含まれる予約中の予約をまとめて編集する
+// 重複する回や時刻が不正になる回は変更せずに報告する
+func editSeries(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool, a actor, reservation *models.Reservation, scope string, change seriesChange) {
+	userID, username := a.UserID, a.Username
+
+	targets, err := seriesTargets(store, reservation, scope)
+	if err != nil {
+		respondError(s, i, "予約の取得に失敗しました。")
+		logger.LogError("ERROR", "handleEdit", "Failed to query series", err, map[string]interface{}{
+			"series_id": reservation.SeriesID,
+		})
+		return
+	}
+
+	today := models.Today()
+
+	var updatedLines, skippedLines []string
+	for _, target := range targets {
+		// 保存済みの各回に変更を適用し、重複チェックと更新を不可分に実行する
+		updated, overlappingReservation, err := store.UpdateIfFree(target.ID, func(r *models.Reservation) error {
+			if err := a.authorize(r); err != nil {
+				return err
+			}
+			if r.Status != models.StatusPending {
+				return errNotActive
+			}
+			target = r.Clone()
+			changed := change.apply(r)
+			if changed.EndTime == changed.StartTime {
+				return newInputError("終了時間が開始時間と同じになります")
+			}
+			if changed.Date < today {
+				return newInputError("過去の日付になります")
+			}
+			changed.RecordHistory(r, newHistoryEntry(a, "edit", models.HistoryEdited))
+			*r = *changed
+			return nil
+		})
+		var inputErr *inputError
+		if errors.As(err, &inputErr) {
+			skippedLines = append(skippedLines, fmt.Sprintf("%s（%s）", formatOccurrence(target), inputErr.Message))
+			continue
+		}
+		if err == errForbidden {
+			skippedLines = append(skippedLines, fmt.Sprintf("%s（他のユーザーの予約です）", formatOccurrence(target)))
+			continue
+		}
+		if err == errNotActive {
+			skippedLines = append(skippedLines, fmt.Sprintf("%s（利用中・完了・キャンセル済みです）", formatOccurrence(target)))
+			continue
+		}
+		if err != nil {
+			logger.LogError("ERROR", "handleEdit", "Failed to update reservation", err, map[string]interface{}{
+				"reservation_id": target.ID,
+				"series_id":      reservation.SeriesID,
+			})
+			skippedLines = append(skippedLines, fmt.Sprintf("%s（更新に失敗しました）", formatOccurrence(target)))
+			continue
+		}
+		if overlappingReservation != nil {
+			skippedLines = append(skippedLines, fmt.Sprintf("%s（<@%s> %s と重複）",
+				formatOccurrence(target), overlappingReservation.UserID, formatTimeRange(overlappingReservation)))
+			continue
+		}
+
+		updatedLines = append(updatedLines, fmt.Sprintf("%s → %s", formatOccurrence(target), formatOccurrence(updated)))
+	}
+
+	if len(updatedLines) == 0 {
+		fields := []*discordgo.MessageEmbedField{}
+		if len(skippedLines) > 0 {
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:   "⚠️ 変更できなかった予約",
+				Value:  joinLines(skippedLines),
+				Inline: false,
+			})
+		}
+		respondEmbedWithFields(s, i, "🔴 予約を編集できませんでした", "変更できる予約がありませんでした。", fields, 0xED4245, true)
+		return
+	}
+
+	if err := store.Save(); err != nil {
+		respondError(s, i, "予約の更新に失敗しました。")
+		logger.LogError("ERROR", "handleEdit", "Failed to save reservation", err, map[string]interface{}{
+			"series_id": reservation.SeriesID,
+		})
+		return
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "🔁 範囲",
+			Value:  scopeLabel(scope),
+			Inline: true,
+		},
+	}
+	if change.resourceID != "" && change.resourceID != reservation.GetResourceID() {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "🚪 部屋",
+			Value:  fmt.Sprintf("%s → %s", resourceName(reservation.GetResourceID()), resourceName(change.resourceID)),
+			Inline: true,
+		})
+	}
+	if change.comment != nil {
+		commentDisplay := *change.comment
+		if commentDisplay == "" {
+			commentDisplay = "（なし）"
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "💬 コメント",
+			Value:  commentDisplay,
+			Inline: false,
+		})
+	}
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:   fmt.Sprintf("📅 変更した予約（%d件）", len(updatedLines)),
+		Value:  joinLines(updatedLines),
+		Inline: false,
+	})
+	if len(skippedLines) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("⚠️ 変更できなかった予約（%d件）", len(skippedLines)),
+			Value:  joinLines(skippedLines),
+			Inline: false,
+		})
+	}
+
+	a.logOverride(logger, "edit", reservation, map[string]interface{}{
+		"scope":     scope,
+		"series_id": reservation.SeriesID,
+		"count":     len(updatedLines),
+	})
+	fields = a.appendOverrideField(fields, reservation)
+
+	respondEmbedWithFields(s, i, "🟡 繰り返し予約を編集しました", "", fields, 0xFEE75C, true)
+
+	// 公開通知（DMから実行された場合も指定チャンネルに通知）
+	resourceID := reservation.GetResourceID()
+	if change.resourceID != "" {
+		resourceID = change.resourceID
+	}
+	if noticeChannelID := notificationChannel(resourceID, allowedChannelID); noticeChannelID != "" {
+		description := fmt.Sprintf("<@%s> さんが繰り返し予約を編集しました", userID)
+		if a.overrides(reservation) {
+			description = fmt.Sprintf("管理者 <@%s> さんが <@%s> さんの繰り返し予約を編集しました", userID, reservation.UserID)
+		} else if isDM {
+			description = fmt.Sprintf("%s さんが繰り返し予約を編集しました", username)
+		}
+		editEmbed := &discordgo.MessageEmbed{
+			Title:       "🟡 予約が編集されました",
+			Description: description,
+			Fields:      fields,
+			Color:       0xFEE75C, // Discord Yellow
+			Timestamp:   time.Now().Format(time.RFC3339),
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: "部室予約システム  |  edit",
+			},
+		}
+		s.ChannelMessageSendEmbed(noticeChannelID, editEmbed)
+	}
+
+	// 空いた時間帯をキャンセル待ちのユーザーに案内する
+	notifyWaitlist(s, store, logger)
 
 	// Botステータスを更新
 	if UpdateStatusCallback != nil {
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestParseEditOptionsSeriesWithDateTime(t *testing.T) {
+	reservation := &models.Reservation{ID: "weekly", Date: "2030-05-10", EndDate: "2030-05-10", StartTime: "10:00", EndTime: "11:00", SeriesID: "series"}
+	option := func(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+	}
+
+	// 日付に含めた開始時間も、繰り返し予約の各回に適用する（利用時間はその開始時刻から数える）
+	req, change, inputErr := parseEditOptions(reservation, map[string]*discordgo.ApplicationCommandInteractionDataOption{
+		"date":     option("date", "2030/05/17 15時"),
+		"duration": option("duration", "2時間"),
+	})
+	if inputErr != nil {
+		t.Fatalf("parseEditOptions failed: %s", inputErr.Message)
+	}
+	if req.Date != "2030-05-17" || req.StartTime != "15:00" || req.EndTime != "17:00" {
+		t.Errorf("Unexpected edit request %+v", req)
+	}
+	if change.dateShift != 7 || change.startTime != "15:00" || change.endTime != "17:00" {
+		t.Errorf("Expected the series change to shift by a week and move to 15:00-17:00, got %+v", change)
+	}
+	updated := change.apply(&models.Reservation{Date: "2030-05-24", EndDate: "2030-05-24", StartTime: "10:00", EndTime: "11:00"})
+	if updated.Date != "2030-05-31" || updated.StartTime != "15:00" || updated.EndTime != "17:00" {
+		t.Errorf("Unexpected occurrence after the series edit %+v", updated)
+	}
+
+	// 日付だけの場合は開始時間を変更しない
+	_, change, inputErr = parseEditOptions(reservation, map[string]*discordgo.ApplicationCommandInteractionDataOption{
+		"date": option("date", "2030/05/17"),
+	})
+	if inputErr != nil || change.startTime != "" || change.endTime != "" || change.dateShift != 7 {
+		t.Errorf("Expected only a date shift, got %+v (%v)", change, inputErr)
+	}
+}
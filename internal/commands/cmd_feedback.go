@@ -7,6 +7,7 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
 )
 
 // handleFeedback はフィードバックコマンドを処理する（匿名で特定チャンネルに転送）
@@ -34,8 +35,8 @@ func handleFeedback(s *discordgo.Session, i *discordgo.InteractionCreate, logger
 		return
 	}
 
-	// タイムスタンプを生成
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	// タイムスタンプを生成（部室のタイムゾーン）
+	timestamp := models.Now().Format("2006-01-02 15:04:05")
 
 	// フィードバックチャンネルに匿名で転送
 	feedbackEmbed := &discordgo.MessageEmbed{
@@ -5,46 +5,91 @@ import (
 	"github.com/dice/hxs_reservation_system/internal/logging"
 )
 
-// handleHelp はヘルプコマンドを処理する（コマンドを打った人にしか見えない）
-func handleHelp(s *discordgo.Session, i *discordgo.InteractionCreate, logger *logging.Logger, isDM bool) {
-	helpMessage := "# 📖部室予約システム - ヘルプ\n" +
+// helpMessageLimit は1つのメッセージに送信できる文字数の上限
+const helpMessageLimit = 2000
+
+// helpMessages はヘルプの本文を返す（メッセージの文字数の上限に収まるように、コマンド一覧とその他の説明に分ける）
+func helpMessages() []string {
+	commandsMessage := "# 📖部室予約システム - ヘルプ\n" +
 		"## 利用可能なコマンド:\n" +
 		"**/reserve**\n" +
 		"> 部室の予約を作成します\n" +
-		"> - `date`: 予約日（YYYY-MM-DD または YYYY/MM/DD、例: 2025-10-15）\n" +
-		"> - `start_time`: 開始時間（HH:MM形式、例: 14:00）\n" +
-		"> - `end_time`: 終了時間（HH:MM形式、例: 15:00）※省略時は開始時刻+1時間\n" +
-		"> - `comment`: コメント（任意）\n\n" +
+		"> - `date`: 予約日（例: 2025/10/15、明日、来週火曜、金曜 15時）\n" +
+		"> - `start_time`: 開始時間（例: 14:00、15時半、3pm）\n" +
+		"> - `end_time`: 終了時間（例: 15:00、2時間）※省略時は開始時刻+1時間、開始より前の時刻は翌日扱い\n" +
+		"> - `duration`: 利用時間（例: 30m、1h30m、2時間）※`end_time` の代わりに指定\n" +
+		"> - `room`: 部屋（任意）※省略時は部室\n" +
+		"> - `comment`: コメント（任意）\n" +
+		"> ※ `date` と `start_time` を省略すると入力フォームが開きます\n\n" +
+		"**/reserve-recurring**\n" +
+		"> 毎週・隔週・毎月の繰り返し予約をまとめて作成します\n" +
+		"> - `date`, `start_time`, `end_time`, `room`, `comment`: /reserve と同じ\n" +
+		"> - `frequency`: 繰り返しの間隔（毎週・隔週・毎月）\n" +
+		"> - `until` / `count`: 最終日または回数（どちらかを指定）\n\n" +
 		"**/edit**\n" +
 		"> 予約を編集します\n" +
 		"> - `reservation_id`: 予約ID\n" +
 		"> - `date`: 予約日（任意）\n" +
 		"> - `start_time`: 開始時間（任意）\n" +
-		"> - `end_time`: 終了時間（任意）\n" +
-		"> - `comment`: コメント（任意）\n\n" +
+		"> - `end_time` / `duration`: 終了時間または利用時間（任意）\n" +
+		"> - `room`: 部屋（任意）\n" +
+		"> - `comment`: コメント（任意）\n" +
+		"> - `scope`: 繰り返し予約の変更範囲（任意）※この予約のみ・この予約以降・シリーズ全体\n\n" +
 		"**/cancel**\n" +
 		"> 予約を取り消します\n" +
 		"> - `reservation_id`: 予約ID\n" +
-		"> - `comment`: コメント（任意）\n\n" +
+		"> - `comment`: コメント（任意）\n" +
+		"> - `scope`: 繰り返し予約の取り消し範囲（任意）\n\n" +
 		"**/complete**\n" +
 		"> 予約を完了にします\n" +
 		"> - `reservation_id`: 予約ID\n" +
 		"> - `comment`: コメント（任意）\n\n" +
+		"**/checkin**\n" +
+		"> 予約にチェックインして利用中にします（開始15分前から）\n" +
+		"> - `reservation_id`: 予約ID（任意、省略時は今の自分の予約）\n\n" +
 		"**/list**\n" +
-		"> すべての予約を表示します（自分だけに表示されます）\n\n" +
+		"> すべての予約を表示します（自分だけに表示されます）\n" +
+		"> - `date`・`until`・`user`・`room`・`status`: 期間・予約者・部屋・状態で絞り込み（任意）\n\n" +
 		"**/my-reservations**\n" +
 		"> 自分の予約を表示します（自分だけに表示されます）\n\n" +
+		"**/today** / **/week** / **/calendar**\n" +
+		"> 今日・1週間の予約を時間割や画像で表示します（自分だけに表示されます）\n" +
+		"> - `date` / `room`: 表示する週・部屋（/week・/calendar、任意）\n" +
+		"> - `color_by`: 画像の色分け（メンバー・部屋、/calendar のみ）\n\n" +
+		"**/availability**\n" +
+		"> 空き時間を表示し、ボタンから予約できます（自分だけに表示されます）\n" +
+		"> - `date` / `until`: 日付・期間（任意）\n" +
+		"> - `duration`: 必要な最短時間（任意）\n\n" +
+		"**/history**\n" +
+		"> 予約の変更履歴を表示します（自分だけに表示されます）\n" +
+		"> - `reservation_id`: 予約ID\n\n" +
+		"**/waitlist**\n" +
+		"> 埋まっている時間帯のキャンセル待ちに登録し、空いたらDMで予約を案内します\n" +
+		"> - `date`, `start_time`, `end_time`, `room`, `comment`: /reserve と同じ（省略すると登録中の一覧）\n\n" +
+		"**/reminders**\n" +
+		"> 予約開始前のリマインダー（DM）を受け取るかを設定します\n" +
+		"> - `setting`: 受け取る・受け取らない（省略時は現在の設定を表示）\n\n" +
+		"**/penalties**（管理者のみ）\n" +
+		"> 違反（無断欠席・直前の取り消し）の記録を表示・リセットします\n" +
+		"> - `user` / `action`: 対象のユーザー・表示かリセットか（任意）\n\n" +
 		"**/feedback**\n" +
 		"> システムへのご意見・ご要望を匿名で送信します\n" +
 		"> - `message`: フィードバック内容\n\n" +
 		"**/help**\n" +
-		"> このヘルプメッセージを表示します\n\n" +
-		"## プライバシー:\n" +
-		"- /list、/my-reservations、/help、/feedback は自分だけに表示されます\n" +
+		"> このヘルプメッセージを表示します\n"
+
+	infoMessage := "## プライバシー:\n" +
+		"- /list、/my-reservations、/today、/week、/calendar、/availability、/history、/waitlist、/reminders、/help、/feedback は自分だけに表示されます\n" +
 		"- 予約作成時、予約IDは予約者だけに通知されます\n" +
-		"- フィードバックは完全に匿名で送信されます\n\n" +
+		"- 編集・取り消し・完了は予約者本人と管理者のみ行えます\n" +
+		"- 予約メッセージのボタンからチェックイン・延長・編集・完了・取り消しもできます\n" +
+		"- フィードバックは完全に匿名で送信されます\n" +
+		"- 予約開始の少し前に予約者へリマインダーがDMで届きます（/reminders で停止できます）\n\n" +
 		"## データ管理:\n" +
-		"- 完了・キャンセル済みの予約は30日後に自動削除されます\n" +
+		"- 開始後しばらくチェックインがない予約は解放され、チャンネルで空きが告知されます\n" +
+		"- 予約が取り消されるなどして空くと、キャンセル待ちの登録順にDMで案内が届き、期限内に「予約する」を押すと予約できます\n" +
+		"- 無断欠席や開始直前の取り消しが続くと、一定期間同時に持てる予約の数が制限されます（/my-reservations で確認できます）\n" +
+		"- 完了・キャンセル済み・無断欠席の予約は30日後に自動削除されます\n" +
 		"- 期限切れの予約は毎日午前3時に自動完了されます\n\n" +
 		"## 利用可能チャンネル:\n" +
 		"- https://discord.com/channels/1090816023965479035/1375843736864559195で利用が可能です\n" +
@@ -54,9 +99,23 @@ func handleHelp(s *discordgo.Session, i *discordgo.InteractionCreate, logger *lo
 		"## サポート:\n" +
 		"- 問題が発生した場合は、フィードバックまでご連絡ください\n"
 
+	return []string{commandsMessage, infoMessage}
+}
+
+// handleHelp はヘルプコマンドを処理する（コマンドを打った人にしか見えない）
+func handleHelp(s *discordgo.Session, i *discordgo.InteractionCreate, logger *logging.Logger, isDM bool) {
 	userID, username := getUserInfo(i, isDM)
 
-	respondEphemeral(s, i, helpMessage)
+	messages := helpMessages()
+	respondEphemeral(s, i, messages[0])
+	for _, message := range messages[1:] {
+		if _, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content: message,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		}); err != nil {
+			logger.LogError("ERROR", "handleHelp", "Failed to send help message", err, nil)
+		}
+	}
 
 	// ログに記録
 	logger.LogCommand("help", userID, username, i.ChannelID, true, "", nil)
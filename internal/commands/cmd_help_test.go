@@ -0,0 +1,14 @@
+package commands
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestHelpMessagesFitMessageLimit(t *testing.T) {
+	for idx, message := range helpMessages() {
+		if n := utf8.RuneCountInString(message); n > helpMessageLimit {
+			t.Errorf("Help message %d has %d characters (limit %d)", idx, n, helpMessageLimit)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// maxHistoryFields は履歴として表示するフィールドの最大数（埋め込みのフィールドは25個まで）
+const maxHistoryFields = 20
+
+// historyTextBudget は履歴のフィールドに使う文字数の上限（埋め込み全体は6000文字まで）
+const historyTextBudget = 4500
+
+// maxHistoryNoteLength は履歴に表示するコメント・変更前後の値の最大文字数
+// 1件の履歴がフィールドの値の上限（embedFieldValueLimit）を超えないようにする
+const maxHistoryNoteLength = 200
+
+// historyFieldLabels は変更履歴の項目の表示名
+var historyFieldLabels = map[string]string{
+	"date":        "日付",
+	"end_date":    "終了日",
+	"start_time":  "開始時間",
+	"end_time":    "終了時間",
+	"resource_id": "部屋",
+	"comment":     "コメント",
+	"status":      "状態",
+}
+
+// newHistoryEntry はコマンドを実行したユーザーによる変更履歴を作成する
+func newHistoryEntry(a actor, command string, action models.HistoryAction) models.HistoryEntry {
+	return models.HistoryEntry{
+		At:        time.Now(),
+		ActorID:   a.UserID,
+		ActorName: a.Username,
+		Source:    models.CommandSource(command),
+		Action:    action,
+	}
+}
+
+// handleHistory は予約の変更履歴を表示する（予約者本人または管理者のみ）
+func handleHistory(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool) {
+	var reservationID string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "reservation_id" {
+			reservationID = opt.StringValue()
+		}
+	}
+
+	reservation, err := store.GetReservation(reservationID)
+	if err != nil {
+		respondError(s, i, "予約が見つかりませんでした。予約IDを確認してください。")
+		return
+	}
+
+	a := getActor(i, isDM)
+	if !a.canModify(reservation) {
+		respondError(s, i, "他のユーザーの予約の履歴は表示できません。")
+		return
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "👤 予約者",
+			Value:  fmt.Sprintf("<@%s>", reservation.UserID),
+			Inline: false,
+		},
+		{
+			Name:   "📅 日付",
+			Value:  formatDateRange(reservation),
+			Inline: true,
+		},
+		{
+			Name:   "🕐 時間",
+			Value:  formatTimeRange(reservation),
+			Inline: true,
+		},
+	}
+	fields = appendResourceField(fields, reservation.ResourceID)
+
+	// 新しい履歴から文字数の上限まで表示し、古い順に並べる
+	historyFields := []*discordgo.MessageEmbedField{}
+	used := 0
+	for idx := len(reservation.History) - 1; idx >= 0 && len(historyFields) < maxHistoryFields; idx-- {
+		entry := reservation.History[idx]
+		field := &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("%s %s  ·  %s", historyActionEmoji(entry.Action), entry.Action.Label(), entry.At.In(models.Location()).Format("2006/01/02 15:04")),
+			Value:  formatHistoryEntry(entry),
+			Inline: false,
+		}
+		used += len([]rune(field.Name)) + len([]rune(field.Value))
+		if used > historyTextBudget {
+			break
+		}
+		historyFields = append([]*discordgo.MessageEmbedField{field}, historyFields...)
+	}
+	fields = append(fields, historyFields...)
+
+	description := fmt.Sprintf("予約ID: `%s`\n%s 現在の状態: %s", reservation.ID, getStatusEmoji(reservation.Status), statusLabel(reservation.Status))
+	if len(reservation.History) == 0 {
+		description += "\n\n変更履歴はありません（履歴の記録を始める前に作成された予約です）。"
+	}
+	if omitted := len(reservation.History) - len(historyFields); omitted > 0 {
+		description += fmt.Sprintf("\n\n古い履歴 %d 件は省略しています。", omitted)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "📜 予約の変更履歴",
+		Description: description,
+		Fields:      fields,
+		Color:       0x5865F2,
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "部室予約システム  |  history",
+		},
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// formatHistoryEntry は変更履歴1件の内容（操作者・変更元・変更内容）を表示用の文字列にする
+func formatHistoryEntry(entry models.HistoryEntry) string {
+	lines := []string{}
+	if entry.IsSystem() {
+		lines = append(lines, fmt.Sprintf("⚙️ %s", historySourceLabel(entry.Source)))
+	} else {
+		lines = append(lines, fmt.Sprintf("👤 <@%s>（%s）", entry.ActorID, historySourceLabel(entry.Source)))
+	}
+
+	var dateChange *models.FieldChange
+	for idx := range entry.Changes {
+		if entry.Changes[idx].Field == "date" {
+			dateChange = &entry.Changes[idx]
+		}
+	}
+	for _, change := range entry.Changes {
+		// 日を跨がない予約の終了日は日付と同じ変更になるため表示しない
+		if change.Field == "end_date" && dateChange != nil && change.From == dateChange.From && change.To == dateChange.To {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s → %s", historyFieldLabel(change.Field),
+			truncateHistoryText(formatHistoryValue(change.Field, change.From)), truncateHistoryText(formatHistoryValue(change.Field, change.To))))
+	}
+
+	if entry.Note != "" {
+		lines = append(lines, fmt.Sprintf("💬 %s", truncateHistoryText(entry.Note)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncateHistoryText は maxHistoryNoteLength 文字を超える部分を省略する
+func truncateHistoryText(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxHistoryNoteLength {
+		return text
+	}
+	return string(runes[:maxHistoryNoteLength]) + "…"
+}
+
+// historyFieldLabel は変更された項目の表示名を返す
+func historyFieldLabel(field string) string {
+	if label, ok := historyFieldLabels[field]; ok {
+		return label
+	}
+	return field
+}
+
+// formatHistoryValue は変更前後の値を項目に応じて表示用の文字列にする
+func formatHistoryValue(field, value string) string {
+	if value == "" {
+		return "（なし）"
+	}
+	switch field {
+	case "date", "end_date":
+		return formatDate(value)
+	case "resource_id":
+		return resourceName(value)
+	case "status":
+		return statusLabel(models.ReservationStatus(value))
+	default:
+		return value
+	}
+}
+
+// historySourceLabel は変更元の表示名を返す
+func historySourceLabel(source string) string {
+	if command, ok := strings.CutPrefix(source, models.CommandSource("")); ok {
+		return "/" + command
+	}
+	if action, ok := strings.CutPrefix(source, models.ButtonSource("")); ok {
+		if label, found := reservationButtonLabels[action]; found {
+			return fmt.Sprintf("「%s」ボタン", label)
+		}
+		return "ボタン"
+	}
+	switch source {
+	case models.SourceAutoComplete:
+		return "期限切れ予約の自動完了"
+	case models.SourceNoShow:
+		return "チェックインのない予約の自動解放"
+	default:
+		return source
+	}
+}
+
+// historyActionEmoji は操作の種類に対応する絵文字を返す
+func historyActionEmoji(action models.HistoryAction) string {
+	switch action {
+	case models.HistoryCreated:
+		return "🟢"
+	case models.HistoryEdited:
+		return "🟡"
+	case models.HistoryCancelled:
+		return "🔴"
+	case models.HistoryCompleted:
+		return "🔵"
+	case models.HistoryCheckedIn:
+		return "🟣"
+	default:
+		return "⚪"
+	}
+}
+
+// statusLabel はステータスの表示名を返す
+func statusLabel(status models.ReservationStatus) string {
+	switch status {
+	case models.StatusPending:
+		return "予約中"
+	case models.StatusCheckedIn:
+		return "利用中"
+	case models.StatusCompleted:
+		return "完了"
+	case models.StatusCancelled:
+		return "キャンセル済み"
+	case models.StatusNoShow:
+		return "無断欠席"
+	default:
+		return string(status)
+	}
+}
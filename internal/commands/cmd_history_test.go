@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestFormatHistoryEntryTruncatesLongValues(t *testing.T) {
+	long := strings.Repeat("あ", 1500)
+	entry := models.HistoryEntry{
+		ActorID: "user1",
+		Source:  models.CommandSource("edit"),
+		Action:  models.HistoryEdited,
+		Changes: []models.FieldChange{{Field: "comment", From: long, To: long}},
+		Note:    long,
+	}
+
+	// 長いコメントを変更しても、1件の履歴がフィールドの値の上限を超えない
+	value := formatHistoryEntry(entry)
+	if n := len([]rune(value)); n > embedFieldValueLimit {
+		t.Errorf("Expected at most %d characters, got %d", embedFieldValueLimit, n)
+	}
+	if !strings.Contains(value, strings.Repeat("あ", maxHistoryNoteLength)+"…") {
+		t.Errorf("Expected long values to be truncated with an ellipsis, got %q", value)
+	}
+}
@@ -2,7 +2,8 @@ package commands
 
 import (
 	"fmt"
-	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -11,163 +12,260 @@ import (
 	"github.com/dice/hxs_reservation_system/internal/storage"
 )
 
-// handleList はすべての予約一覧を表示する
-func handleList(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, logger *logging.Logger, isDM bool) {
-	allReservations := store.GetAllReservations()
-	// 完了・キャンセル済みを除外
-	reservations := make([]*models.Reservation, 0)
-	for _, r := range allReservations {
-		if r.Status != models.StatusCompleted && r.Status != models.StatusCancelled {
-			reservations = append(reservations, r)
-		}
+const (
+	listPageSize         = 10 // 1ページに表示する予約の件数
+	maxListCommentLength = 40 // 一覧に表示するコメントの最大文字数
+)
+
+// 一覧で絞り込む状態（status オプションの値）
+const (
+	listStatusPending   = "pending"
+	listStatusCompleted = "completed"
+	listStatusCancelled = "cancelled"
+	listStatusNoShow    = "no_show"
+	listStatusAll       = "all"
+)
+
+// listFilter は /list の絞り込み条件
+// ページ送りのボタンでも同じ条件で検索し直せるように、カスタムIDに埋め込む
+type listFilter struct {
+	DateFrom   string // この日付以降（YYYY-MM-DD形式、空の場合は指定なし）
+	DateTo     string // この日付以前（YYYY-MM-DD形式、空の場合は指定なし）
+	UserID     string // 予約者のDiscord ID
+	ResourceID string // 部屋ID
+	Status     string // 状態（listStatus* のいずれか）
+}
+
+// query は絞り込み条件をストレージの検索条件に変換する
+func (f listFilter) query() storage.Query {
+	query := storage.Query{
+		UserID:     f.UserID,
+		ResourceID: f.ResourceID,
+		DateFrom:   f.DateFrom,
+		DateTo:     f.DateTo,
+	}
+	switch f.Status {
+	case listStatusCompleted:
+		query.Statuses = []models.ReservationStatus{models.StatusCompleted}
+	case listStatusCancelled:
+		query.Statuses = []models.ReservationStatus{models.StatusCancelled}
+	case listStatusNoShow:
+		query.Statuses = []models.ReservationStatus{models.StatusNoShow}
+	case listStatusAll:
+	default:
+		// 予約中には利用中（チェックイン済み）の予約も含める
+		query.Statuses = models.ActiveStatuses
 	}
+	return query
+}
 
-	if len(reservations) == 0 {
-		respondEmbed(s, i, "⚫ 予約一覧", "現在、予約はありません。", 0x000000, true)
-		return
+// describe は絞り込み条件を表示用の文字列にする（条件がない場合は空）
+func (f listFilter) describe() string {
+	parts := []string{}
+	switch {
+	case f.DateFrom != "" && f.DateTo != "":
+		parts = append(parts, fmt.Sprintf("📅 %s 〜 %s", formatDate(f.DateFrom), formatDate(f.DateTo)))
+	case f.DateFrom != "":
+		parts = append(parts, fmt.Sprintf("📅 %s 以降", formatDate(f.DateFrom)))
+	case f.DateTo != "":
+		parts = append(parts, fmt.Sprintf("📅 %s 以前", formatDate(f.DateTo)))
+	}
+	if f.UserID != "" {
+		parts = append(parts, fmt.Sprintf("👤 <@%s>", f.UserID))
+	}
+	if f.ResourceID != "" {
+		parts = append(parts, "🚪 "+resourceName(f.ResourceID))
 	}
+	if f.Status != listStatusPending {
+		parts = append(parts, "状態: "+listStatusLabel(f.Status))
+	}
+	return strings.Join(parts, "  ")
+}
 
-	// 日時でソート
-	sort.Slice(reservations, func(a, b int) bool {
-		tA, errA := reservations[a].GetStartDateTime()
-		tB, errB := reservations[b].GetStartDateTime()
-		if errA != nil || errB != nil {
-			return a < b
-		}
-		return tA.Before(tB)
-	})
+// listStatusLabel は状態の絞り込みの表示名を返す
+func listStatusLabel(status string) string {
+	switch status {
+	case listStatusCompleted:
+		return "完了"
+	case listStatusCancelled:
+		return "キャンセル"
+	case listStatusNoShow:
+		return "無断欠席"
+	case listStatusAll:
+		return "すべて"
+	default:
+		return "予約中"
+	}
+}
 
-	// 最初のメッセージ（ヘッダー + 最初の予約9件）
-	embeds := []*discordgo.MessageEmbed{}
+// listPageCustomID はページ送りボタンのカスタムIDを作成する
+func listPageCustomID(filter listFilter, page int) string {
+	return newCustomID(actionListPage, strconv.Itoa(page), filter.DateFrom, filter.DateTo, filter.UserID, filter.ResourceID, filter.Status)
+}
 
-	// ヘッダー
-	headerDescription := fmt.Sprintf("現在 %d 件の予約があります", len(reservations))
-	headerEmbed := &discordgo.MessageEmbed{
-		Title:       "⚫ すべての予約一覧",
-		Description: headerDescription,
-		Color:       0x000000,
-		Timestamp:   time.Now().Format(time.RFC3339),
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "部室予約システム  |  list",
-		},
+// parseListPageArgs はページ送りボタンの引数（ページ, 開始日, 終了日, 予約者, 部屋, 状態）を分解する
+func parseListPageArgs(args []string) (listFilter, int, bool) {
+	if len(args) != 6 {
+		return listFilter{}, 0, false
 	}
-	embeds = append(embeds, headerEmbed)
-
-	// 最初の9件を表示
-	maxFirstMessage := 9
-	for idx := 0; idx < len(reservations) && idx < maxFirstMessage; idx++ {
-		r := reservations[idx]
-
-		fields := []*discordgo.MessageEmbedField{
-			{
-				Name:   "👤 予約者",
-				Value:  fmt.Sprintf("<@%s>", r.UserID),
-				Inline: false,
-			},
-			{
-				Name:   "📅 日付",
-				Value:  formatDate(r.Date),
-				Inline: true,
-			},
-			{
-				Name:   "🕐 時間",
-				Value:  fmt.Sprintf("%s - %s", r.StartTime, r.EndTime),
-				Inline: true,
-			},
-		}
+	page, err := strconv.Atoi(args[0])
+	if err != nil {
+		return listFilter{}, 0, false
+	}
+	return listFilter{DateFrom: args[1], DateTo: args[2], UserID: args[3], ResourceID: args[4], Status: args[5]}, page, true
+}
 
-		if r.Comment != "" {
-			fields = append(fields, &discordgo.MessageEmbedField{
-				Name:   "💬 コメント",
-				Value:  r.Comment,
-				Inline: false,
-			})
+// handleList は予約一覧を絞り込み条件付きでページごとに表示する
+func handleList(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool) {
+	filter := listFilter{Status: listStatusPending}
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "room":
+			resource, found := findResource(opt.StringValue())
+			if !found {
+				respondError(s, i, "指定された部屋が見つかりません。候補から選択してください。")
+				return
+			}
+			filter.ResourceID = resource.ID
+		case "date", "until":
+			date, inputErr := parseDateInput(opt.StringValue())
+			if inputErr != nil {
+				respondError(s, i, inputErr.Message)
+				return
+			}
+			if opt.Name == "date" {
+				filter.DateFrom = date.Format("2006-01-02")
+			} else {
+				filter.DateTo = date.Format("2006-01-02")
+			}
+		case "user":
+			filter.UserID = opt.UserValue(nil).ID
+		case "status":
+			filter.Status = opt.StringValue()
 		}
+	}
+	if filter.DateFrom != "" && filter.DateTo != "" && filter.DateTo < filter.DateFrom {
+		respondError(s, i, "`until` には `date` 以降の日付を指定してください。")
+		return
+	}
+	if len(listPageCustomID(filter, 0)) > customIDMaxLength {
+		// 部屋IDが長すぎてボタンに条件を埋め込めない場合（通常は発生しない）
+		respondError(s, i, "絞り込み条件が長すぎます。部屋の指定を外してお試しください。")
+		return
+	}
 
-		reservationEmbed := &discordgo.MessageEmbed{
-			Title:     fmt.Sprintf("No.%d", idx+1),
-			Fields:    fields,
-			Color:     0x000000,
-			Timestamp: time.Now().Format(time.RFC3339),
-			Footer: &discordgo.MessageEmbedFooter{
-				Text: fmt.Sprintf("部室予約システム  |  list  |  予約 %d/%d", idx+1, len(reservations)),
-			},
-		}
-		embeds = append(embeds, reservationEmbed)
+	respondListPage(s, i, store, logger, discordgo.InteractionResponseChannelMessageWithSource, filter, 0)
+}
+
+// handleListPageButton はページ送りのボタンが押されたときに、同じメッセージを指定したページに更新する
+func handleListPageButton(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, args []string) {
+	filter, page, ok := parseListPageArgs(args)
+	if !ok {
+		respondError(s, i, "この操作は利用できません。もう一度コマンドを実行してください。")
+		return
 	}
+	respondListPage(s, i, store, logger, discordgo.InteractionResponseUpdateMessage, filter, page)
+}
+
+// respondListPage は絞り込み条件に一致する予約の一覧のうち、指定したページを表示する
+func respondListPage(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, responseType discordgo.InteractionResponseType, filter listFilter, page int) {
+	reservations, err := store.QueryReservations(filter.query())
+	if err != nil {
+		respondError(s, i, "予約の取得に失敗しました")
+		logger.LogError("ERROR", "handleList", "Failed to query reservations", err, nil)
+		return
+	}
+	embed, components := listPage(reservations, filter, page)
 
-	// 最初のメッセージを送信
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Type: responseType,
 		Data: &discordgo.InteractionResponseData{
-			Embeds: embeds,
-			Flags:  discordgo.MessageFlagsEphemeral,
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
 		},
 	})
+}
 
-	// 残りの予約を複数のメッセージで送信（10件ごと）
-	if len(reservations) > maxFirstMessage {
-		// 10件ずつ（ヘッダーなし）のフォローアップメッセージを送信
-		itemsPerMessage := 10
-		for startIdx := maxFirstMessage; startIdx < len(reservations); startIdx += itemsPerMessage {
-			endIdx := startIdx + itemsPerMessage
-			if endIdx > len(reservations) {
-				endIdx = len(reservations)
-			}
+// listPage は予約一覧の1ページ分の埋め込みメッセージとページ送りのボタンを作成する
+// 予約が減ってページが範囲外になった場合は最後のページを表示する
+func listPage(reservations []*models.Reservation, filter listFilter, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	// 日時でソート
+	sortByStart(reservations)
 
-			messageEmbeds := []*discordgo.MessageEmbed{}
-			for idx := startIdx; idx < endIdx; idx++ {
-				r := reservations[idx]
-
-				fields := []*discordgo.MessageEmbedField{
-					{
-						Name:   "👤 予約者",
-						Value:  fmt.Sprintf("<@%s>", r.UserID),
-						Inline: false,
-					},
-					{
-						Name:   "📅 日付",
-						Value:  formatDate(r.Date),
-						Inline: true,
-					},
-					{
-						Name:   "🕐 時間",
-						Value:  fmt.Sprintf("%s - %s", r.StartTime, r.EndTime),
-						Inline: true,
-					},
-				}
-
-				if r.Comment != "" {
-					fields = append(fields, &discordgo.MessageEmbedField{
-						Name:   "💬 コメント",
-						Value:  r.Comment,
-						Inline: false,
-					})
-				}
-
-				reservationEmbed := &discordgo.MessageEmbed{
-					Title:     fmt.Sprintf("No.%d", idx+1),
-					Fields:    fields,
-					Color:     0x000000,
-					Timestamp: time.Now().Format(time.RFC3339),
-					Footer: &discordgo.MessageEmbedFooter{
-						Text: fmt.Sprintf("部室予約システム  |  list  |  予約 %d/%d", idx+1, len(reservations)),
-					},
-				}
-				messageEmbeds = append(messageEmbeds, reservationEmbed)
-			}
+	pages := (len(reservations) + listPageSize - 1) / listPageSize
+	if page >= pages {
+		page = pages - 1
+	}
+	if page < 0 {
+		page = 0
+	}
 
-			// フォローアップメッセージを送信（Ephemeral）
-			_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
-				Embeds: messageEmbeds,
-				Flags:  discordgo.MessageFlagsEphemeral,
-			})
-			if err != nil {
-				logger.LogError("ERROR", "handleList", "Failed to send followup message", err, map[string]interface{}{
-					"start_idx": startIdx,
-					"end_idx":   endIdx,
-				})
-			}
+	description := fmt.Sprintf("%d 件の予約があります", len(reservations))
+	if len(reservations) == 0 {
+		description = "条件に一致する予約はありません。"
+	}
+	if conditions := filter.describe(); conditions != "" {
+		description = conditions + "\n" + description
+	}
+
+	lines := []string{}
+	start := page * listPageSize
+	for idx := start; idx < len(reservations) && idx < start+listPageSize; idx++ {
+		lines = append(lines, formatListLine(idx+1, reservations[idx], filter.Status != listStatusPending))
+	}
+	if len(lines) > 0 {
+		description += "\n\n" + strings.Join(lines, "\n")
+	}
+
+	footer := "部室予約システム  |  list"
+	if pages > 1 {
+		footer = fmt.Sprintf("部室予約システム  |  list  |  ページ %d/%d", page+1, pages)
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       "⚫ 予約一覧",
+		Description: description,
+		Color:       0x000000,
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: footer,
+		},
+	}
+
+	if pages <= 1 {
+		return embed, []discordgo.MessageComponent{}
+	}
+	return embed, buttonRows([]discordgo.Button{
+		{
+			Label:    "◀ 前へ",
+			Style:    discordgo.SecondaryButton,
+			CustomID: listPageCustomID(filter, page-1),
+			Disabled: page == 0,
+		},
+		{
+			Label:    "次へ ▶",
+			Style:    discordgo.SecondaryButton,
+			CustomID: listPageCustomID(filter, page+1),
+			Disabled: page >= pages-1,
+		},
+	})
+}
+
+// formatListLine は一覧の1件を1〜2行で表示する（withStatus が true の場合は状態の絵文字を付ける）
+func formatListLine(number int, r *models.Reservation, withStatus bool) string {
+	line := fmt.Sprintf("**%d.** %s %s  <@%s>", number, formatDateRange(r), formatTimeRange(r), r.UserID)
+	if withStatus || r.Status == models.StatusCheckedIn {
+		line = getStatusEmoji(r.Status) + " " + line
+	}
+	if hasMultipleResources() {
+		line += "  🚪 " + resourceName(r.ResourceID)
+	}
+	if r.Comment != "" {
+		comment := []rune(strings.ReplaceAll(r.Comment, "\n", " "))
+		if len(comment) > maxListCommentLength {
+			comment = append(comment[:maxListCommentLength], []rune("…")...)
 		}
+		line += "\n　💬 " + string(comment)
 	}
+	return line
 }
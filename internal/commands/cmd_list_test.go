@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestListPageCustomIDRoundTrip(t *testing.T) {
+	filter := listFilter{DateFrom: "2030-01-01", DateTo: "2030-01-31", UserID: "123456789012345678", ResourceID: "main", Status: listStatusAll}
+	customID := listPageCustomID(filter, 3)
+	if len(customID) > customIDMaxLength {
+		t.Fatalf("Custom ID %q is too long", customID)
+	}
+	action, args := parseCustomID(customID)
+	got, page, ok := parseListPageArgs(args)
+	if action != actionListPage || !ok || page != 3 || got != filter {
+		t.Errorf("Round trip failed: %q -> %+v page %d", customID, got, page)
+	}
+}
+
+func TestListPageFiltersAndPaginates(t *testing.T) {
+	store := newTestStore(t)
+	day := models.Now().AddDate(0, 0, 5)
+	for idx := 0; idx < 23; idx++ {
+		date := day.AddDate(0, 0, idx).Format("2006-01-02")
+		status := models.StatusPending
+		if idx%5 == 0 {
+			status = models.StatusCancelled
+		}
+		if err := store.AddReservation(&models.Reservation{
+			ID: fmt.Sprintf("r%02d", idx), UserID: fmt.Sprintf("user-%d", idx%2),
+			Date: date, EndDate: date, StartTime: "10:00", EndTime: "11:00",
+			Status: status, ResourceID: models.DefaultResourceID,
+		}); err != nil {
+			t.Fatalf("AddReservation failed: %v", err)
+		}
+	}
+
+	filter := listFilter{Status: listStatusPending}
+	reservations, err := store.QueryReservations(filter.query())
+	if err != nil {
+		t.Fatalf("QueryReservations failed: %v", err)
+	}
+	if len(reservations) != 18 {
+		t.Fatalf("Expected 18 pending reservations, got %d", len(reservations))
+	}
+
+	embed, components := listPage(reservations, filter, 1)
+	if !strings.Contains(embed.Footer.Text, "ページ 2/2") {
+		t.Errorf("Expected page 2 of 2, got footer %q", embed.Footer.Text)
+	}
+	if !strings.Contains(embed.Description, "**11.**") || strings.Contains(embed.Description, "**10.**") {
+		t.Errorf("Expected the second page to start at No.11:\n%s", embed.Description)
+	}
+	buttons := components[0].(discordgo.ActionsRow).Components
+	if prev, next := buttons[0].(discordgo.Button), buttons[1].(discordgo.Button); prev.Disabled || !next.Disabled {
+		t.Errorf("Expected only the previous button to be enabled on the last page")
+	}
+
+	// 予約が減ってページが範囲外になった場合は最後のページを表示する
+	embed, _ = listPage(reservations[:5], filter, 4)
+	if !strings.Contains(embed.Description, "**5.**") {
+		t.Errorf("Expected the out-of-range page to fall back to the last page:\n%s", embed.Description)
+	}
+
+	filter = listFilter{UserID: "user-1", Status: listStatusCancelled, DateTo: day.AddDate(0, 0, 15).Format("2006-01-02")}
+	reservations, err = store.QueryReservations(filter.query())
+	if err != nil {
+		t.Fatalf("QueryReservations failed: %v", err)
+	}
+	// idx 5, 15 が user-1 のキャンセル済みの予約（idx 0, 10, 20 は user-0 または期間外）
+	if len(reservations) != 2 {
+		t.Errorf("Expected 2 cancelled reservations of user-1, got %d", len(reservations))
+	}
+	if _, components := listPage(reservations, filter, 0); len(components) != 0 {
+		t.Errorf("Expected no page buttons for a single page")
+	}
+}
@@ -12,20 +12,23 @@ import (
 )
 
 // handleMyReservations は自分の予約一覧を表示する
-func handleMyReservations(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, logger *logging.Logger, isDM bool) {
+func handleMyReservations(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool) {
 	userID, _ := getUserInfo(i, isDM)
 
 	allReservations := store.GetUserReservations(userID)
-	// 完了・キャンセル済みを除外
+	// 完了・キャンセル済み・無断欠席を除外
 	reservations := make([]*models.Reservation, 0)
 	for _, r := range allReservations {
-		if r.Status != models.StatusCompleted && r.Status != models.StatusCancelled {
+		if r.Status.IsActive() {
 			reservations = append(reservations, r)
 		}
 	}
 
+	// 違反の回数と予約の制限（ある場合のみ）
+	standing := standingDescription(userID, models.Now())
+
 	if len(reservations) == 0 {
-		respondEmbed(s, i, "⚪ あなたの予約一覧", "あなたの予約はありません。", 0xFFFFFF, true)
+		respondEmbed(s, i, "⚪ あなたの予約一覧", appendStanding("あなたの予約はありません。", standing), 0xFFFFFF, true)
 		return
 	}
 
@@ -43,7 +46,7 @@ func handleMyReservations(s *discordgo.Session, i *discordgo.InteractionCreate,
 	embeds := []*discordgo.MessageEmbed{}
 
 	// ヘッダー
-	headerDescription := fmt.Sprintf("現在 %d 件の予約があります", len(reservations))
+	headerDescription := appendStanding(fmt.Sprintf("現在 %d 件の予約があります", len(reservations)), standing)
 	headerEmbed := &discordgo.MessageEmbed{
 		Title:       "⚪ あなたの予約一覧",
 		Description: headerDescription,
@@ -68,15 +71,16 @@ func handleMyReservations(s *discordgo.Session, i *discordgo.InteractionCreate,
 			},
 			{
 				Name:   "📅 日付",
-				Value:  formatDate(r.Date),
+				Value:  formatDateRange(r),
 				Inline: true,
 			},
 			{
 				Name:   "🕐 時間",
-				Value:  fmt.Sprintf("%s - %s", r.StartTime, r.EndTime),
+				Value:  formatTimeRange(r),
 				Inline: true,
 			},
 		}
+		fields = appendResourceField(fields, r.ResourceID)
 
 		if r.Comment != "" {
 			fields = append(fields, &discordgo.MessageEmbedField{
@@ -129,15 +133,16 @@ func handleMyReservations(s *discordgo.Session, i *discordgo.InteractionCreate,
 					},
 					{
 						Name:   "📅 日付",
-						Value:  formatDate(r.Date),
+						Value:  formatDateRange(r),
 						Inline: true,
 					},
 					{
 						Name:   "🕐 時間",
-						Value:  fmt.Sprintf("%s - %s", r.StartTime, r.EndTime),
+						Value:  formatTimeRange(r),
 						Inline: true,
 					},
 				}
+				fields = appendResourceField(fields, r.ResourceID)
 
 				if r.Comment != "" {
 					fields = append(fields, &discordgo.MessageEmbedField{
@@ -10,166 +10,157 @@ import (
 	"github.com/dice/hxs_reservation_system/internal/storage"
 )
 
+// reserveRequest は予約作成の入力（未検証の値）
+type reserveRequest struct {
+	Date      string
+	StartTime string
+	EndTime   string // 空の場合は開始時刻+1時間
+	Room      string // 部屋IDまたは表示名（空の場合は既定の部屋）
+	Comment   string
+}
+
 // handleReserve は予約作成コマンドを処理する
-func handleReserve(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, logger *logging.Logger, allowedChannelID string, isDM bool) {
+func handleReserve(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool) {
 	options := i.ApplicationCommandData().Options
 	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
 	for _, opt := range options {
 		optionMap[opt.Name] = opt
 	}
 
-	// ユーザー情報を取得
-	userID, username := getUserInfo(i, isDM)
-
-	// 必須パラメータを取得
-	date := optionMap["date"].StringValue()
-	startTime := optionMap["start_time"].StringValue()
+	req := reserveRequest{}
+	if opt, ok := optionMap["date"]; ok {
+		req.Date = opt.StringValue()
+	}
+	if opt, ok := optionMap["start_time"]; ok {
+		req.StartTime = opt.StringValue()
+	}
+	if opt, ok := optionMap["end_time"]; ok {
+		req.EndTime = opt.StringValue()
+	}
+	if opt, ok := optionMap["comment"]; ok {
+		req.Comment = opt.StringValue()
+	}
+	if opt, ok := optionMap["room"]; ok {
+		req.Room = opt.StringValue()
+	}
+	duration := ""
+	if opt, ok := optionMap["duration"]; ok {
+		duration = opt.StringValue()
+	}
+	if duration != "" && req.EndTime != "" {
+		respondError(s, i, "終了時間と利用時間はどちらか一方だけ指定してください。")
+		return
+	}
 
-	// 日付を正規化（YYYY/M/D → YYYY/MM/DD）
-	date = normalizeDate(date)
+	// 日付に時刻も含まれている場合（例: "金曜 15時"）は開始時間として使う
+	req.Date, req.StartTime = splitDateTimeInput(req.Date, req.StartTime)
 
-	// 時刻を正規化（H:MM → HH:MM）
-	startTime = normalizeTime(startTime)
+	// 日付と開始時間を両方省略した場合は入力フォームを表示する
+	if req.Date == "" && req.StartTime == "" {
+		if duration == "" {
+			duration = defaultFormDuration
+		}
+		openReserveForm(s, i, reserveForm{Duration: duration, Room: req.Room, Comment: req.Comment})
+		return
+	}
+	if req.Date == "" || req.StartTime == "" {
+		respondError(s, i, "日付と開始時間を両方指定してください（両方省略すると入力フォームが開きます）。")
+		return
+	}
 
-	// オプションパラメータを取得
-	var endTime string
-	if opt, ok := optionMap["end_time"]; ok {
-		endTime = opt.StringValue()
-		// 時刻を正規化（H:MM → HH:MM）
-		endTime = normalizeTime(endTime)
-	} else {
-		// 終了時間が指定されていない場合は開始時刻+1時間
-		start, err := time.Parse("15:04", startTime)
-		if err != nil {
-			respondError(s, i, "開始時間の形式が正しくありません（HH:MM形式で入力してください）")
+	// 利用時間を指定した場合は開始時刻からの終了時間に置き換える
+	if duration != "" {
+		startTime, inputErr := parseClockInput(req.StartTime, "開始時間")
+		if inputErr != nil {
+			respondError(s, i, inputErr.Message)
+			return
+		}
+		if req.EndTime, inputErr = endTimeFromDuration(startTime, duration); inputErr != nil {
+			respondError(s, i, inputErr.Message)
 			return
 		}
-		endTime = start.Add(1 * time.Hour).Format("15:04")
 	}
 
-	comment := ""
-	if opt, ok := optionMap["comment"]; ok {
-		comment = opt.StringValue()
-	}
+	createReservation(s, i, store, logger, allowedChannelID, isDM, "reserve", req)
+}
+
+// createReservation は入力を検証して予約を作成し、予約者と通知チャンネルに結果を送信する（予約できた場合は true を返す）
+// スラッシュコマンドとモーダル・ボタンから使うため、command にはログと履歴に記録する呼び出し元を渡す
+// 重複する予約がある場合は、近くの空いている時間帯を候補のボタンとして表示する
+func createReservation(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool, command string, req reserveRequest) bool {
+	// ユーザー情報を取得
+	userID, username := getUserInfo(i, isDM)
+	date, startTime, endTime, room, comment := req.Date, req.StartTime, req.EndTime, req.Room, req.Comment
 
 	// ログ用パラメータを構築
 	parameters := map[string]interface{}{
 		"date":       date,
 		"start_time": startTime,
 		"end_time":   endTime,
+		"room":       room,
 	}
 	if comment != "" {
 		parameters["comment"] = comment
 	}
 
-	// 日付と時間の形式を検証（YYYY-MM-DD または YYYY/MM/DD を許可）
-	var reservationDate time.Time
-	if parsedDate, err := time.Parse("2006-01-02", date); err != nil {
-		if t2, err2 := time.Parse("2006/01/02", date); err2 == nil {
-			// 正規化して保存用は YYYY-MM-DD に統一
-			date = t2.Format("2006-01-02")
-			reservationDate = t2
-		} else {
-			errorMsg := "日付の形式が正しくありません（YYYY-MM-DD または YYYY/MM/DD）"
-			logger.LogCommand("reserve", userID, username, i.ChannelID, false, errorMsg, parameters)
-			respondError(s, i, errorMsg)
-			return
-		}
-	} else {
-		reservationDate = parsedDate
-	}
-
-	var startTimeParsed time.Time
-	if t, err := time.Parse("15:04", startTime); err != nil {
-		errorMsg := "開始時間の形式が正しくありません（HH:MM形式で入力してください）"
-		logger.LogCommand("reserve", userID, username, i.ChannelID, false, errorMsg, parameters)
-		respondError(s, i, errorMsg)
-		return
-	} else {
-		startTimeParsed = t
-	}
-
-	if _, err := time.Parse("15:04", endTime); err != nil {
-		errorMsg := "終了時間の形式が正しくありません（HH:MM形式で入力してください）"
-		logger.LogCommand("reserve", userID, username, i.ChannelID, false, errorMsg, parameters)
-		respondError(s, i, errorMsg)
-		return
+	// 部屋を取得（省略時は既定の部屋）
+	resourceID, inputErr := parseResourceOption(room)
+	if inputErr != nil {
+		logger.LogCommand(command, userID, username, i.ChannelID, false, inputErr.Reason, parameters)
+		respondError(s, i, inputErr.Message)
+		return false
 	}
 
-	// 終了時刻が開始時刻より前または同じ時刻でないかチェック
-	if endTime <= startTime {
-		errorMsg := fmt.Sprintf("❌ 終了時刻は開始時刻より後である必要があります\n\n"+
-			"**開始時刻:** %s\n"+
-			"**終了時刻:** %s\n\n"+
-			"終了時刻を開始時刻より後の時刻に設定してください。",
-			startTime,
-			endTime,
-		)
-		logger.LogCommand("reserve", userID, username, i.ChannelID, false, "End time before start time", parameters)
-		respondEphemeral(s, i, errorMsg)
-		return
-	}
-
-	// 過去日時のチェック
-	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
-	nowJST := time.Now().In(jst)
-
-	// 予約日時を構築（日付 + 開始時刻）
-	reservationDateTime := time.Date(
-		reservationDate.Year(),
-		reservationDate.Month(),
-		reservationDate.Day(),
-		startTimeParsed.Hour(),
-		startTimeParsed.Minute(),
-		0, 0, jst,
-	)
-
-	// 現在時刻より過去の場合はエラー
-	if reservationDateTime.Before(nowJST) {
-		errorMsg := fmt.Sprintf("❌ 過去の日時は予約できません\n\n"+
-			"**指定された日時:** %s %s\n"+
-			"**現在日時:** %s\n\n"+
-			"現在時刻以降の日時を指定してください。",
-			formatDate(date),
-			startTime,
-			nowJST.Format("2006-01-02 15:04"),
-		)
-		logger.LogCommand("reserve", userID, username, i.ChannelID, false, "Past datetime", parameters)
-		respondEphemeral(s, i, errorMsg)
-		return
+	// 日付と時間を検証・正規化
+	slot, inputErr := parseSlotInput(date, startTime, endTime)
+	if inputErr != nil {
+		logger.LogCommand(command, userID, username, i.ChannelID, false, inputErr.Reason, parameters)
+		respondError(s, i, inputErr.Message)
+		return false
 	}
+	date = slot.Date
 
 	// 予約IDを生成
 	reservationID, err := models.GenerateReservationID()
 	if err != nil {
 		respondError(s, i, "予約IDの生成に失敗しました")
-		return
+		return false
 	}
 
 	// 予約を作成
 	reservation := &models.Reservation{
-		ID:        reservationID,
-		UserID:    userID,
-		Username:  username,
-		Date:      date,
-		StartTime: startTime,
-		EndTime:   endTime,
-		Comment:   comment,
-		Status:    models.StatusPending,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		ChannelID: allowedChannelID, // 公開メッセージの送信先は常に指定チャンネル
-	}
-
-	// 時間の重複をチェック
-	overlappingReservation, err := store.CheckOverlap(reservation)
+		ID:         reservationID,
+		UserID:     userID,
+		Username:   username,
+		Date:       slot.Date,
+		EndDate:    slot.EndDate,
+		StartTime:  slot.StartTime,
+		EndTime:    slot.EndTime,
+		Comment:    comment,
+		Status:     models.StatusPending,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+		ChannelID:  allowedChannelID, // 公開メッセージの送信先は常に指定チャンネル
+		ResourceID: resourceID,
+	}
+	reservation.RecordHistory(nil, newHistoryEntry(actor{UserID: userID, Username: username}, command, models.HistoryCreated))
+
+	// 重複チェックと保存を不可分に実行（同時予約による二重予約を防ぐ）
+	// 違反が続いて予約が制限されているユーザーは、制限内の件数まで予約できる
+	restriction, overlappingReservation, err := reserveWithinLimit(store, reservation, models.Now())
 	if err != nil {
-		respondError(s, i, "予約の重複チェックに失敗しました")
-		logger.LogError("ERROR", "handlers.handleReserve", "Failed to check overlap", err, map[string]interface{}{
-			"user_id": userID,
-			"date":    date,
+		respondError(s, i, "予約の保存に失敗しました")
+		logger.LogError("ERROR", "handlers.handleReserve", "Failed to reserve", err, map[string]interface{}{
+			"user_id":        userID,
+			"reservation_id": reservation.ID,
+			"date":           date,
 		})
-		return
+		return false
+	}
+	if restriction != "" {
+		logger.LogCommand(command, userID, username, i.ChannelID, false, "Restricted by penalties", parameters)
+		respondError(s, i, restriction)
+		return false
 	}
 
 	if overlappingReservation != nil {
@@ -186,37 +177,44 @@ func handleReserve(s *discordgo.Session, i *discordgo.InteractionCreate, store *
 			},
 			{
 				Name:   "🕐 時間",
-				Value:  fmt.Sprintf("%s - %s", overlappingReservation.StartTime, overlappingReservation.EndTime),
+				Value:  formatTimeRange(overlappingReservation),
 				Inline: true,
 			},
 		}
+		fields = appendResourceField(fields, overlappingReservation.ResourceID)
+
+		// 同じ長さで空いている近くの時間帯を提案する（コメントなどはボタンを押すまで保持する）
+		retry := req
+		retry.Room = resourceID
+		alternativesField, components := offerAlternatives(store, logger, actionReserveAlternative, retry, resourceID, "", slot)
+		fields = append(fields, alternativesField)
+
+		// 空くのを待ちたい場合は、同じ時間帯のキャンセル待ちに登録できる
+		waiting := retry
+		waiting.Date, waiting.StartTime, waiting.EndTime = slot.Date, slot.StartTime, slot.EndTime
+		components = append(components, waitlistJoinComponents(waiting)...)
+		description := "指定された時間は既に予約されています。"
+		if waitlist != nil {
+			description += "\nキャンセル待ちに登録すると、空いたときにDMでお知らせします。"
+		}
 
 		embed := &discordgo.MessageEmbed{
 			Title:       "🔴 予約できませんでした",
-			Description: "指定された時間は既に予約されています。",
+			Description: description,
 			Fields:      fields,
 			Color:       0xED4245, // Discord Red
 			Timestamp:   time.Now().Format(time.RFC3339),
 		}
 
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Type: resultResponseType(i),
 			Data: &discordgo.InteractionResponseData{
-				Embeds: []*discordgo.MessageEmbed{embed},
-				Flags:  discordgo.MessageFlagsEphemeral,
+				Embeds:     []*discordgo.MessageEmbed{embed},
+				Components: components,
+				Flags:      discordgo.MessageFlagsEphemeral,
 			},
 		})
-		return
-	}
-
-	// 予約を保存
-	if err := store.AddReservation(reservation); err != nil {
-		respondError(s, i, "予約の保存に失敗しました")
-		logger.LogError("ERROR", "handlers.handleReserve", "Failed to add reservation", err, map[string]interface{}{
-			"user_id":        userID,
-			"reservation_id": reservation.ID,
-		})
-		return
+		return false
 	}
 
 	if err := store.Save(); err != nil {
@@ -225,92 +223,26 @@ func handleReserve(s *discordgo.Session, i *discordgo.InteractionCreate, store *
 			"user_id":        userID,
 			"reservation_id": reservation.ID,
 		})
-		return
-	}
-
-	// 予約者にはIDを含めたメッセージを送信（Ephemeral）
-	fields := []*discordgo.MessageEmbedField{
-		{
-			Name:   "予約ID",
-			Value:  fmt.Sprintf("`%s`", reservation.ID),
-			Inline: false,
-		},
-		{
-			Name:   "📅 日付",
-			Value:  formatDate(reservation.Date),
-			Inline: true,
-		},
-		{
-			Name:   "🕐 時間",
-			Value:  fmt.Sprintf("%s - %s", reservation.StartTime, reservation.EndTime),
-			Inline: true,
-		},
-	}
-	if comment != "" {
-		fields = append(fields, &discordgo.MessageEmbedField{
-			Name:   "💬 コメント",
-			Value:  comment,
-			Inline: false,
-		})
-	}
-
-	embed := &discordgo.MessageEmbed{
-		Title:       "🟢 予約が完了しました！",
-		Description: "",
-		Fields:      fields,
-		Color:       0x57F287, // Discord Green
-		Timestamp:   time.Now().Format(time.RFC3339),
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "部室予約システム  |  reserve",
-		},
+		return false
 	}
 
+	// 予約者にはIDを含めたメッセージを操作ボタン付きで送信（Ephemeral）
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Type: resultResponseType(i),
 		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-			Flags:  discordgo.MessageFlagsEphemeral,
+			Embeds:     []*discordgo.MessageEmbed{reservationEmbed(reservation, "🟢 予約が完了しました！", 0x57F287, true)},
+			Components: reservationButtons(reservation),
+			Flags:      discordgo.MessageFlagsEphemeral,
 		},
 	})
 
 	// チャンネルの全員に予約情報を通知（予約IDは含めない）
-	publicEmbed := &discordgo.MessageEmbed{
-		Title: "🟢 新しい予約が追加されました",
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "👤 予約者",
-				Value:  fmt.Sprintf("<@%s>", reservation.UserID),
-				Inline: false,
-			},
-			{
-				Name:   "📅 日付",
-				Value:  formatDate(reservation.Date),
-				Inline: true,
-			},
-			{
-				Name:   "🕐 時間",
-				Value:  fmt.Sprintf("%s - %s", reservation.StartTime, reservation.EndTime),
-				Inline: true,
-			},
-		},
-		Color:     0x57F287, // Discord Green
-		Timestamp: time.Now().Format(time.RFC3339),
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "部室予約システム  |  reserve",
-		},
-	}
-	if comment != "" {
-		publicEmbed.Fields = append(publicEmbed.Fields, &discordgo.MessageEmbedField{
-			Name:   "💬 コメント",
-			Value:  comment,
-			Inline: false,
-		})
-	}
-	// DMから実行された場合も、指定チャンネルに通知
-	s.ChannelMessageSendEmbed(allowedChannelID, publicEmbed)
+	// DMから実行された場合も、指定チャンネル（部屋ごとの通知先があればそちら）に通知
+	sendReservationNotice(s, store, logger, notificationChannel(reservation.ResourceID, allowedChannelID), reservation)
 
 	// Botステータスを更新
 	if UpdateStatusCallback != nil {
 		UpdateStatusCallback()
 	}
+	return true
 }
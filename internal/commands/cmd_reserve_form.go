@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// defaultFormDuration は予約フォームの利用時間の初期値（分）
+const defaultFormDuration = "60"
+
+// reserveForm は予約フォームの入力値（未検証の値）
+// 確認や修正のボタンから使えるように pendingInputs に保持する
+type reserveForm struct {
+	Date      string
+	StartTime string
+	Duration  string
+	Room      string
+	Comment   string
+}
+
+// openReserveForm は入力値を入れた予約フォーム（モーダル）を表示する
+func openReserveForm(s *discordgo.Session, i *discordgo.InteractionCreate, form reserveForm) {
+	components := []discordgo.MessageComponent{
+		textInputRow("date", "予約日", form.Date, "YYYY/MM/DD（例: 2025/10/15）", discordgo.TextInputShort, true, 10),
+		textInputRow("start_time", "開始時間", form.StartTime, "HH:MM（例: 14:00）", discordgo.TextInputShort, true, 5),
+		textInputRow("duration", "利用時間", form.Duration, "例: 60、90分、1:30、1時間30分", discordgo.TextInputShort, true, 10),
+	}
+	// 部屋が1つだけの場合は入力欄を表示しない
+	if hasMultipleResources() {
+		room := form.Room
+		if room == "" {
+			room = resourceName(defaultResourceID())
+		}
+		components = append(components, textInputRow("room", "部屋", room, "部屋の名前", discordgo.TextInputShort, true, 100))
+	}
+	components = append(components, textInputRow("comment", "コメント（任意）", form.Comment, "", discordgo.TextInputParagraph, false, 200))
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID:   newCustomID(actionReserveForm),
+			Title:      "新規予約",
+			Components: components,
+		},
+	})
+}
+
+// validateReserveForm は予約フォームの入力を /reserve と同じルールで検証する
+// 入力欄ごとのエラーをまとめて返し、エラーがない場合は予約作成の入力と利用時間を返す
+func validateReserveForm(form reserveForm) (reserveRequest, time.Duration, []string) {
+	problems := []string{}
+
+	if _, inputErr := parseDateInput(form.Date); inputErr != nil {
+		problems = append(problems, "📅 予約日: "+inputErr.Message)
+	}
+	startTime, inputErr := parseClockInput(form.StartTime, "開始時間")
+	if inputErr != nil {
+		problems = append(problems, "🕐 "+inputErr.Message)
+	}
+	duration, inputErr := parseDurationInput(form.Duration)
+	if inputErr != nil {
+		problems = append(problems, "⏱️ 利用時間: "+inputErr.Message)
+	}
+	resourceID, inputErr := parseResourceOption(form.Room)
+	if inputErr != nil {
+		problems = append(problems, "🚪 部屋: "+inputErr.Message)
+	}
+	if len(problems) > 0 {
+		return reserveRequest{}, 0, problems
+	}
+
+	// 日時の組み合わせ（過去日時など）は /reserve と同じ手順で確認する
+	start, _ := time.Parse("15:04", startTime)
+	slot, inputErr := parseSlotInput(form.Date, startTime, start.Add(duration).Format("15:04"))
+	if inputErr != nil {
+		return reserveRequest{}, 0, []string{inputErr.Message}
+	}
+	return reserveRequest{
+		Date:      slot.Date,
+		StartTime: slot.StartTime,
+		EndTime:   slot.EndTime,
+		Room:      resourceID,
+		Comment:   form.Comment,
+	}, duration, nil
+}
+
+// formResponseType はフォームの確認画面の応答の種類を返す
+// 修正ボタンから開いたフォームの送信では、元の確認画面を置き換える
+func formResponseType(i *discordgo.InteractionCreate) discordgo.InteractionResponseType {
+	if i.Message != nil {
+		return discordgo.InteractionResponseUpdateMessage
+	}
+	return discordgo.InteractionResponseChannelMessageWithSource
+}
+
+// respondReserveFormReview は予約フォームの入力を検証し、エラーまたは確認画面を表示する
+func respondReserveFormReview(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, responseType discordgo.InteractionResponseType, token string, form reserveForm) {
+	fixButton := discordgo.Button{Label: "修正する", Style: discordgo.SecondaryButton, CustomID: newCustomID(actionReserveFormFix, token)}
+	discardButton := discordgo.Button{Label: "やめる", Style: discordgo.SecondaryButton, CustomID: newCustomID(actionReserveFormDiscard, token)}
+
+	var embed *discordgo.MessageEmbed
+	var buttons []discordgo.Button
+	req, duration, problems := validateReserveForm(form)
+	if len(problems) > 0 {
+		fixButton.Style = discordgo.PrimaryButton
+		lines := make([]string, 0, len(problems))
+		for _, message := range problems {
+			lines = append(lines, "• "+message)
+		}
+		embed = &discordgo.MessageEmbed{
+			Title:       "🔴 入力内容を確認してください",
+			Description: joinLines(lines),
+			Color:       0xED4245, // Discord Red
+			Timestamp:   time.Now().Format(time.RFC3339),
+		}
+		buttons = []discordgo.Button{fixButton, discardButton}
+	} else {
+		preview := &models.Reservation{
+			Date:       req.Date,
+			EndDate:    models.ResolveEndDate(req.Date, req.StartTime, req.EndTime),
+			StartTime:  req.StartTime,
+			EndTime:    req.EndTime,
+			Comment:    req.Comment,
+			Status:     models.StatusPending,
+			ResourceID: req.Room,
+		}
+		fields := []*discordgo.MessageEmbedField{
+			{
+				Name:   "📅 日付",
+				Value:  formatDateRange(preview),
+				Inline: true,
+			},
+			{
+				Name:   "🕐 時間",
+				Value:  fmt.Sprintf("%s（%s）", formatTimeRange(preview), formatDuration(duration)),
+				Inline: true,
+			},
+		}
+		fields = appendResourceField(fields, preview.ResourceID)
+		if preview.Comment != "" {
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:   "💬 コメント",
+				Value:  preview.Comment,
+				Inline: false,
+			})
+		}
+
+		// この時点の空き状況を表示する（確定時にもう一度確認する）
+		overlapping, err := store.CheckOverlap(preview)
+		if err != nil {
+			logger.LogError("ERROR", "respondReserveFormReview", "Failed to check overlap", err, map[string]interface{}{
+				"date": req.Date,
+			})
+		}
+		if overlapping != nil {
+			fields = append(fields, &discordgo.MessageEmbedField{
+				Name:   "⚠️ 重複している予約",
+				Value:  fmt.Sprintf("<@%s> %s %s", overlapping.UserID, formatDateRange(overlapping), formatTimeRange(overlapping)),
+				Inline: false,
+			})
+		}
+
+		embed = &discordgo.MessageEmbed{
+			Title:       "📝 予約内容の確認",
+			Description: "この内容で予約する場合は「予約する」を押してください。",
+			Fields:      fields,
+			Color:       0xFEE75C, // Discord Yellow
+			Timestamp:   time.Now().Format(time.RFC3339),
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: "部室予約システム  |  reserve",
+			},
+		}
+		buttons = []discordgo.Button{
+			{Label: "予約する", Style: discordgo.SuccessButton, CustomID: newCustomID(actionReserveFormConfirm, token)},
+			fixButton,
+			discardButton,
+		}
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: responseType,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: buttonRows(buttons),
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// pendingReserveForm はボタンの引数のトークンから予約フォームの入力値を取得する
+func pendingReserveForm(s *discordgo.Session, i *discordgo.InteractionCreate, args []string) (string, reserveForm, bool) {
+	if len(args) != 1 {
+		respondError(s, i, "この操作は利用できません。もう一度コマンドを実行してください。")
+		return "", reserveForm{}, false
+	}
+	value, found := pendingInputs.get(args[0])
+	form, ok := value.(reserveForm)
+	if !found || !ok {
+		respondError(s, i, "入力内容の有効期限が切れました。もう一度 /reserve を実行してください。")
+		return "", reserveForm{}, false
+	}
+	return args[0], form, true
+}
+
+// handleReserveFormModal は予約フォームの送信を受け取り、入力エラーまたは確認画面を表示する
+func handleReserveFormModal(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, values map[string]string) {
+	form := reserveForm{
+		Date:      values["date"],
+		StartTime: values["start_time"],
+		Duration:  values["duration"],
+		Room:      values["room"],
+		Comment:   values["comment"],
+	}
+	token, err := pendingInputs.put(form)
+	if err != nil {
+		respondError(s, i, "入力内容の保存に失敗しました。もう一度お試しください。")
+		logger.LogError("ERROR", "handleReserveFormModal", "Failed to keep form values", err, nil)
+		return
+	}
+	respondReserveFormReview(s, i, store, logger, formResponseType(i), token, form)
+}
+
+// handleReserveFormFixButton は「修正する」ボタンが押されたときに、前回の入力値を入れたフォームを表示する
+func handleReserveFormFixButton(s *discordgo.Session, i *discordgo.InteractionCreate, args []string) {
+	// フォームを閉じた場合も元の確認画面のボタンを使えるように、入力値は削除しない
+	_, form, ok := pendingReserveForm(s, i, args)
+	if !ok {
+		return
+	}
+	openReserveForm(s, i, form)
+}
+
+// handleReserveFormConfirmButton は「予約する」ボタンが押されたときに、/reserve と同じ手順で予約を作成する
+func handleReserveFormConfirmButton(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool, args []string) {
+	token, form, ok := pendingReserveForm(s, i, args)
+	if !ok {
+		return
+	}
+	// 確認画面を表示してから時間が経っている場合もあるため、もう一度検証する
+	req, _, problems := validateReserveForm(form)
+	if len(problems) > 0 {
+		respondReserveFormReview(s, i, store, logger, discordgo.InteractionResponseUpdateMessage, token, form)
+		return
+	}
+	if createReservation(s, i, store, logger, allowedChannelID, isDM, "reserve", req) {
+		pendingInputs.remove(token)
+	}
+}
+
+// handleReserveFormDiscardButton は「やめる」ボタンが押されたときに、予約せずに確認画面を閉じる
+func handleReserveFormDiscardButton(s *discordgo.Session, i *discordgo.InteractionCreate, args []string) {
+	if len(args) == 1 {
+		pendingInputs.remove(args[0])
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       "予約を作成しませんでした",
+				Description: "もう一度予約する場合は /reserve を実行してください。",
+				Color:       0x000000,
+				Timestamp:   time.Now().Format(time.RFC3339),
+			}},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestValidateReserveFormReportsEveryInvalidField(t *testing.T) {
+	_, _, problems := validateReserveForm(reserveForm{Date: "15/45", StartTime: "25時", Duration: "長め", Room: "存在しない部屋"})
+	if len(problems) != 4 {
+		t.Errorf("Expected an error for each of the 4 invalid fields, got %v", problems)
+	}
+}
+
+func TestValidateReserveForm(t *testing.T) {
+	day := models.Now().AddDate(0, 0, 3)
+	req, duration, problems := validateReserveForm(reserveForm{
+		Date:      day.Format("2006/1/2"),
+		StartTime: "23:30",
+		Duration:  "1時間30分",
+		Comment:   "面接",
+	})
+	if len(problems) > 0 {
+		t.Fatalf("Expected no errors, got %v", problems)
+	}
+	if req.Date != day.Format("2006-01-02") || req.StartTime != "23:30" || req.EndTime != "01:00" || req.Comment != "面接" {
+		t.Errorf("Unexpected request %+v", req)
+	}
+	if req.Room != defaultResourceID() {
+		t.Errorf("Expected the default room, got %q", req.Room)
+	}
+	if duration != 90*time.Minute {
+		t.Errorf("Expected 90 minutes, got %s", duration)
+	}
+
+	past := models.Now().AddDate(0, 0, -1)
+	if _, _, problems := validateReserveForm(reserveForm{Date: past.Format("2006/01/02"), StartTime: "10:00", Duration: "60"}); len(problems) != 1 {
+		t.Errorf("Expected the past date to be rejected, got %v", problems)
+	}
+}
@@ -0,0 +1,325 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// handleReserveRecurring は繰り返し予約の作成コマンドを処理する
+// 空いている日だけを予約し、重複した日は予約せずに一覧で報告する
+func handleReserveRecurring(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool) {
+	options := i.ApplicationCommandData().Options
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		optionMap[opt.Name] = opt
+	}
+
+	// ユーザー情報を取得
+	userID, username := getUserInfo(i, isDM)
+
+	// 必須パラメータを取得
+	date := optionMap["date"].StringValue()
+	startTime := optionMap["start_time"].StringValue()
+	frequency := models.RecurrenceFrequency(optionMap["frequency"].StringValue())
+
+	// オプションパラメータを取得
+	var endTime, until, room, comment string
+	if opt, ok := optionMap["end_time"]; ok {
+		endTime = opt.StringValue()
+	}
+	if opt, ok := optionMap["until"]; ok {
+		until = opt.StringValue()
+	}
+	if opt, ok := optionMap["room"]; ok {
+		room = opt.StringValue()
+	}
+	if opt, ok := optionMap["comment"]; ok {
+		comment = opt.StringValue()
+	}
+	count := 0
+	if opt, ok := optionMap["count"]; ok {
+		count = int(opt.IntValue())
+	}
+
+	// ログ用パラメータを構築
+	parameters := map[string]interface{}{
+		"date":       date,
+		"start_time": startTime,
+		"end_time":   endTime,
+		"frequency":  string(frequency),
+		"until":      until,
+		"count":      count,
+		"room":       room,
+	}
+	if comment != "" {
+		parameters["comment"] = comment
+	}
+
+	fail := func(inputErr *inputError) {
+		logger.LogCommand("reserve-recurring", userID, username, i.ChannelID, false, inputErr.Reason, parameters)
+		respondError(s, i, inputErr.Message)
+	}
+
+	resourceID, inputErr := parseResourceOption(room)
+	if inputErr != nil {
+		fail(inputErr)
+		return
+	}
+
+	// 初回の日時を検証・正規化
+	slot, inputErr := parseSlotInput(date, startTime, endTime)
+	if inputErr != nil {
+		fail(inputErr)
+		return
+	}
+
+	// 繰り返しルールを検証
+	rule := &models.Recurrence{Frequency: frequency, Count: count}
+	if until != "" {
+		untilDate, inputErr := parseDateInput(until)
+		if inputErr != nil {
+			fail(inputErr)
+			return
+		}
+		rule.Until = untilDate.Format("2006-01-02")
+	}
+	if rule.Until == "" && rule.Count == 0 {
+		fail(newInputError("終了日（until）または回数（count）のどちらかを指定してください。"))
+		return
+	}
+	if rule.Until != "" && rule.Until < slot.Date {
+		fail(newInputError("終了日は初回の予約日以降の日付を指定してください。"))
+		return
+	}
+
+	dates, err := rule.Occurrences(slot.Date)
+	if err != nil {
+		fail(newInputError(fmt.Sprintf("繰り返しの指定が正しくありません（回数は1〜%d回で指定してください）", models.MaxOccurrences)))
+		return
+	}
+
+	// 違反が続いて予約が制限されているユーザーは、制限内の件数まで予約できる
+	// 制限の確認から各回の保存までを同じユーザーの他の予約と直列にする（同時の予約で上限を超えないように）
+	unlock := lockReservations(userID)
+	defer unlock()
+	restriction, err := reserveRestriction(store, userID, models.Now(), len(dates))
+	if err != nil {
+		respondError(s, i, "予約の取得に失敗しました")
+		logger.LogError("ERROR", "handlers.handleReserveRecurring", "Failed to check restriction", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return
+	}
+	if restriction != "" {
+		fail(newInputError(restriction))
+		return
+	}
+
+	seriesID, err := models.GenerateReservationID()
+	if err != nil {
+		respondError(s, i, "予約IDの生成に失敗しました")
+		return
+	}
+
+	// 各回を順番に予約（重複した回は飛ばして報告する）
+	var booked []*models.Reservation
+	var conflictLines []string
+	for _, occurrenceDate := range dates {
+		reservationID, err := models.GenerateReservationID()
+		if err != nil {
+			respondError(s, i, "予約IDの生成に失敗しました")
+			return
+		}
+
+		now := time.Now()
+		reservation := &models.Reservation{
+			ID:         reservationID,
+			UserID:     userID,
+			Username:   username,
+			Date:       occurrenceDate,
+			EndDate:    models.ResolveEndDate(occurrenceDate, slot.StartTime, slot.EndTime),
+			StartTime:  slot.StartTime,
+			EndTime:    slot.EndTime,
+			Comment:    comment,
+			Status:     models.StatusPending,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+			ChannelID:  allowedChannelID, // 公開メッセージの送信先は常に指定チャンネル
+			ResourceID: resourceID,
+			SeriesID:   seriesID,
+			Recurrence: rule,
+		}
+		reservation.RecordHistory(nil, newHistoryEntry(actor{UserID: userID, Username: username}, "reserve-recurring", models.HistoryCreated))
+
+		overlappingReservation, err := store.ReserveIfFree(reservation)
+		if err != nil {
+			logger.LogError("ERROR", "handlers.handleReserveRecurring", "Failed to reserve occurrence", err, map[string]interface{}{
+				"user_id":   userID,
+				"series_id": seriesID,
+				"date":      occurrenceDate,
+			})
+			conflictLines = append(conflictLines, fmt.Sprintf("%s（保存に失敗しました）", formatDate(occurrenceDate)))
+			continue
+		}
+		if overlappingReservation != nil {
+			conflictLines = append(conflictLines, fmt.Sprintf("%s（<@%s> %s）",
+				formatDate(occurrenceDate), overlappingReservation.UserID, formatTimeRange(overlappingReservation)))
+			continue
+		}
+		booked = append(booked, reservation)
+	}
+
+	if len(booked) == 0 {
+		fields := []*discordgo.MessageEmbedField{
+			{
+				Name:   "📅 重複している日",
+				Value:  joinLines(conflictLines),
+				Inline: false,
+			},
+		}
+		fields = appendResourceField(fields, resourceID)
+
+		embed := &discordgo.MessageEmbed{
+			Title:       "🔴 予約できませんでした",
+			Description: "指定されたすべての日で、時間が既に予約されています。",
+			Fields:      fields,
+			Color:       0xED4245, // Discord Red
+			Timestamp:   time.Now().Format(time.RFC3339),
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: "部室予約システム  |  reserve-recurring",
+			},
+		}
+
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{embed},
+				Flags:  discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if err := store.Save(); err != nil {
+		respondError(s, i, "予約の保存に失敗しました")
+		logger.LogError("ERROR", "handlers.handleReserveRecurring", "Failed to save reservations", err, map[string]interface{}{
+			"user_id":   userID,
+			"series_id": seriesID,
+		})
+		return
+	}
+
+	bookedLines := make([]string, len(booked))
+	for idx, reservation := range booked {
+		bookedLines[idx] = fmt.Sprintf("%s `%s`", formatDate(reservation.Date), reservation.ID)
+	}
+
+	// 予約者にはIDを含めたメッセージを送信（Ephemeral）
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "🔁 繰り返し",
+			Value:  formatRecurrence(rule),
+			Inline: true,
+		},
+		{
+			Name:   "🕐 時間",
+			Value:  formatTimeRange(booked[0]),
+			Inline: true,
+		},
+	}
+	fields = appendResourceField(fields, resourceID)
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:   fmt.Sprintf("📅 予約した日（%d件）", len(booked)),
+		Value:  joinLines(bookedLines),
+		Inline: false,
+	})
+	if len(conflictLines) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("⚠️ 重複のため予約できなかった日（%d件）", len(conflictLines)),
+			Value:  joinLines(conflictLines),
+			Inline: false,
+		})
+	}
+	if comment != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "💬 コメント",
+			Value:  comment,
+			Inline: false,
+		})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "🟢 繰り返し予約が完了しました！",
+		Description: "個別の予約は `/edit`・`/cancel` の scope で、この予約以降やシリーズ全体をまとめて変更できます。",
+		Fields:      fields,
+		Color:       0x57F287, // Discord Green
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "部室予約システム  |  reserve-recurring",
+		},
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	// チャンネルの全員に予約情報を通知（予約IDは含めない）
+	dateLines := make([]string, len(booked))
+	for idx, reservation := range booked {
+		dateLines[idx] = formatDate(reservation.Date)
+	}
+	publicEmbed := &discordgo.MessageEmbed{
+		Title: "🟢 新しい繰り返し予約が追加されました",
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "👤 予約者",
+				Value:  fmt.Sprintf("<@%s>", userID),
+				Inline: false,
+			},
+			{
+				Name:   "🔁 繰り返し",
+				Value:  formatRecurrence(rule),
+				Inline: true,
+			},
+			{
+				Name:   "🕐 時間",
+				Value:  formatTimeRange(booked[0]),
+				Inline: true,
+			},
+		},
+		Color:     0x57F287, // Discord Green
+		Timestamp: time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "部室予約システム  |  reserve-recurring",
+		},
+	}
+	publicEmbed.Fields = appendResourceField(publicEmbed.Fields, resourceID)
+	publicEmbed.Fields = append(publicEmbed.Fields, &discordgo.MessageEmbedField{
+		Name:   fmt.Sprintf("📅 日程（%d件）", len(booked)),
+		Value:  joinLines(dateLines),
+		Inline: false,
+	})
+	if comment != "" {
+		publicEmbed.Fields = append(publicEmbed.Fields, &discordgo.MessageEmbedField{
+			Name:   "💬 コメント",
+			Value:  comment,
+			Inline: false,
+		})
+	}
+	// DMから実行された場合も、指定チャンネル（部屋ごとの通知先があればそちら）に通知
+	s.ChannelMessageSendEmbed(notificationChannel(resourceID, allowedChannelID), publicEmbed)
+
+	// Botステータスを更新
+	if UpdateStatusCallback != nil {
+		UpdateStatusCallback()
+	}
+}
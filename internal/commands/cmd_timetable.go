@@ -0,0 +1,332 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// timetableStep は時間割の1行の長さ
+const timetableStep = 30 * time.Minute
+
+// 時間割のセル（コードブロック内で幅がずれないように ASCII だけを使う）
+const (
+	timetableFree  = "  ."
+	timetableOther = " ##"
+	timetableOwn   = " @@"
+)
+
+// timetableLegend は時間割の記号の説明
+const timetableLegend = "`@@` 自分の予約　`##` 他の予約　`.` 空き　`*` 今日　`>` 現在時刻"
+
+// weekdayLabels は時間割のヘッダーに使う曜日（time.Weekday の順）
+var weekdayLabels = [...]string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"}
+
+// timetableColumn は時間割の1列（1日分、または1つの部屋の1日分）
+type timetableColumn struct {
+	Label      string // 1行目のヘッダー（2文字まで）
+	Sub        string // 2行目のヘッダー（2文字まで）
+	Date       string // 日付（YYYY-MM-DD形式）
+	ResourceID string // 部屋ID（空の場合はすべての部屋の予約を表示する）
+}
+
+// handleToday は今日の予約を部屋ごとの時間割で表示する
+func handleToday(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool) {
+	userID, _ := getUserInfo(i, isDM)
+	today := models.Today()
+
+	reservations, err := store.QueryReservations(storage.Query{
+		Statuses: models.ActiveStatuses,
+		DateFrom: today,
+		DateTo:   today,
+	})
+	if err != nil {
+		respondError(s, i, "予約の取得に失敗しました")
+		logger.LogError("ERROR", "handleToday", "Failed to query reservations", err, nil)
+		return
+	}
+
+	columns := []timetableColumn{}
+	description := formatDate(today)
+	if hasMultipleResources() {
+		names := []string{}
+		for idx, resource := range resources {
+			label := fmt.Sprintf("%d", idx+1)
+			columns = append(columns, timetableColumn{Label: label, Date: today, ResourceID: resource.ID})
+			names = append(names, fmt.Sprintf("%s: %s", label, resource.Name))
+		}
+		description += "\n" + strings.Join(names, "　")
+	} else {
+		day, _ := time.Parse("2006-01-02", today)
+		columns = append(columns, timetableColumn{Label: weekdayLabels[day.Weekday()], Sub: day.Format("02"), Date: today})
+	}
+
+	lines := []string{}
+	sortByStart(reservations)
+	for _, r := range reservations {
+		line := fmt.Sprintf("• %s <@%s>", formatTimeRange(r), r.UserID)
+		if hasMultipleResources() {
+			line += "　🚪 " + resourceName(r.ResourceID)
+		}
+		lines = append(lines, line)
+	}
+	value := "今日の予約はありません"
+	if len(lines) > 0 {
+		value = joinLines(lines)
+	}
+
+	respondTimetable(s, i, "today", "🗓️ 今日の時間割", description, columns, reservations, userID, &discordgo.MessageEmbedField{
+		Name:   fmt.Sprintf("📋 今日の予約（%d件）", len(reservations)),
+		Value:  value,
+		Inline: false,
+	})
+}
+
+// handleWeek は指定した日を含む週（月曜〜日曜）の予約を日ごとの時間割で表示する
+func handleWeek(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool) {
+	userID, _ := getUserInfo(i, isDM)
+	day, resourceID, inputErr := parseWeekOptions(i.ApplicationCommandData().Options)
+	if inputErr != nil {
+		respondError(s, i, inputErr.Message)
+		return
+	}
+
+	columns := weekColumns(day, resourceID)
+	from, to := columns[0].Date, columns[len(columns)-1].Date
+	reservations, err := store.QueryReservations(storage.Query{
+		ResourceID: resourceID,
+		Statuses:   models.ActiveStatuses,
+		DateFrom:   from,
+		DateTo:     to,
+	})
+	if err != nil {
+		respondError(s, i, "予約の取得に失敗しました")
+		logger.LogError("ERROR", "handleWeek", "Failed to query reservations", err, map[string]interface{}{
+			"from": from,
+			"to":   to,
+		})
+		return
+	}
+
+	description := fmt.Sprintf("%s 〜 %s", formatDate(from), formatDate(to))
+	if resourceID != "" {
+		description += "　🚪 " + resourceName(resourceID)
+	} else if hasMultipleResources() {
+		description += "　🚪 すべての部屋"
+	}
+
+	own := []string{}
+	sortByStart(reservations)
+	for _, r := range reservations {
+		if r.UserID == userID {
+			own = append(own, "• "+formatOccurrence(r))
+		}
+	}
+	value := "この週の予約はありません"
+	if len(own) > 0 {
+		value = joinLines(own)
+	}
+
+	respondTimetable(s, i, "week", "🗓️ 週間時間割", description, columns, reservations, userID, &discordgo.MessageEmbedField{
+		Name:   fmt.Sprintf("📅 自分の予約（%d件）", len(own)),
+		Value:  value,
+		Inline: false,
+	})
+}
+
+// parseWeekOptions は /week と /calendar の表示する週（date）と部屋（room）のオプションを解釈する
+// date を省略した場合は今週、room を省略した場合はすべての部屋を表示する
+func parseWeekOptions(options []*discordgo.ApplicationCommandInteractionDataOption) (time.Time, string, *inputError) {
+	day, _ := time.Parse("2006-01-02", models.Today())
+	resourceID := ""
+	for _, opt := range options {
+		switch opt.Name {
+		case "date":
+			parsed, inputErr := parseDateInput(opt.StringValue())
+			if inputErr != nil {
+				return time.Time{}, "", inputErr
+			}
+			day = parsed
+		case "room":
+			resource, found := findResource(opt.StringValue())
+			if !found {
+				return time.Time{}, "", newInputError("指定された部屋が見つかりません。候補から選択してください。")
+			}
+			resourceID = resource.ID
+		}
+	}
+	return day, resourceID, nil
+}
+
+// weekColumns は day を含む週（月曜〜日曜）の7日分の列を返す
+func weekColumns(day time.Time, resourceID string) []timetableColumn {
+	monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	columns := make([]timetableColumn, 0, 7)
+	for offset := 0; offset < 7; offset++ {
+		date := monday.AddDate(0, 0, offset)
+		columns = append(columns, timetableColumn{
+			Label:      weekdayLabels[date.Weekday()],
+			Sub:        date.Format("02"),
+			Date:       date.Format("2006-01-02"),
+			ResourceID: resourceID,
+		})
+	}
+	return columns
+}
+
+// respondTimetable は時間割と補足のフィールドを表示する
+func respondTimetable(s *discordgo.Session, i *discordgo.InteractionCreate, command, title, description string, columns []timetableColumn, reservations []*models.Reservation, userID string, field *discordgo.MessageEmbedField) {
+	grid := renderTimetable(columns, reservations, userID, models.Now())
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: fmt.Sprintf("%s\n```\n%s```\n%s", description, grid, timetableLegend),
+		Fields:      []*discordgo.MessageEmbedField{field},
+		Color:       0x5865F2,
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "部室予約システム  |  " + command,
+		},
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// renderTimetable は時間を縦、列を横にした時間割をテキストで作成する
+func renderTimetable(columns []timetableColumn, reservations []*models.Reservation, userID string, now time.Time) string {
+	busy := reservationSlots(reservations)
+	dayStarts, first, last := timetableSpan(columns, reservations, busy)
+
+	var builder strings.Builder
+	header, sub := "      ", "      "
+	for _, column := range columns {
+		marker := " "
+		if column.Date == now.Format("2006-01-02") {
+			marker = "*"
+		}
+		header += fmt.Sprintf("%s%2s", marker, column.Label)
+		sub += fmt.Sprintf(" %2s", column.Sub)
+	}
+	builder.WriteString(strings.TrimRight(header, " ") + "\n")
+	if strings.TrimSpace(sub) != "" {
+		builder.WriteString(strings.TrimRight(sub, " ") + "\n")
+	}
+
+	for offset := first; offset < last; offset += timetableStep {
+		marker := " "
+		row := strings.Builder{}
+		for col, column := range columns {
+			slot := models.TimeSlot{Start: dayStarts[col].Add(offset), End: dayStarts[col].Add(offset + timetableStep)}
+			if !now.Before(slot.Start) && now.Before(slot.End) {
+				marker = ">"
+			}
+			cell := timetableFree
+			for idx, reserved := range busy {
+				if !columnMatches(column, reservations[idx]) || !reserved.Start.Before(slot.End) || !reserved.End.After(slot.Start) {
+					continue
+				}
+				if reservations[idx].UserID == userID {
+					cell = timetableOwn
+					break
+				}
+				cell = timetableOther
+			}
+			row.WriteString(cell)
+		}
+		builder.WriteString(fmt.Sprintf("%02d:%02d%s%s\n", int(offset.Hours()), int(offset.Minutes())%60, marker, row.String()))
+	}
+	return builder.String()
+}
+
+// reservationSlots は予約ごとの時間帯を返す（日時が不正な予約はどの時間とも重ならない）
+func reservationSlots(reservations []*models.Reservation) []models.TimeSlot {
+	busy := make([]models.TimeSlot, len(reservations))
+	for idx, r := range reservations {
+		start, errStart := r.GetStartDateTime()
+		end, errEnd := r.GetEndDateTime()
+		if errStart == nil && errEnd == nil {
+			busy[idx] = models.TimeSlot{Start: start, End: end}
+		}
+	}
+	return busy
+}
+
+// timetableSpan は列ごとの0時と、すべての列で共通の表示範囲（0時からの長さ、timetableStep 単位）を求める
+// 表示する時間は利用可能時間を基本とし、その外にかかる予約があればその時間まで広げる
+func timetableSpan(columns []timetableColumn, reservations []*models.Reservation, busy []models.TimeSlot) ([]time.Time, time.Duration, time.Duration) {
+	dayStarts := make([]time.Time, len(columns))
+	first, last := 24*time.Hour, time.Duration(0)
+	for col, column := range columns {
+		window, err := openingHours.Window(column.Date)
+		if err != nil {
+			continue
+		}
+		dayStart, _ := time.ParseInLocation("2006-01-02", column.Date, models.Location())
+		dayStarts[col] = dayStart
+		dayEnd := dayStart.AddDate(0, 0, 1)
+		first, last = shorterDuration(first, window.Start.Sub(dayStart)), longerDuration(last, window.End.Sub(dayStart))
+		for idx, slot := range busy {
+			if !columnMatches(column, reservations[idx]) || !slot.Start.Before(dayEnd) || !slot.End.After(dayStart) {
+				continue
+			}
+			if slot.Start.After(dayStart) {
+				first = shorterDuration(first, slot.Start.Sub(dayStart))
+			} else {
+				first = 0
+			}
+			if slot.End.Before(dayEnd) {
+				last = longerDuration(last, slot.End.Sub(dayStart))
+			} else {
+				last = 24 * time.Hour
+			}
+		}
+	}
+	first = first.Truncate(timetableStep)
+	if rest := last % timetableStep; rest != 0 {
+		last += timetableStep - rest
+	}
+	return dayStarts, first, last
+}
+
+// columnMatches は予約が時間割の列の部屋に含まれるかを返す
+func columnMatches(column timetableColumn, r *models.Reservation) bool {
+	return column.ResourceID == "" || r.GetResourceID() == column.ResourceID
+}
+
+// sortByStart は予約を開始日時の順に並べる
+func sortByStart(reservations []*models.Reservation) {
+	sort.SliceStable(reservations, func(a, b int) bool {
+		tA, errA := reservations[a].GetStartDateTime()
+		tB, errB := reservations[b].GetStartDateTime()
+		if errA != nil || errB != nil {
+			return false
+		}
+		return tA.Before(tB)
+	})
+}
+
+// shorterDuration は短い方の長さを返す
+func shorterDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// longerDuration は長い方の長さを返す
+func longerDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestRenderTimetable(t *testing.T) {
+	old := openingHours
+	SetOpeningHours(models.OpeningHours{Open: "09:00", Close: "12:00"})
+	t.Cleanup(func() { SetOpeningHours(old) })
+
+	columns := []timetableColumn{
+		{Label: "Mo", Sub: "13", Date: "2030-05-13"},
+		{Label: "Tu", Sub: "14", Date: "2030-05-14"},
+	}
+	reservations := []*models.Reservation{
+		{UserID: "me", Date: "2030-05-13", EndDate: "2030-05-13", StartTime: "09:30", EndTime: "10:30", Status: models.StatusPending},
+		{UserID: "other", Date: "2030-05-14", EndDate: "2030-05-14", StartTime: "11:00", EndTime: "11:30", Status: models.StatusPending},
+		// 月曜の夜から火曜の朝まで（利用可能時間の外）
+		{UserID: "other", Date: "2030-05-13", EndDate: "2030-05-14", StartTime: "23:00", EndTime: "01:00", Status: models.StatusPending},
+	}
+	now := time.Date(2030, 5, 14, 11, 10, 0, 0, models.Location())
+
+	lines := strings.Split(strings.TrimRight(renderTimetable(columns, reservations, "me", now), "\n"), "\n")
+	if lines[0] != "       Mo*Tu" || lines[1] != "       13 14" {
+		t.Fatalf("Unexpected header:\n%s\n%s", lines[0], lines[1])
+	}
+
+	rows := map[string]string{}
+	for _, line := range lines[2:] {
+		rows[line[:5]] = line[5:]
+	}
+	// 日を跨ぐ予約があるため、表示範囲は 00:00-24:00 に広がる
+	if len(rows) != 48 {
+		t.Fatalf("Expected 48 rows, got %d", len(rows))
+	}
+	expected := map[string]string{
+		"00:00": "   . ##",
+		"01:00": "   .  .",
+		"09:00": "   .  .",
+		"09:30": "  @@  .",
+		"10:00": "  @@  .",
+		"10:30": "   .  .",
+		"11:00": ">  . ##",
+		"23:00": "  ##  .",
+	}
+	for label, row := range expected {
+		if rows[label] != row {
+			t.Errorf("Row %s: expected %q, got %q", label, row, rows[label])
+		}
+	}
+}
+
+func TestRenderTimetableOpeningHours(t *testing.T) {
+	old := openingHours
+	SetOpeningHours(models.OpeningHours{Open: "09:00", Close: "12:00"})
+	t.Cleanup(func() { SetOpeningHours(old) })
+
+	columns := []timetableColumn{{Label: "1", Date: "2030-05-13", ResourceID: "main"}}
+	reservations := []*models.Reservation{
+		{UserID: "other", Date: "2030-05-13", EndDate: "2030-05-13", StartTime: "10:00", EndTime: "11:00", Status: models.StatusPending, ResourceID: "sub"},
+	}
+	grid := renderTimetable(columns, reservations, "me", time.Date(2030, 5, 1, 0, 0, 0, 0, models.Location()))
+
+	lines := strings.Split(strings.TrimRight(grid, "\n"), "\n")
+	if len(lines) != 7 || !strings.HasPrefix(lines[1], "09:00") || !strings.HasPrefix(lines[6], "11:30") {
+		t.Fatalf("Expected the opening hours only:\n%s", grid)
+	}
+	if strings.Contains(grid, "#") {
+		t.Errorf("Reservations in other rooms should not be shown:\n%s", grid)
+	}
+}
+
+func TestWeekColumns(t *testing.T) {
+	tests := []struct {
+		day  string
+		from string
+	}{
+		{"2030-05-13", "2030-05-13"}, // 月曜
+		{"2030-05-16", "2030-05-13"}, // 木曜
+		{"2030-05-19", "2030-05-13"}, // 日曜
+	}
+	for _, tt := range tests {
+		day, _ := time.Parse("2006-01-02", tt.day)
+		columns := weekColumns(day, "main")
+		if len(columns) != 7 || columns[0].Date != tt.from || columns[0].Label != "Mo" || columns[6].Label != "Su" {
+			t.Errorf("weekColumns(%s) = %+v", tt.day, columns)
+		}
+		if columns[3].ResourceID != "main" {
+			t.Errorf("Expected the room to be kept, got %q", columns[3].ResourceID)
+		}
+	}
+}
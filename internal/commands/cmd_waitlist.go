@@ -0,0 +1,603 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// DefaultWaitlistOfferFor は空いた時間帯の案内に応答できる時間の既定値
+const DefaultWaitlistOfferFor = 15 * time.Minute
+
+// maxWaitlistEntries は1人が同時に登録できるキャンセル待ちの件数
+const maxWaitlistEntries = 5
+
+// waitlistOfferFor は空いた時間帯の案内に応答できる時間（過ぎると次の人に案内する）
+var waitlistOfferFor = DefaultWaitlistOfferFor
+
+// waitlist はキャンセル待ちの保存先（nil の場合はキャンセル待ちを受け付けない）
+var waitlist *storage.Waitlist
+
+// waitlistMu はキャンセル待ちの登録・案内の記録・応答を直列にする（DMの送信中は保持しない）
+var waitlistMu sync.Mutex
+
+// waitlistProcessMu は案内の送信を直列にする（DMを送っている間に同じ時間帯を別の人に案内しないため）
+var waitlistProcessMu sync.Mutex
+
+// SetWaitlistOfferFor は空いた時間帯の案内に応答できる時間を設定する
+func SetWaitlistOfferFor(d time.Duration) {
+	waitlistOfferFor = d
+}
+
+// SetWaitlist はキャンセル待ちの保存先を設定する
+func SetWaitlist(w *storage.Waitlist) {
+	waitlist = w
+}
+
+// handleWaitlist は埋まっている時間帯のキャンセル待ちを登録する（日付と開始時間を省略した場合は登録中の一覧を表示する）
+func handleWaitlist(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool) {
+	if waitlist == nil {
+		respondError(s, i, "キャンセル待ちは現在利用できません。")
+		return
+	}
+
+	req := reserveRequest{}
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "date":
+			req.Date = opt.StringValue()
+		case "start_time":
+			req.StartTime = opt.StringValue()
+		case "end_time":
+			req.EndTime = opt.StringValue()
+		case "room":
+			req.Room = opt.StringValue()
+		case "comment":
+			req.Comment = opt.StringValue()
+		}
+	}
+
+	// 日付に時刻も含まれている場合（例: "金曜 15時"）は開始時間として使う
+	req.Date, req.StartTime = splitDateTimeInput(req.Date, req.StartTime)
+	if req.Date == "" && req.StartTime == "" {
+		userID, _ := getUserInfo(i, isDM)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: waitlistOverview(userID, waitlist.Entries(), models.Now()),
+		})
+		return
+	}
+	if req.Date == "" || req.StartTime == "" {
+		respondError(s, i, "日付と開始時間を両方指定してください（両方省略すると登録中のキャンセル待ちを表示します）。")
+		return
+	}
+
+	joinWaitlist(s, i, store, logger, isDM, req)
+}
+
+// joinWaitlist は入力を検証してキャンセル待ちに登録し、登録者に順番を知らせる
+// 指定した時間帯が空いている場合は登録せず、そのまま予約するよう案内する
+func joinWaitlist(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool, req reserveRequest) {
+	userID, username := getUserInfo(i, isDM)
+
+	resourceID, inputErr := parseResourceOption(req.Room)
+	if inputErr != nil {
+		respondError(s, i, inputErr.Message)
+		return
+	}
+	slot, inputErr := parseSlotInput(req.Date, req.StartTime, req.EndTime)
+	if inputErr != nil {
+		respondError(s, i, inputErr.Message)
+		return
+	}
+
+	id, err := models.GenerateReservationID()
+	if err != nil {
+		respondError(s, i, "キャンセル待ちIDの生成に失敗しました")
+		return
+	}
+	entry := &models.WaitlistEntry{
+		ID:         id,
+		UserID:     userID,
+		Username:   username,
+		ResourceID: resourceID,
+		Date:       slot.Date,
+		EndDate:    slot.EndDate,
+		StartTime:  slot.StartTime,
+		EndTime:    slot.EndTime,
+		Comment:    req.Comment,
+		CreatedAt:  time.Now(),
+	}
+
+	// 空き状況の確認と登録の間に案内が送られないようにする
+	waitlistMu.Lock()
+	defer waitlistMu.Unlock()
+
+	blocking, err := store.CheckOverlap(entry.Slot())
+	if err != nil {
+		respondError(s, i, "予約の取得に失敗しました")
+		logger.LogError("ERROR", "joinWaitlist", "Failed to check overlap", err, map[string]interface{}{
+			"user_id": userID,
+			"date":    slot.Date,
+		})
+		return
+	}
+	if blocking == nil {
+		respondError(s, i, "指定された時間は空いています。`/reserve` でそのまま予約してください。")
+		return
+	}
+	if blocking.UserID == userID {
+		respondError(s, i, "指定された時間は、あなたの予約と重なっています。")
+		return
+	}
+
+	entries := waitlist.Entries()
+	if message := waitlistJoinError(entries, entry); message != "" {
+		respondError(s, i, message)
+		return
+	}
+	if err := waitlist.Add(entry); err != nil {
+		respondError(s, i, "キャンセル待ちの保存に失敗しました")
+		logger.LogError("ERROR", "joinWaitlist", "Failed to save waitlist", err, map[string]interface{}{
+			"user_id": userID,
+			"date":    slot.Date,
+		})
+		return
+	}
+
+	embed := waitlistEmbed(entry, "🎟️ キャンセル待ちに登録しました", 0x5865F2) // Discord Blue
+	embed.Description = fmt.Sprintf("現在 **%d 番目**です。予約が取り消されるなどして空いたら、登録の順にDMでお知らせします。\n"+
+		"お知らせから %s 以内に「予約する」を押すと予約できます。",
+		waitlistPosition(append(entries, entry), entry), formatDuration(waitlistOfferFor))
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: resultResponseType(i),
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: buttonRows([]discordgo.Button{waitlistLeaveButton(entry, "キャンセル待ちをやめる")}),
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// waitlistJoinError はキャンセル待ちに登録できない理由を返す（登録できる場合は空文字）
+func waitlistJoinError(entries []*models.WaitlistEntry, entry *models.WaitlistEntry) string {
+	count := 0
+	for _, e := range entries {
+		if e.UserID != entry.UserID {
+			continue
+		}
+		if e.Slot().GetResourceID() == entry.Slot().GetResourceID() && e.Date == entry.Date && e.StartTime == entry.StartTime && e.EndTime == entry.EndTime {
+			return "同じ時間帯のキャンセル待ちに登録済みです。"
+		}
+		count++
+	}
+	if count >= maxWaitlistEntries {
+		return fmt.Sprintf("キャンセル待ちは1人 %d 件まで登録できます。`/waitlist` で不要なものを取り消してください。", maxWaitlistEntries)
+	}
+	return ""
+}
+
+// waitlistPosition はキャンセル待ちの順番（時間帯の重なる登録のうち何番目か）を返す
+func waitlistPosition(entries []*models.WaitlistEntry, entry *models.WaitlistEntry) int {
+	position := 1
+	for _, e := range entries {
+		if e.ID != entry.ID && e.CreatedAt.Before(entry.CreatedAt) && e.Overlaps(entry) {
+			position++
+		}
+	}
+	return position
+}
+
+// waitlistEmbed はキャンセル待ちの時間帯を表す埋め込みメッセージを作成する
+func waitlistEmbed(e *models.WaitlistEntry, title string, color int) *discordgo.MessageEmbed {
+	slot := e.Slot()
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "📅 日付",
+			Value:  formatDateRange(slot),
+			Inline: true,
+		},
+		{
+			Name:   "🕐 時間",
+			Value:  formatTimeRange(slot),
+			Inline: true,
+		},
+	}
+	fields = appendResourceField(fields, e.ResourceID)
+	if e.Comment != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "💬 コメント",
+			Value:  e.Comment,
+			Inline: false,
+		})
+	}
+	return &discordgo.MessageEmbed{
+		Title:     title,
+		Fields:    fields,
+		Color:     color,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "部室予約システム  |  waitlist",
+		},
+	}
+}
+
+// waitlistLeaveButton はキャンセル待ちを取り消すボタンを返す
+func waitlistLeaveButton(e *models.WaitlistEntry, label string) discordgo.Button {
+	return discordgo.Button{Label: label, Style: discordgo.SecondaryButton, CustomID: newCustomID(actionWaitlistLeave, e.ID)}
+}
+
+// waitlistOverview はユーザーが登録中のキャンセル待ちの一覧と、取り消しボタンを作成する
+func waitlistOverview(userID string, entries []*models.WaitlistEntry, now time.Time) *discordgo.InteractionResponseData {
+	lines := []string{}
+	buttons := []discordgo.Button{}
+	for _, e := range entries {
+		if e.UserID != userID {
+			continue
+		}
+		slot := e.Slot()
+		line := fmt.Sprintf("%d. %s %s", len(lines)+1, formatDateRange(slot), formatTimeRange(slot))
+		if hasMultipleResources() {
+			line += "  " + resourceName(e.ResourceID)
+		}
+		if e.Offered(now) {
+			line += fmt.Sprintf("  🔔 %s まで予約できます（DMを確認してください）", e.OfferExpiresAt.In(models.Location()).Format("15:04"))
+		} else {
+			line += fmt.Sprintf("  %d 番目", waitlistPosition(entries, e))
+		}
+		lines = append(lines, line)
+		buttons = append(buttons, waitlistLeaveButton(e, fmt.Sprintf("%d. を取り消す", len(lines))))
+	}
+
+	description := "登録中のキャンセル待ちはありません。\n`/waitlist date:... start_time:...` で埋まっている時間帯に登録できます。"
+	if len(lines) > 0 {
+		description = joinLines(lines) + "\n\n空いたら登録の順にDMでお知らせします。"
+	}
+	return &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{{
+			Title:       "🎟️ キャンセル待ち",
+			Description: description,
+			Color:       0x5865F2, // Discord Blue
+			Timestamp:   time.Now().Format(time.RFC3339),
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: "部室予約システム  |  waitlist",
+			},
+		}},
+		Components: buttonRows(buttons),
+		Flags:      discordgo.MessageFlagsEphemeral,
+	}
+}
+
+// waitlistJoinComponents は予約の重複時にキャンセル待ちに登録するボタンを返す（受け付けない場合は空）
+func waitlistJoinComponents(req reserveRequest) []discordgo.MessageComponent {
+	if waitlist == nil {
+		return []discordgo.MessageComponent{}
+	}
+	token, err := pendingInputs.put(pendingWaitlist{Request: req})
+	if err != nil {
+		return []discordgo.MessageComponent{}
+	}
+	return buttonRows([]discordgo.Button{
+		{Label: "キャンセル待ちに登録", Style: discordgo.PrimaryButton, CustomID: newCustomID(actionWaitlistJoin, token)},
+	})
+}
+
+// pendingWaitlist は重複時のキャンセル待ちボタンから使う予約の入力
+type pendingWaitlist struct {
+	Request reserveRequest
+}
+
+// handleWaitlistJoinButton は重複時の「キャンセル待ちに登録」ボタンが押されたときに、その時間帯のキャンセル待ちに登録する
+func handleWaitlistJoinButton(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool, args []string) {
+	if waitlist == nil {
+		respondError(s, i, "キャンセル待ちは現在利用できません。")
+		return
+	}
+	if len(args) != 1 {
+		respondError(s, i, "この操作は利用できません。もう一度コマンドを実行してください。")
+		return
+	}
+	value, found := pendingInputs.get(args[0])
+	pending, ok := value.(pendingWaitlist)
+	if !found || !ok {
+		respondError(s, i, "ボタンの有効期限が切れました。`/waitlist` で登録してください。")
+		return
+	}
+	joinWaitlist(s, i, store, logger, isDM, pending.Request)
+}
+
+// handleWaitlistLeaveButton はキャンセル待ちを取り消す（案内中の場合は次の人に案内する）
+func handleWaitlistLeaveButton(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool, args []string) {
+	if waitlist == nil || len(args) != 1 {
+		respondError(s, i, "この操作は利用できません。もう一度コマンドを実行してください。")
+		return
+	}
+	userID, _ := getUserInfo(i, isDM)
+
+	waitlistMu.Lock()
+	entry, err := waitlist.Get(args[0])
+	if err != nil {
+		waitlistMu.Unlock()
+		respondError(s, i, "このキャンセル待ちは既に終了しています。")
+		return
+	}
+	if entry.UserID != userID {
+		waitlistMu.Unlock()
+		respondError(s, i, "他のユーザーのキャンセル待ちは取り消せません。")
+		return
+	}
+	_, err = waitlist.Remove(entry.ID)
+	waitlistMu.Unlock()
+	if err != nil {
+		respondError(s, i, "キャンセル待ちの保存に失敗しました")
+		logger.LogError("ERROR", "handleWaitlistLeaveButton", "Failed to save waitlist", err, map[string]interface{}{
+			"waitlist_id": entry.ID,
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{waitlistEmbed(entry, "⚪ キャンセル待ちを取り消しました", 0x99AAB5)}, // Discord Greyple
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+
+	// 案内を辞退した時間帯は次の人に案内する
+	if entry.OfferExpiresAt != nil {
+		notifyWaitlist(s, store, logger)
+	}
+}
+
+// handleWaitlistAcceptButton は案内の「予約する」ボタンが押されたときに、空いた時間帯を予約する
+// 重複の確認と保存は予約の作成と同じく不可分に行うため、他の人が先に予約した場合は予約できない
+func handleWaitlistAcceptButton(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool, args []string) {
+	if waitlist == nil || len(args) != 1 {
+		respondError(s, i, "この操作は利用できません。もう一度コマンドを実行してください。")
+		return
+	}
+	userID, _ := getUserInfo(i, isDM)
+	now := models.Now()
+
+	waitlistMu.Lock()
+	defer waitlistMu.Unlock()
+
+	entry, err := waitlist.Get(args[0])
+	if err != nil {
+		respondError(s, i, "このお知らせは既に終了しています。")
+		return
+	}
+	if entry.UserID != userID {
+		respondError(s, i, "他のユーザーへのお知らせです。")
+		return
+	}
+	if !entry.Offered(now) {
+		respondError(s, i, "お知らせの有効期限が切れました。次の人に案内しています。")
+		return
+	}
+
+	slot := entry.SlotFrom(now)
+	req := reserveRequest{
+		Date:      slot.Date,
+		StartTime: slot.StartTime,
+		EndTime:   slot.EndTime,
+		Room:      slot.GetResourceID(),
+		Comment:   entry.Comment,
+	}
+	if createReservation(s, i, store, logger, allowedChannelID, isDM, "waitlist", req) {
+		if _, err := waitlist.Remove(entry.ID); err != nil {
+			logger.LogError("ERROR", "handleWaitlistAcceptButton", "Failed to save waitlist", err, map[string]interface{}{
+				"waitlist_id": entry.ID,
+			})
+		}
+		return
+	}
+
+	// 予約できなかった場合は順番待ちに戻す（空いていれば次の確認で改めて案内する）
+	if _, err := waitlist.Update(entry.ID, func(e *models.WaitlistEntry) error {
+		e.ClearOffer()
+		return nil
+	}); err != nil {
+		logger.LogError("ERROR", "handleWaitlistAcceptButton", "Failed to save waitlist", err, map[string]interface{}{
+			"waitlist_id": entry.ID,
+		})
+	}
+}
+
+// notifyWaitlist は予約の取り消しや変更で空いた時間帯を、キャンセル待ちのユーザーに案内する
+func notifyWaitlist(s *discordgo.Session, store storage.Repository, logger *logging.Logger) {
+	if _, err := ProcessWaitlist(s, store, logger); err != nil {
+		logger.LogError("ERROR", "notifyWaitlist", "Failed to process waitlist", err, nil)
+	}
+}
+
+// ProcessWaitlist は空いた時間帯をキャンセル待ちの登録の古い順にDMで案内し、案内した件数を返す
+// 期限までに応答のない案内と、時間の過ぎたキャンセル待ちは削除する
+// 案内中の時間帯と重なる後の登録には、その案内が終わるまで案内しない
+// DMの送信中は waitlistMu を保持しないため、その間も「予約する」ボタンにすぐ応答できる
+func ProcessWaitlist(s *discordgo.Session, store storage.Repository, logger *logging.Logger) (int, error) {
+	if waitlist == nil {
+		return 0, nil
+	}
+	waitlistProcessMu.Lock()
+	defer waitlistProcessMu.Unlock()
+
+	now := models.Now()
+	removed, candidates, err := pickWaitlistOffers(store, logger, now)
+	for _, e := range removed {
+		expireWaitlistOffer(s, e, now)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, c := range candidates {
+		message, err := sendWaitlistOffer(s, c.entry, c.slot, now)
+		if err != nil {
+			// DMを受け付けないユーザーには案内できないため、キャンセル待ちを削除する
+			logger.LogError("WARN", "ProcessWaitlist", "Failed to send waitlist offer", err, map[string]interface{}{
+				"waitlist_id": c.entry.ID,
+				"user_id":     c.entry.UserID,
+			})
+			waitlistMu.Lock()
+			removeWaitlistEntry(logger, c.entry)
+			waitlistMu.Unlock()
+			continue
+		}
+		if recordWaitlistOffer(s, logger, c.entry, message) {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// waitlistCandidate は案内を送るキャンセル待ちと、案内する時間帯
+type waitlistCandidate struct {
+	entry *models.WaitlistEntry
+	slot  *models.Reservation
+}
+
+// pickWaitlistOffers は waitlistMu を保持して終了したキャンセル待ちを削除し、案内を送る登録を選ぶ
+// 削除した登録（案内のDMを期限切れにするため）と、案内を送る登録を返す
+func pickWaitlistOffers(store storage.Repository, logger *logging.Logger, now time.Time) ([]*models.WaitlistEntry, []waitlistCandidate, error) {
+	waitlistMu.Lock()
+	defer waitlistMu.Unlock()
+
+	removed := []*models.WaitlistEntry{}
+	candidates := []waitlistCandidate{}
+	offered := []*models.WaitlistEntry{}
+	for _, e := range waitlist.Entries() {
+		if e.Ended(now) || e.OfferExpired(now) {
+			if removeWaitlistEntry(logger, e) {
+				removed = append(removed, e)
+			}
+			continue
+		}
+		if e.Offered(now) {
+			offered = append(offered, e)
+			continue
+		}
+		if overlapsAny(e, offered) {
+			continue
+		}
+
+		slot := e.SlotFrom(now)
+		blocking, err := store.CheckOverlap(slot)
+		if err != nil {
+			return removed, nil, err
+		}
+		if blocking != nil {
+			continue
+		}
+		// 予約が制限されているユーザーには、制限が解けるまで案内しない
+		if restriction, err := reserveRestriction(store, e.UserID, now, 1); err != nil || restriction != "" {
+			continue
+		}
+
+		candidates = append(candidates, waitlistCandidate{entry: e, slot: slot})
+		offered = append(offered, e)
+	}
+	return removed, candidates, nil
+}
+
+// recordWaitlistOffer は送った案内をキャンセル待ちに記録する（記録できた場合は true を返す）
+// DMの送信中にキャンセル待ちが取り消された場合は、送った案内を削除する
+func recordWaitlistOffer(s *discordgo.Session, logger *logging.Logger, e *models.WaitlistEntry, message *discordgo.Message) bool {
+	waitlistMu.Lock()
+	offeredAt := time.Now()
+	expiresAt := offeredAt.Add(waitlistOfferFor)
+	_, err := waitlist.Update(e.ID, func(stored *models.WaitlistEntry) error {
+		stored.OfferedAt = &offeredAt
+		stored.OfferExpiresAt = &expiresAt
+		stored.OfferChannelID = message.ChannelID
+		stored.OfferMessageID = message.ID
+		return nil
+	})
+	waitlistMu.Unlock()
+
+	if err == storage.ErrNotFound {
+		s.ChannelMessageDelete(message.ChannelID, message.ID)
+		return false
+	}
+	if err != nil {
+		logger.LogError("ERROR", "ProcessWaitlist", "Failed to record waitlist offer", err, map[string]interface{}{
+			"waitlist_id": e.ID,
+		})
+		return false
+	}
+	return true
+}
+
+// overlapsAny はキャンセル待ちが entries のいずれかと時間帯が重なるかを返す
+func overlapsAny(e *models.WaitlistEntry, entries []*models.WaitlistEntry) bool {
+	for _, other := range entries {
+		if e.Overlaps(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeWaitlistEntry は終了したキャンセル待ちを削除する（呼び出し側で waitlistMu を保持すること）
+// 削除できた場合は true を返す
+func removeWaitlistEntry(logger *logging.Logger, e *models.WaitlistEntry) bool {
+	if _, err := waitlist.Remove(e.ID); err != nil {
+		logger.LogError("ERROR", "removeWaitlistEntry", "Failed to save waitlist", err, map[string]interface{}{
+			"waitlist_id": e.ID,
+		})
+		return false
+	}
+	return true
+}
+
+// expireWaitlistOffer は削除したキャンセル待ちに案内のDMがあれば、ボタンを外して期限切れにする
+func expireWaitlistOffer(s *discordgo.Session, e *models.WaitlistEntry, now time.Time) {
+	if e.OfferMessageID == "" {
+		return
+	}
+	embed := waitlistEmbed(e, "⚪ お知らせの有効期限が切れました", 0x99AAB5) // Discord Greyple
+	embed.Description = "応答がなかったため、次の人に案内しました。"
+	if e.Ended(now) {
+		embed.Description = "希望の時間が過ぎたため、キャンセル待ちを終了しました。"
+	}
+	s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         e.OfferMessageID,
+		Channel:    e.OfferChannelID,
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: []discordgo.MessageComponent{},
+	})
+}
+
+// sendWaitlistOffer はキャンセル待ちのユーザーに、空いた時間帯を予約するボタン付きでDMする
+func sendWaitlistOffer(s *discordgo.Session, e *models.WaitlistEntry, slot *models.Reservation, now time.Time) (*discordgo.Message, error) {
+	channel, err := s.UserChannelCreate(e.UserID)
+	if err != nil {
+		return nil, err
+	}
+	embed, components := waitlistOffer(e, slot, now)
+	return s.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+	})
+}
+
+// waitlistOffer はキャンセル待ちの案内の埋め込みメッセージとボタンを作成する
+// 開始時刻を過ぎている場合は、今から終了までの時間帯を案内する
+func waitlistOffer(e *models.WaitlistEntry, slot *models.Reservation, now time.Time) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	offered := e.Clone()
+	offered.Date, offered.EndDate, offered.StartTime = slot.Date, slot.EndDate, slot.StartTime
+	embed := waitlistEmbed(offered, "🟢 キャンセル待ちの時間が空きました", 0x57F287) // Discord Green
+	embed.Description = fmt.Sprintf("%s までに「予約する」を押すと予約できます。\n期限を過ぎるか辞退すると、次の人に案内します。",
+		now.Add(waitlistOfferFor).Format("15:04"))
+	return embed, buttonRows([]discordgo.Button{
+		{Label: "予約する", Style: discordgo.SuccessButton, CustomID: newCustomID(actionWaitlistAccept, e.ID)},
+		waitlistLeaveButton(e, "辞退する"),
+	})
+}
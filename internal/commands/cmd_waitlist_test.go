@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// useTestWaitlist はテスト用のキャンセル待ちを設定し、テスト後に元に戻す
+func useTestWaitlist(t *testing.T) *storage.Waitlist {
+	t.Helper()
+	old := waitlist
+	w := storage.NewWaitlist(filepath.Join(t.TempDir(), "waitlist.json"))
+	SetWaitlist(w)
+	t.Cleanup(func() {
+		SetWaitlist(old)
+	})
+	return w
+}
+
+func TestWaitlistJoinErrorAndPosition(t *testing.T) {
+	created := time.Date(2030, 5, 15, 12, 0, 0, 0, models.Location())
+	entries := []*models.WaitlistEntry{
+		{ID: "a", UserID: "first", Date: "2030-05-16", StartTime: "14:00", EndTime: "16:00", CreatedAt: created},
+		{ID: "b", UserID: "second", Date: "2030-05-16", StartTime: "15:00", EndTime: "16:00", CreatedAt: created.Add(time.Minute)},
+		{ID: "c", UserID: "second", Date: "2030-05-16", StartTime: "18:00", EndTime: "19:00", CreatedAt: created.Add(2 * time.Minute)},
+	}
+
+	// 順番は時間帯の重なる先の登録の数で決まる
+	if got := waitlistPosition(entries, entries[1]); got != 2 {
+		t.Errorf("Expected the overlapping entry to be second, got %d", got)
+	}
+	if got := waitlistPosition(entries, entries[2]); got != 1 {
+		t.Errorf("Expected the separate entry to be first, got %d", got)
+	}
+
+	duplicate := &models.WaitlistEntry{UserID: "second", Date: "2030-05-16", StartTime: "15:00", EndTime: "16:00"}
+	if got := waitlistJoinError(entries, duplicate); got == "" {
+		t.Error("Expected a duplicate entry to be rejected")
+	}
+	fresh := &models.WaitlistEntry{UserID: "second", Date: "2030-05-17", StartTime: "15:00", EndTime: "16:00"}
+	if got := waitlistJoinError(entries, fresh); got != "" {
+		t.Errorf("Expected a new slot to be accepted, got %q", got)
+	}
+
+	full := []*models.WaitlistEntry{}
+	for day := 0; day < maxWaitlistEntries; day++ {
+		full = append(full, &models.WaitlistEntry{UserID: "second", Date: fmt.Sprintf("2030-06-%02d", day+1), StartTime: "10:00", EndTime: "11:00"})
+	}
+	if got := waitlistJoinError(full, fresh); got == "" {
+		t.Error("Expected the entry to be rejected once the limit is reached")
+	}
+}
+
+func TestWaitlistOffer(t *testing.T) {
+	entry := &models.WaitlistEntry{ID: "w1", UserID: "member", Date: "2030-05-15", StartTime: "14:00", EndTime: "16:00"}
+	now := time.Date(2030, 5, 15, 14, 20, 30, 0, models.Location())
+
+	embed, components := waitlistOffer(entry, entry.SlotFrom(now), now)
+	if embed.Fields[1].Value != "14:21 - 16:00" {
+		t.Errorf("Expected the offer to run from the next minute until the end, got %q", embed.Fields[1].Value)
+	}
+	if len(components) != 1 {
+		t.Fatalf("Expected one row of buttons, got %d", len(components))
+	}
+	buttons := components[0].(discordgo.ActionsRow).Components
+	if len(buttons) != 2 {
+		t.Fatalf("Expected accept and decline buttons, got %d", len(buttons))
+	}
+	if got := buttons[0].(discordgo.Button).CustomID; got != newCustomID(actionWaitlistAccept, "w1") {
+		t.Errorf("Unexpected accept button %q", got)
+	}
+	if got := buttons[1].(discordgo.Button).CustomID; got != newCustomID(actionWaitlistLeave, "w1") {
+		t.Errorf("Unexpected decline button %q", got)
+	}
+}
+
+func TestProcessWaitlistCleansUp(t *testing.T) {
+	store := newTestStore(t)
+	w := useTestWaitlist(t)
+	logger := logging.NewLogger(t.TempDir())
+
+	if err := store.AddReservation(&models.Reservation{ID: "blocking", UserID: "owner", Date: "2030-05-16", EndDate: "2030-05-16", StartTime: "14:00", EndTime: "16:00", Status: models.StatusPending}); err != nil {
+		t.Fatalf("AddReservation failed: %v", err)
+	}
+	expired := time.Now().Add(-time.Minute)
+	entries := []*models.WaitlistEntry{
+		{ID: "blocked", UserID: "member", Date: "2030-05-16", StartTime: "15:00", EndTime: "16:00", CreatedAt: time.Now()},
+		{ID: "ended", UserID: "member", Date: "2020-05-16", StartTime: "15:00", EndTime: "16:00", CreatedAt: time.Now()},
+		{ID: "expired", UserID: "member", Date: "2030-05-16", StartTime: "14:00", EndTime: "15:00", CreatedAt: time.Now(), OfferedAt: &expired, OfferExpiresAt: &expired},
+	}
+	for _, e := range entries {
+		if err := w.Add(e); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	// 埋まったままの時間帯には案内せず、時間の過ぎた登録と期限切れの案内は削除する
+	sent, err := ProcessWaitlist(nil, store, logger)
+	if err != nil || sent != 0 {
+		t.Fatalf("ProcessWaitlist = %d, %v; want no offers", sent, err)
+	}
+	remaining := w.Entries()
+	if len(remaining) != 1 || remaining[0].ID != "blocked" {
+		t.Errorf("Expected only the blocked entry to remain, got %+v", remaining)
+	}
+}
+
+func TestPickWaitlistOffers(t *testing.T) {
+	store := newTestStore(t)
+	w := useTestWaitlist(t)
+	logger := logging.NewLogger(t.TempDir())
+
+	entries := []*models.WaitlistEntry{
+		{ID: "first", UserID: "member1", Date: "2030-05-16", StartTime: "15:00", EndTime: "16:00", CreatedAt: time.Now().Add(-time.Hour)},
+		{ID: "second", UserID: "member2", Date: "2030-05-16", StartTime: "15:30", EndTime: "16:30", CreatedAt: time.Now()},
+	}
+	for _, e := range entries {
+		if err := w.Add(e); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	// 空いた時間帯は登録の古い人にだけ案内し、DMを送る前に waitlistMu を解放する
+	removed, candidates, err := pickWaitlistOffers(store, logger, models.Now())
+	if err != nil || len(removed) != 0 {
+		t.Fatalf("pickWaitlistOffers = %v, %v; want nothing removed", removed, err)
+	}
+	if len(candidates) != 1 || candidates[0].entry.ID != "first" {
+		t.Errorf("Expected only the first entry to be offered, got %+v", candidates)
+	}
+	if !waitlistMu.TryLock() {
+		t.Fatal("Expected waitlistMu to be released before sending offers")
+	}
+	waitlistMu.Unlock()
+}
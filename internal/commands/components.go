@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// customIDSeparator はボタンやモーダルのカスタムIDで操作名と引数を区切る文字
+// 時刻（HH:MM）にコロンが含まれるため、コロン以外を使う
+const customIDSeparator = "|"
+
+// customIDMaxLength はDiscordが受け付けるカスタムIDの最大文字数
+const customIDMaxLength = 100
+
+// ボタン・モーダルの操作名（カスタムIDの先頭）
+const (
+	actionAvailabilityReserve = "availability-reserve" // 空き時間から予約を作成する
+	actionReserveAlternative  = "reserve-alternative"  // 重複時の候補で予約を作成する
+	actionEditAlternative     = "edit-alternative"     // 重複時の候補に予約を変更する
+	actionReservationCancel   = "reservation-cancel"   // 予約を取り消す
+	actionReservationCheckIn  = "reservation-checkin"  // 予約にチェックインする
+	actionReservationComplete = "reservation-complete" // 予約を完了にする
+	actionReservationEdit     = "reservation-edit"     // 予約の編集フォームを表示する・編集する
+	actionReservationExtend   = "reservation-extend"   // 予約の終了時間を延ばす
+	actionReserveForm         = "reserve-form"         // 予約フォームを送信する
+	actionReserveFormFix      = "reserve-form-fix"     // 予約フォームを入力し直す
+	actionReserveFormConfirm  = "reserve-form-confirm" // 予約フォームの内容で予約する
+	actionReserveFormDiscard  = "reserve-form-discard" // 予約フォームの内容を破棄する
+	actionListPage            = "list-page"            // 予約一覧のページを切り替える
+	actionReminderConfirm     = "reminder-confirm"     // リマインダーで参加予定を伝える
+	actionWaitlistJoin        = "waitlist-join"        // 重複時にキャンセル待ちに登録する
+	actionWaitlistAccept      = "waitlist-accept"      // キャンセル待ちの案内で予約する
+	actionWaitlistLeave       = "waitlist-leave"       // キャンセル待ちを取り消す・案内を辞退する
+)
+
+// newCustomID は操作名と引数からカスタムIDを作成する
+func newCustomID(action string, args ...string) string {
+	return strings.Join(append([]string{action}, args...), customIDSeparator)
+}
+
+// parseCustomID はカスタムIDを操作名と引数に分割する
+func parseCustomID(customID string) (action string, args []string) {
+	parts := strings.Split(customID, customIDSeparator)
+	return parts[0], parts[1:]
+}
+
+// HandleComponent はボタンなどのメッセージコンポーネントのインタラクションを処理する
+// ボタンはBotが送信したメッセージにしか付かないため、チャンネルの制限は行わない
+func HandleComponent(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string) {
+	customID := i.MessageComponentData().CustomID
+	action, args := parseCustomID(customID)
+	isDM := i.GuildID == ""
+
+	userID, username := getUserInfo(i, isDM)
+	logger.LogCommand(action, userID, username, i.ChannelID, true, "", map[string]interface{}{
+		"custom_id": customID,
+	})
+
+	switch action {
+	case actionAvailabilityReserve:
+		handleAvailabilityReserveButton(s, i, args)
+	case actionReserveAlternative:
+		handleReserveAlternativeButton(s, i, store, logger, allowedChannelID, isDM, args)
+	case actionEditAlternative:
+		handleEditAlternativeButton(s, i, store, logger, allowedChannelID, isDM, args)
+	case actionReservationCancel, actionReservationComplete:
+		handleReservationStatusButton(s, i, store, logger, allowedChannelID, isDM, action, args)
+	case actionReservationCheckIn:
+		handleReservationCheckInButton(s, i, store, logger, isDM, args)
+	case actionReservationExtend:
+		handleReservationExtendButton(s, i, store, logger, isDM, args)
+	case actionReservationEdit:
+		handleReservationEditButton(s, i, store, isDM, args)
+	case actionReserveFormFix:
+		handleReserveFormFixButton(s, i, args)
+	case actionReserveFormConfirm:
+		handleReserveFormConfirmButton(s, i, store, logger, allowedChannelID, isDM, args)
+	case actionReserveFormDiscard:
+		handleReserveFormDiscardButton(s, i, args)
+	case actionListPage:
+		handleListPageButton(s, i, store, logger, args)
+	case actionReminderConfirm:
+		handleReminderConfirmButton(s, i, store, logger, isDM, args)
+	case actionWaitlistJoin:
+		handleWaitlistJoinButton(s, i, store, logger, isDM, args)
+	case actionWaitlistAccept:
+		handleWaitlistAcceptButton(s, i, store, logger, allowedChannelID, isDM, args)
+	case actionWaitlistLeave:
+		handleWaitlistLeaveButton(s, i, store, logger, isDM, args)
+	default:
+		respondError(s, i, "この操作は利用できません。もう一度コマンドを実行してください。")
+	}
+}
+
+// HandleModalSubmit はモーダル（入力フォーム）の送信を処理する
+func HandleModalSubmit(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string) {
+	data := i.ModalSubmitData()
+	action, args := parseCustomID(data.CustomID)
+	isDM := i.GuildID == ""
+
+	userID, username := getUserInfo(i, isDM)
+	values := modalValues(data)
+	parameters := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		parameters[name] = value
+	}
+	logger.LogCommand(action, userID, username, i.ChannelID, true, "", parameters)
+
+	switch action {
+	case actionAvailabilityReserve:
+		handleAvailabilityReserveModal(s, i, store, logger, allowedChannelID, isDM, args, values)
+	case actionReservationEdit:
+		handleReservationEditModal(s, i, store, logger, allowedChannelID, isDM, args, values)
+	case actionReserveForm:
+		handleReserveFormModal(s, i, store, logger, values)
+	default:
+		respondError(s, i, "この操作は利用できません。もう一度コマンドを実行してください。")
+	}
+}
+
+// modalValues はモーダルのテキスト入力の値を入力欄のカスタムIDごとに返す
+func modalValues(data discordgo.ModalSubmitInteractionData) map[string]string {
+	values := make(map[string]string)
+	for _, component := range data.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, child := range row.Components {
+			if input, ok := child.(*discordgo.TextInput); ok {
+				values[input.CustomID] = strings.TrimSpace(input.Value)
+			}
+		}
+	}
+	return values
+}
+
+// textInputRow はモーダルに表示する1行のテキスト入力を作成する
+func textInputRow(customID, label, value, placeholder string, style discordgo.TextInputStyle, required bool, maxLength int) discordgo.ActionsRow {
+	return discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.TextInput{
+				CustomID:    customID,
+				Label:       label,
+				Style:       style,
+				Value:       value,
+				Placeholder: placeholder,
+				Required:    required,
+				MaxLength:   maxLength,
+			},
+		},
+	}
+}
+
+// buttonRows はボタンを1行5個ずつの行に分ける（メッセージに付けられるのは5行まで）
+func buttonRows(buttons []discordgo.Button) []discordgo.MessageComponent {
+	rows := []discordgo.MessageComponent{}
+	for start := 0; start < len(buttons) && len(rows) < 5; start += 5 {
+		end := start + 5
+		if end > len(buttons) {
+			end = len(buttons)
+		}
+		row := discordgo.ActionsRow{}
+		for _, button := range buttons[start:end] {
+			row.Components = append(row.Components, button)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
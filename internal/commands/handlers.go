@@ -8,7 +8,7 @@ import (
 
 var UpdateStatusCallback func()
 
-func HandleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate, store *storage.Storage, logger *logging.Logger, allowedChannelID string) {
+func HandleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string) {
 	// コマンドインタラクションの処理
 	commandName := i.ApplicationCommandData().Name
 	isDM := i.GuildID == ""
@@ -32,16 +32,36 @@ func HandleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate, sto
 	switch commandName {
 	case "reserve":
 		handleReserve(s, i, store, logger, allowedChannelID, isDM)
+	case "reserve-recurring":
+		handleReserveRecurring(s, i, store, logger, allowedChannelID, isDM)
 	case "cancel":
 		handleCancel(s, i, store, logger, allowedChannelID, isDM)
 	case "complete":
 		handleComplete(s, i, store, logger, allowedChannelID, isDM)
+	case "checkin":
+		handleCheckIn(s, i, store, logger, isDM)
 	case "edit":
 		handleEdit(s, i, store, logger, allowedChannelID, isDM)
 	case "list":
 		handleList(s, i, store, logger, isDM)
 	case "my-reservations":
 		handleMyReservations(s, i, store, logger, isDM)
+	case "today":
+		handleToday(s, i, store, logger, isDM)
+	case "week":
+		handleWeek(s, i, store, logger, isDM)
+	case "calendar":
+		handleCalendar(s, i, store, logger, isDM)
+	case "availability":
+		handleAvailability(s, i, store, logger, isDM)
+	case "history":
+		handleHistory(s, i, store, logger, isDM)
+	case "waitlist":
+		handleWaitlist(s, i, store, logger, isDM)
+	case "reminders":
+		handleReminders(s, i, logger, isDM)
+	case "penalties":
+		handlePenalties(s, i, logger, isDM)
 	case "help":
 		handleHelp(s, i, logger, isDM)
 	case "feedback":
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// DefaultNoShowGrace は予約開始から何分チェックインがなければ予約を解放するかの既定値
+const DefaultNoShowGrace = 15 * time.Minute
+
+// noShowGrace は予約開始から何分チェックインがなければ予約を解放するか（0の場合は解放しない）
+var noShowGrace = DefaultNoShowGrace
+
+// SetNoShowGrace はチェックインのない予約を解放するまでの時間（予約開始から何分か）を設定する
+func SetNoShowGrace(grace time.Duration) {
+	noShowGrace = grace
+}
+
+// dueNoShows は now の時点で解放する予約を返す
+func dueNoShows(reservations []*models.Reservation, now time.Time, grace time.Duration) []*models.Reservation {
+	due := []*models.Reservation{}
+	for _, r := range reservations {
+		if r.NoShowDue(now, grace) {
+			due = append(due, r)
+		}
+	}
+	return due
+}
+
+// ReleaseNoShows は開始から一定時間チェックインのない予約を無断欠席として解放し、解放した件数を返す
+// 解放した時間帯は通知チャンネルとキャンセル待ちのユーザーに知らせ、他のユーザーが予約できるようにする（予約者には違反を記録する）
+func ReleaseNoShows(s *discordgo.Session, store storage.Repository, logger *logging.Logger, allowedChannelID string) (int, error) {
+	if noShowGrace <= 0 {
+		return 0, nil
+	}
+	now := models.Now()
+	reservations, err := store.QueryReservations(storage.Query{
+		Statuses: []models.ReservationStatus{models.StatusPending},
+		DateFrom: now.Format("2006-01-02"),
+		DateTo:   now.Format("2006-01-02"),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	released := []*models.Reservation{}
+	for _, r := range dueNoShows(reservations, now, noShowGrace) {
+		// 直前にチェックインされた場合は解放しない（確認と変更をストレージのロック内で行う）
+		changed := false
+		reservation, err := store.Mutate(r.ID, func(stored *models.Reservation) error {
+			if !stored.NoShowDue(now, noShowGrace) {
+				return nil
+			}
+			before := stored.Clone()
+			stored.Status = models.StatusNoShow
+			stored.UpdatedAt = time.Now()
+			stored.RecordHistory(before, models.HistoryEntry{
+				At:     time.Now(),
+				Source: models.SourceNoShow,
+				Action: models.HistoryNoShow,
+			})
+			changed = true
+			return nil
+		})
+		if err != nil {
+			logger.LogError("ERROR", "ReleaseNoShows", "Failed to release reservation", err, map[string]interface{}{
+				"reservation_id": r.ID,
+			})
+			continue
+		}
+		if changed {
+			released = append(released, reservation)
+		}
+	}
+	if len(released) == 0 {
+		return 0, nil
+	}
+
+	if err := store.Save(); err != nil {
+		return 0, err
+	}
+	for _, r := range released {
+		recordStrike(logger, r, models.StrikeNoShow, now)
+		refreshReservationNotice(s, r, "")
+		sendNoShowNotice(s, allowedChannelID, r, now)
+	}
+	notifyWaitlist(s, store, logger)
+	return len(released), nil
+}
+
+// sendNoShowNotice は解放された時間帯を、空き時間から予約するボタン付きで通知チャンネルに送信する
+func sendNoShowNotice(s *discordgo.Session, allowedChannelID string, r *models.Reservation, now time.Time) {
+	embed, components := noShowNotice(r, now)
+	s.ChannelMessageSendComplex(notificationChannel(r.ResourceID, allowedChannelID), &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+	})
+}
+
+// noShowNotice は解放の通知の埋め込みメッセージとボタンを作成する
+// 空いたのは今から予約の終了までのため、その時間帯を予約フォームに入力済みにする
+func noShowNotice(r *models.Reservation, now time.Time) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	from := now.Truncate(time.Minute)
+	until := from
+	if end, err := r.GetEndDateTime(); err == nil {
+		until = end
+	}
+	freed := fmt.Sprintf("%s - %s", from.Format("15:04"), until.Format("15:04"))
+	if until.Format("2006-01-02") != from.Format("2006-01-02") {
+		freed = fmt.Sprintf("%s - 翌%s", from.Format("15:04"), until.Format("15:04"))
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "📅 日付",
+			Value:  formatDate(from.Format("2006-01-02")),
+			Inline: true,
+		},
+		{
+			Name:   "🕐 空いた時間",
+			Value:  freed,
+			Inline: true,
+		},
+	}
+	fields = appendResourceField(fields, r.ResourceID)
+	embed := &discordgo.MessageEmbed{
+		Title:       "🟢 部屋が空きました",
+		Description: fmt.Sprintf("<@%s> さんの予約は開始から %s 経ってもチェックインがなかったため、解放されました。", r.UserID, formatDuration(noShowGrace)),
+		Fields:      fields,
+		Color:       0x57F287, // Discord Green
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "部室予約システム  |  no-show",
+		},
+	}
+
+	components := []discordgo.MessageComponent{}
+	customID := newCustomID(actionAvailabilityReserve, r.GetResourceID(), from.Format("2006-01-02"), from.Format("15:04"), until.Format("15:04"))
+	if until.After(from) && len(customID) <= customIDMaxLength {
+		components = buttonRows([]discordgo.Button{
+			{Label: "この時間を予約", Style: discordgo.SuccessButton, CustomID: customID},
+		})
+	}
+	return embed, components
+}
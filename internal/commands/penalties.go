@@ -0,0 +1,357 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// penaltyPolicy は違反の数え方と予約の制限の設定
+var penaltyPolicy = models.DefaultPenaltyPolicy
+
+// penalties はユーザーごとの違反の記録（nil の場合は違反を記録・制限しない）
+var penalties *storage.Penalties
+
+// maxPenaltyLines は /penalties の一覧・違反の記録に表示する最大行数
+const maxPenaltyLines = 20
+
+// SetPenaltyPolicy は違反の数え方と予約の制限を設定する
+func SetPenaltyPolicy(policy models.PenaltyPolicy) {
+	penaltyPolicy = policy
+}
+
+// SetPenalties は違反の記録の保存先を設定する
+func SetPenalties(p *storage.Penalties) {
+	penalties = p
+}
+
+// recordStrike は予約者に違反を記録し、新しく記録した場合は true を返す
+func recordStrike(logger *logging.Logger, r *models.Reservation, kind models.StrikeKind, at time.Time) bool {
+	if penalties == nil {
+		return false
+	}
+	added, err := penalties.AddStrike(r.UserID, models.Strike{At: at, Kind: kind, ReservationID: r.ID})
+	if err != nil {
+		logger.LogError("ERROR", "recordStrike", "Failed to record strike", err, map[string]interface{}{
+			"reservation_id": r.ID,
+			"user_id":        r.UserID,
+			"kind":           string(kind),
+		})
+		return false
+	}
+	return added
+}
+
+// isLateCancel は a が予約を at の時点で取り消すと直前の取り消しになるかを返す
+// 管理者が他のユーザーの予約を取り消した場合は予約者の違反にしない
+func isLateCancel(a actor, r *models.Reservation, at time.Time) bool {
+	return r.UserID == a.UserID && penaltyPolicy.IsLateCancel(r, at)
+}
+
+// lateCancelNotice は直前の取り消しを違反として記録したことを予約者に伝える文面を返す
+func lateCancelNotice(userID string, now time.Time) string {
+	notice := fmt.Sprintf("⚠️ 開始 %s 前を過ぎた取り消しのため、違反として記録されました。", formatPeriod(penaltyPolicy.LateCancelWithin))
+	if standing := standingDescription(userID, now); standing != "" {
+		notice += "\n" + standing
+	}
+	return notice
+}
+
+// sendLateCancelNotice はボタンで直前に取り消した予約者に、違反の記録を本人だけに見えるメッセージで伝える
+func sendLateCancelNotice(s *discordgo.Session, i *discordgo.InteractionCreate, userID string, now time.Time) {
+	s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: lateCancelNotice(userID, now),
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+}
+
+// standingDescription はユーザーの違反の回数と予約の制限を表す文面を返す（違反も制限もない場合は空文字）
+func standingDescription(userID string, now time.Time) string {
+	if penalties == nil {
+		return ""
+	}
+	strikes := penalties.Strikes(userID)
+	recent := penaltyPolicy.RecentStrikes(strikes, now)
+	until, restricted := penaltyPolicy.Restricted(strikes, now)
+	if len(recent) == 0 && !restricted {
+		return ""
+	}
+
+	lines := []string{}
+	if len(recent) > 0 {
+		line := fmt.Sprintf("⚠️ 直近%sの違反: %d 回", formatPeriod(penaltyPolicy.Window), len(recent))
+		if penaltyPolicy.Threshold > 0 {
+			line += fmt.Sprintf("（%d 回で予約を制限）", penaltyPolicy.Threshold)
+		}
+		lines = append(lines, line)
+	}
+	if restricted {
+		lines = append(lines, "⛔ "+restrictionText(until))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// appendStanding は説明文の後に違反の回数と予約の制限を段落として追加する（ない場合はそのまま）
+func appendStanding(description, standing string) string {
+	if standing == "" {
+		return description
+	}
+	return description + "\n\n" + standing
+}
+
+// restrictionText は予約の制限の内容を返す
+func restrictionText(until time.Time) string {
+	if penaltyPolicy.MaxActive <= 0 {
+		return fmt.Sprintf("%s まで予約できません", formatDateTime(until))
+	}
+	return fmt.Sprintf("%s まで、同時に持てる予約は %d 件までです", formatDateTime(until), penaltyPolicy.MaxActive)
+}
+
+// reserveRestriction はユーザーが adding 件の予約を追加できるかを確認し、制限される場合は理由を返す（追加できる場合は空文字）
+func reserveRestriction(store storage.Repository, userID string, now time.Time, adding int) (string, error) {
+	if penalties == nil {
+		return "", nil
+	}
+	until, restricted := penaltyPolicy.Restricted(penalties.Strikes(userID), now)
+	if !restricted {
+		return "", nil
+	}
+
+	reservations, err := store.QueryReservations(storage.Query{
+		UserID:   userID,
+		Statuses: models.ActiveStatuses,
+		DateFrom: now.Format("2006-01-02"),
+	})
+	if err != nil {
+		return "", err
+	}
+	active := 0
+	for _, r := range reservations {
+		if end, err := r.GetEndDateTime(); err == nil && end.After(now) {
+			active++
+		}
+	}
+	if active+adding <= penaltyPolicy.MaxActive {
+		return "", nil
+	}
+	return fmt.Sprintf("違反（無断欠席・直前の取り消し）が続いたため、%s。\n現在の予約: %d 件",
+		restrictionText(until), active), nil
+}
+
+// reserveLocks はユーザーごとに、予約の制限の確認から予約の保存までを直列にするロック
+// 同じユーザーの同時の予約が、どちらも制限の確認を通って上限を超えないようにする
+var reserveLocks = struct {
+	mu    sync.Mutex
+	users map[string]*sync.Mutex
+}{users: make(map[string]*sync.Mutex)}
+
+// lockReservations はユーザーの予約の追加を直列にするロックを取得し、解放する関数を返す
+func lockReservations(userID string) func() {
+	reserveLocks.mu.Lock()
+	lock, ok := reserveLocks.users[userID]
+	if !ok {
+		lock = &sync.Mutex{}
+		reserveLocks.users[userID] = lock
+	}
+	reserveLocks.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// reserveWithinLimit は予約の制限を確認し、制限内であれば重複のない場合に予約を保存する
+// 制限される場合は理由を、重複する場合は重複している予約を返す（確認と保存は予約者ごとに不可分に行う）
+func reserveWithinLimit(store storage.Repository, r *models.Reservation, now time.Time) (string, *models.Reservation, error) {
+	unlock := lockReservations(r.UserID)
+	defer unlock()
+
+	restriction, err := reserveRestriction(store, r.UserID, now, 1)
+	if err != nil || restriction != "" {
+		return restriction, nil, err
+	}
+	overlapping, err := store.ReserveIfFree(r)
+	return "", overlapping, err
+}
+
+// formatPeriod は日単位の期間を「30日間」、それ以外を「3時間」のような形式にする
+func formatPeriod(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%d日間", int(d/(24*time.Hour)))
+	}
+	return formatDuration(d)
+}
+
+// formatDateTime は日時を部室のタイムゾーンで「2025/01/15 14:00」形式にする
+func formatDateTime(t time.Time) string {
+	return t.In(models.Location()).Format("2006/01/02 15:04")
+}
+
+// handlePenalties はユーザーの違反の記録を表示・リセットする（管理者のみ）
+func handlePenalties(s *discordgo.Session, i *discordgo.InteractionCreate, logger *logging.Logger, isDM bool) {
+	a := getActor(i, isDM)
+	if !a.Admin {
+		respondError(s, i, "このコマンドは管理者のみ実行できます。")
+		return
+	}
+	if penalties == nil {
+		respondError(s, i, "違反の記録は現在利用できません。")
+		return
+	}
+
+	targetID, action := "", "show"
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "user":
+			targetID = opt.UserValue(nil).ID
+		case "action":
+			action = opt.StringValue()
+		}
+	}
+	now := models.Now()
+
+	if action == "reset" {
+		if targetID == "" {
+			respondError(s, i, "リセットするユーザーを `user` で指定してください。")
+			return
+		}
+		count, err := penalties.Reset(targetID)
+		if err != nil {
+			respondError(s, i, "違反の記録の保存に失敗しました")
+			logger.LogError("ERROR", "handlePenalties", "Failed to reset strikes", err, map[string]interface{}{
+				"user_id": targetID,
+			})
+			return
+		}
+		logger.LogAdminAction("penalties-reset", a.UserID, a.Username, "", targetID, map[string]interface{}{"strikes": count})
+		respondEmbed(s, i, "⚖️ 違反の記録をリセットしました",
+			fmt.Sprintf("<@%s> の違反の記録 %d 件を消去しました。予約の制限も解除されます。", targetID, count), 0x57F287, true)
+		return
+	}
+
+	embed := penaltyOverviewEmbed(penalties.All(), now)
+	if targetID != "" {
+		embed = userPenaltyEmbed(targetID, penalties.Strikes(targetID), now)
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// penaltyOverviewEmbed は直近に違反のあるユーザーの一覧を作成する（制限中のユーザー、違反の多いユーザーの順）
+func penaltyOverviewEmbed(users map[string][]models.Strike, now time.Time) *discordgo.MessageEmbed {
+	type standing struct {
+		userID     string
+		recent     int
+		until      time.Time
+		restricted bool
+	}
+	standings := []standing{}
+	for userID, strikes := range users {
+		st := standing{userID: userID, recent: len(penaltyPolicy.RecentStrikes(strikes, now))}
+		st.until, st.restricted = penaltyPolicy.Restricted(strikes, now)
+		if st.recent > 0 || st.restricted {
+			standings = append(standings, st)
+		}
+	}
+	sort.Slice(standings, func(a, b int) bool {
+		if standings[a].restricted != standings[b].restricted {
+			return standings[a].restricted
+		}
+		if standings[a].recent != standings[b].recent {
+			return standings[a].recent > standings[b].recent
+		}
+		return standings[a].userID < standings[b].userID
+	})
+
+	description := fmt.Sprintf("直近%sに違反のあるユーザーはいません。", formatPeriod(penaltyPolicy.Window))
+	if len(standings) > 0 {
+		lines := []string{fmt.Sprintf("直近%sの違反の回数です。`user` を指定すると詳細を表示します。\n", formatPeriod(penaltyPolicy.Window))}
+		for idx, st := range standings {
+			if idx == maxPenaltyLines {
+				lines = append(lines, fmt.Sprintf("ほか %d 人", len(standings)-maxPenaltyLines))
+				break
+			}
+			line := fmt.Sprintf("<@%s>  違反 %d 回", st.userID, st.recent)
+			if st.restricted {
+				line += fmt.Sprintf("  ⛔ %s まで制限中", formatDateTime(st.until))
+			}
+			lines = append(lines, line)
+		}
+		description = strings.Join(lines, "\n")
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       "⚖️ 違反の記録",
+		Description: description,
+		Color:       0x5865F2, // Discord Blue
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "部室予約システム  |  penalties",
+		},
+	}
+}
+
+// userPenaltyEmbed はユーザーの違反の記録を新しい順に表示する埋め込みメッセージを作成する
+func userPenaltyEmbed(userID string, strikes []models.Strike, now time.Time) *discordgo.MessageEmbed {
+	standing := standingDescription(userID, now)
+	if standing == "" {
+		standing = "制限はありません"
+	}
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "👤 ユーザー",
+			Value:  fmt.Sprintf("<@%s>", userID),
+			Inline: false,
+		},
+		{
+			Name:   "📊 状態",
+			Value:  standing,
+			Inline: false,
+		},
+	}
+
+	sort.SliceStable(strikes, func(a, b int) bool {
+		return strikes[a].At.After(strikes[b].At)
+	})
+	lines := []string{}
+	for idx, strike := range strikes {
+		if idx == maxPenaltyLines {
+			lines = append(lines, fmt.Sprintf("ほか %d 件", len(strikes)-maxPenaltyLines))
+			break
+		}
+		line := fmt.Sprintf("%s  %s（`%s`）", formatDateTime(strike.At), strike.Kind.Label(), strike.ReservationID)
+		if !strike.At.After(now.Add(-penaltyPolicy.Window)) {
+			line += "  ※期間外"
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "違反の記録はありません")
+	}
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:   fmt.Sprintf("📜 違反の記録（%d件）", len(strikes)),
+		Value:  strings.Join(lines, "\n"),
+		Inline: false,
+	})
+
+	return &discordgo.MessageEmbed{
+		Title:     "⚖️ 違反の記録",
+		Fields:    fields,
+		Color:     0x5865F2, // Discord Blue
+		Timestamp: time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "部室予約システム  |  penalties",
+		},
+	}
+}
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// useTestPenalties はテスト用の違反の記録を設定し、テスト後に元に戻す
+func useTestPenalties(t *testing.T) *storage.Penalties {
+	t.Helper()
+	old, oldPolicy := penalties, penaltyPolicy
+	p := storage.NewPenalties(filepath.Join(t.TempDir(), "penalties.json"))
+	SetPenalties(p)
+	SetPenaltyPolicy(models.DefaultPenaltyPolicy)
+	t.Cleanup(func() {
+		SetPenalties(old)
+		SetPenaltyPolicy(oldPolicy)
+	})
+	return p
+}
+
+func TestReserveRestriction(t *testing.T) {
+	store := newTestStore(t)
+	p := useTestPenalties(t)
+	now := time.Date(2030, 5, 15, 12, 0, 0, 0, models.Location())
+
+	if err := store.AddReservation(&models.Reservation{ID: "upcoming", UserID: "member", Date: "2030-05-16", EndDate: "2030-05-16", StartTime: "10:00", EndTime: "11:00", Status: models.StatusPending}); err != nil {
+		t.Fatalf("AddReservation failed: %v", err)
+	}
+
+	// 違反が回数に達するまでは制限しない
+	for idx, id := range []string{"a", "b"} {
+		if _, err := p.AddStrike("member", models.Strike{At: now.Add(-time.Duration(idx+1) * time.Hour), Kind: models.StrikeNoShow, ReservationID: id}); err != nil {
+			t.Fatalf("AddStrike failed: %v", err)
+		}
+	}
+	if got, err := reserveRestriction(store, "member", now, 1); err != nil || got != "" {
+		t.Errorf("Expected no restriction with two strikes, got %q (%v)", got, err)
+	}
+	if got := standingDescription("member", now); !strings.Contains(got, "直近30日間の違反: 2 回（3 回で予約を制限）") {
+		t.Errorf("Unexpected standing %q", got)
+	}
+
+	if _, err := p.AddStrike("member", models.Strike{At: now.Add(-time.Minute), Kind: models.StrikeLateCancel, ReservationID: "c"}); err != nil {
+		t.Fatalf("AddStrike failed: %v", err)
+	}
+	got, err := reserveRestriction(store, "member", now, 1)
+	if err != nil || !strings.Contains(got, "2030/05/29 11:59 まで、同時に持てる予約は 1 件までです") {
+		t.Errorf("Expected a restriction with one active reservation, got %q (%v)", got, err)
+	}
+	if got, _ := reserveRestriction(store, "other", now, 1); got != "" {
+		t.Errorf("Expected other users not to be restricted, got %q", got)
+	}
+
+	// 予約がなければ制限内の1件は予約できる
+	if _, err := store.Mutate("upcoming", func(r *models.Reservation) error {
+		r.Status = models.StatusCancelled
+		return nil
+	}); err != nil {
+		t.Fatalf("Mutate failed: %v", err)
+	}
+	if got, _ := reserveRestriction(store, "member", now, 1); got != "" {
+		t.Errorf("Expected one reservation to be allowed, got %q", got)
+	}
+	if got, _ := reserveRestriction(store, "member", now, 2); got == "" {
+		t.Error("Expected a recurring reservation over the limit to be restricted")
+	}
+}
+
+func TestReserveWithinLimitSerializesPerUser(t *testing.T) {
+	store := newTestStore(t)
+	p := useTestPenalties(t)
+	now := time.Date(2030, 5, 15, 12, 0, 0, 0, models.Location())
+	for idx, id := range []string{"a", "b", "c"} {
+		if _, err := p.AddStrike("member", models.Strike{At: now.Add(-time.Duration(idx+1) * time.Hour), Kind: models.StrikeNoShow, ReservationID: id}); err != nil {
+			t.Fatalf("AddStrike failed: %v", err)
+		}
+	}
+
+	// 同じユーザーの別の予約が確認から保存までの途中の間は、制限の確認を待つ
+	unlock := lockReservations("member")
+	type result struct {
+		restriction string
+		err         error
+	}
+	done := make(chan result, 1)
+	go func() {
+		r := &models.Reservation{ID: "second", UserID: "member", Date: "2030-05-17", EndDate: "2030-05-17", StartTime: "10:00", EndTime: "11:00", Status: models.StatusPending}
+		restriction, _, err := reserveWithinLimit(store, r, now)
+		done <- result{restriction, err}
+	}()
+	select {
+	case got := <-done:
+		t.Fatalf("Expected reserveWithinLimit to wait for the other reservation, got %+v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := store.AddReservation(&models.Reservation{ID: "first", UserID: "member", Date: "2030-05-16", EndDate: "2030-05-16", StartTime: "10:00", EndTime: "11:00", Status: models.StatusPending}); err != nil {
+		t.Fatalf("AddReservation failed: %v", err)
+	}
+	unlock()
+
+	// 先の予約で上限に達したため、待っていた予約は制限される
+	got := <-done
+	if got.err != nil || got.restriction == "" {
+		t.Errorf("Expected the waiting reservation to be restricted, got %+v", got)
+	}
+	if _, err := store.GetReservation("second"); err == nil {
+		t.Error("Expected the restricted reservation not to be stored")
+	}
+}
+
+func TestIsLateCancelIgnoresAdminCancellation(t *testing.T) {
+	useTestPenalties(t)
+	r := &models.Reservation{ID: "a", UserID: "owner", Date: "2030-05-15", EndDate: "2030-05-15", StartTime: "14:00", EndTime: "15:00", Status: models.StatusPending}
+	at := time.Date(2030, 5, 15, 13, 0, 0, 0, models.Location())
+
+	if !isLateCancel(actor{UserID: "owner"}, r, at) {
+		t.Error("Expected the owner's cancellation an hour before start to be late")
+	}
+	if isLateCancel(actor{UserID: "admin", Admin: true}, r, at) {
+		t.Error("Expected an admin cancellation not to count against the owner")
+	}
+}
+
+func TestPenaltyOverviewEmbed(t *testing.T) {
+	useTestPenalties(t)
+	now := time.Date(2030, 5, 15, 12, 0, 0, 0, models.Location())
+	strike := func(id string, ago time.Duration) models.Strike {
+		return models.Strike{At: now.Add(-ago), Kind: models.StrikeNoShow, ReservationID: id}
+	}
+	users := map[string][]models.Strike{
+		"once":       {strike("a", time.Hour)},
+		"restricted": {strike("b", time.Hour), strike("c", 2*time.Hour), strike("d", 3*time.Hour)},
+		"expired":    {strike("e", 40*24*time.Hour)},
+	}
+
+	description := penaltyOverviewEmbed(users, now).Description
+	restricted, once := strings.Index(description, "<@restricted>"), strings.Index(description, "<@once>")
+	if restricted < 0 || once < 0 || restricted > once {
+		t.Errorf("Expected restricted users first, got %q", description)
+	}
+	if strings.Contains(description, "<@expired>") {
+		t.Errorf("Expected users with only expired strikes to be hidden, got %q", description)
+	}
+}
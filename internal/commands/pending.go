@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// pendingTTL はボタンから続きの操作ができるように入力を保持する時間
+const pendingTTL = 15 * time.Minute
+
+// pendingInputs はボタンやモーダルの操作で後から使う入力（カスタムIDに収まらないコメントなど）を保持する
+// Botを再起動すると失われるため、期限切れと同じように扱う
+var pendingInputs = newPendingStore()
+
+// pendingStore はトークンをキーにして入力を一定時間保持する
+type pendingStore struct {
+	mu    sync.Mutex
+	items map[string]pendingItem
+}
+
+type pendingItem struct {
+	value   interface{}
+	expires time.Time
+}
+
+func newPendingStore() *pendingStore {
+	return &pendingStore{items: make(map[string]pendingItem)}
+}
+
+// put は入力を保存し、カスタムIDに埋め込むトークンを返す
+func (p *pendingStore) put(value interface{}) (string, error) {
+	bytes := make([]byte, 6) // 6バイト = 12文字の16進数文字列
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(bytes)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for key, item := range p.items {
+		if now.After(item.expires) {
+			delete(p.items, key)
+		}
+	}
+	p.items[token] = pendingItem{value: value, expires: now.Add(pendingTTL)}
+	return token, nil
+}
+
+// get はトークンに対応する入力を返す（期限切れの場合は false）
+func (p *pendingStore) get(token string) (interface{}, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	item, ok := p.items[token]
+	if !ok || time.Now().After(item.expires) {
+		delete(p.items, token)
+		return nil, false
+	}
+	return item.value, true
+}
+
+// remove はトークンに対応する入力を削除する
+func (p *pendingStore) remove(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.items, token)
+}
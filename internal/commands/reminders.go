@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// DefaultReminderLead は予約開始の何分前にリマインダーを送るかの既定値
+const DefaultReminderLead = 30 * time.Minute
+
+// reminderLead は予約開始の何分前にリマインダーを送るか（0の場合は送らない）
+var reminderLead = DefaultReminderLead
+
+// preferences はユーザーごとの設定（nil の場合は全員が既定の設定）
+var preferences *storage.Preferences
+
+// SetReminderLead はリマインダーを送るタイミング（予約開始の何分前か）を設定する
+func SetReminderLead(lead time.Duration) {
+	reminderLead = lead
+}
+
+// SetPreferences はユーザーごとの設定の保存先を設定する
+func SetPreferences(p *storage.Preferences) {
+	preferences = p
+}
+
+// userSettings はユーザーの設定を返す
+func userSettings(userID string) models.UserSettings {
+	if preferences == nil {
+		return models.UserSettings{}
+	}
+	return preferences.Get(userID)
+}
+
+// dueReminders は now の時点でリマインダーを送る予約を返す（受け取らない設定のユーザーの予約は除く）
+func dueReminders(reservations []*models.Reservation, now time.Time, lead time.Duration) []*models.Reservation {
+	due := []*models.Reservation{}
+	for _, r := range reservations {
+		if r.ReminderDue(now, lead) && !userSettings(r.UserID).ReminderOptOut {
+			due = append(due, r)
+		}
+	}
+	return due
+}
+
+// SendDueReminders は開始が近い予約の予約者にリマインダーをDMで送り、送信した件数を返す
+// 送信済みの記録は予約に保存するため、再起動しても同じリマインダーは送らない
+func SendDueReminders(s *discordgo.Session, store storage.Repository, logger *logging.Logger) (int, error) {
+	if reminderLead <= 0 {
+		return 0, nil
+	}
+	now := models.Now()
+	reservations, err := store.QueryReservations(storage.Query{
+		Statuses: []models.ReservationStatus{models.StatusPending},
+		DateFrom: now.Format("2006-01-02"),
+		DateTo:   now.Add(reminderLead).Format("2006-01-02"),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, r := range dueReminders(reservations, now, reminderLead) {
+		// 送信前に送信済みとして記録する（DMを受け付けないユーザーに毎回送り直さない）
+		claimed := false
+		reservation, err := store.Mutate(r.ID, func(stored *models.Reservation) error {
+			if !stored.ReminderDue(now, reminderLead) {
+				return nil
+			}
+			remindedAt := time.Now()
+			stored.RemindedAt = &remindedAt
+			claimed = true
+			return nil
+		})
+		if err != nil {
+			logger.LogError("ERROR", "SendDueReminders", "Failed to record reminder", err, map[string]interface{}{
+				"reservation_id": r.ID,
+			})
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		if err := sendReminder(s, reservation, now); err != nil {
+			logger.LogError("WARN", "SendDueReminders", "Failed to send reminder", err, map[string]interface{}{
+				"reservation_id": r.ID,
+				"user_id":        r.UserID,
+			})
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		if err := store.Save(); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// sendReminder は予約者にリマインダーを「行きます」「取り消し」ボタン付きでDMする
+func sendReminder(s *discordgo.Session, r *models.Reservation, now time.Time) error {
+	channel, err := s.UserChannelCreate(r.UserID)
+	if err != nil {
+		return err
+	}
+	_, err = s.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{reminderEmbed(r, now)},
+		Components: reminderButtons(r),
+	})
+	return err
+}
+
+// reminderEmbed はリマインダーの埋め込みメッセージを作成する
+func reminderEmbed(r *models.Reservation, now time.Time) *discordgo.MessageEmbed {
+	embed := reservationEmbed(r, "⏰ まもなく予約の時間です", 0xFEE75C, true) // Discord Yellow
+	if start, err := r.GetStartDateTime(); err == nil && start.After(now) {
+		embed.Description = fmt.Sprintf("開始まであと %s です。", formatDuration(start.Sub(now).Round(time.Minute)))
+	}
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: "部室予約システム  |  reminder  |  /reminders で通知を止められます",
+	}
+	return embed
+}
+
+// reminderButtons はリマインダーに付けるボタンを返す（「行きます」を押した後は取り消しのみ）
+func reminderButtons(r *models.Reservation) []discordgo.MessageComponent {
+	if r.Status != models.StatusPending {
+		return []discordgo.MessageComponent{}
+	}
+	buttons := []discordgo.Button{}
+	if r.ConfirmedAt == nil {
+		buttons = append(buttons, discordgo.Button{Label: "行きます", Style: discordgo.SuccessButton, CustomID: newCustomID(actionReminderConfirm, r.ID)})
+	}
+	buttons = append(buttons, discordgo.Button{Label: reservationButtonLabels[actionReservationCancel], Style: discordgo.DangerButton, CustomID: newCustomID(actionReservationCancel, r.ID)})
+	return buttonRows(buttons)
+}
+
+// handleReminderConfirmButton はリマインダーの「行きます」ボタンが押されたときに参加予定を記録する
+func handleReminderConfirmButton(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool, args []string) {
+	a := getActor(i, isDM)
+	target := reservationButtonTarget(s, i, store, a, args, "他のユーザーの予約には応答できません。")
+	if target == nil {
+		return
+	}
+
+	reservation, err := store.Mutate(target.ID, func(r *models.Reservation) error {
+		if r.ConfirmedAt == nil {
+			confirmedAt := time.Now()
+			r.ConfirmedAt = &confirmedAt
+		}
+		return nil
+	})
+	if err != nil {
+		respondError(s, i, "予約の更新に失敗しました")
+		logger.LogError("ERROR", "handleReminderConfirmButton", "Failed to update reservation", err, map[string]interface{}{
+			"reservation_id": target.ID,
+		})
+		return
+	}
+	if err := store.Save(); err != nil {
+		logger.LogError("ERROR", "handleReminderConfirmButton", "Failed to save reservations", err, map[string]interface{}{
+			"reservation_id": reservation.ID,
+		})
+	}
+
+	embed := reservationEmbed(reservation, "✅ 参加予定を確認しました", 0x57F287, true) // Discord Green
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: "部室予約システム  |  reminder",
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: reminderButtons(reservation),
+		},
+	})
+}
+
+// handleReminders はリマインダーを受け取るかどうかを表示・変更する
+func handleReminders(s *discordgo.Session, i *discordgo.InteractionCreate, logger *logging.Logger, isDM bool) {
+	userID, username := getUserInfo(i, isDM)
+	if preferences == nil || reminderLead <= 0 {
+		respondError(s, i, "リマインダーは現在利用できません。")
+		return
+	}
+
+	settings := preferences.Get(userID)
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name != "setting" {
+			continue
+		}
+		optOut := opt.StringValue() == "off"
+		updated, err := preferences.Update(userID, func(s *models.UserSettings) {
+			s.ReminderOptOut = optOut
+		})
+		if err != nil {
+			respondError(s, i, "設定の保存に失敗しました")
+			logger.LogError("ERROR", "handleReminders", "Failed to save preferences", err, map[string]interface{}{
+				"user_id":  userID,
+				"username": username,
+			})
+			return
+		}
+		settings = updated
+	}
+
+	description := fmt.Sprintf("予約開始の %s 前に、予約の内容をDMでお知らせします。\n`/reminders setting:受け取らない` で通知を止められます。", formatDuration(reminderLead))
+	if settings.ReminderOptOut {
+		description = "リマインダーを受け取らない設定です。\n`/reminders setting:受け取る` で通知を再開できます。"
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       "⏰ リマインダーの設定",
+		Description: description,
+		Color:       0x5865F2,
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "部室予約システム  |  reminders",
+		},
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
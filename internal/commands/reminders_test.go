@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+func TestDueRemindersSkipsOptedOutUsers(t *testing.T) {
+	old := preferences
+	prefs := storage.NewPreferences(filepath.Join(t.TempDir(), "preferences.json"))
+	SetPreferences(prefs)
+	t.Cleanup(func() { SetPreferences(old) })
+	if _, err := prefs.Update("quiet", func(s *models.UserSettings) { s.ReminderOptOut = true }); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	created := time.Date(2030, 5, 1, 0, 0, 0, 0, models.Location())
+	reservations := []*models.Reservation{
+		{ID: "soon", UserID: "member", Date: "2030-05-15", StartTime: "14:00", EndTime: "15:00", Status: models.StatusPending, CreatedAt: created},
+		{ID: "quiet", UserID: "quiet", Date: "2030-05-15", StartTime: "14:00", EndTime: "15:00", Status: models.StatusPending, CreatedAt: created},
+		{ID: "later", UserID: "member", Date: "2030-05-15", StartTime: "16:00", EndTime: "17:00", Status: models.StatusPending, CreatedAt: created},
+	}
+	now := time.Date(2030, 5, 15, 13, 40, 0, 0, models.Location())
+
+	due := dueReminders(reservations, now, 30*time.Minute)
+	if len(due) != 1 || due[0].ID != "soon" {
+		t.Errorf("Expected only the reservation starting soon, got %+v", due)
+	}
+}
+
+func TestReminderButtons(t *testing.T) {
+	r := &models.Reservation{ID: "abc", Status: models.StatusPending}
+	customIDs := func(components []discordgo.MessageComponent) []string {
+		ids := []string{}
+		for _, row := range components {
+			for _, c := range row.(discordgo.ActionsRow).Components {
+				ids = append(ids, c.(discordgo.Button).CustomID)
+			}
+		}
+		return ids
+	}
+
+	ids := customIDs(reminderButtons(r))
+	if len(ids) != 2 || ids[0] != newCustomID(actionReminderConfirm, "abc") || ids[1] != newCustomID(actionReservationCancel, "abc") {
+		t.Errorf("Unexpected buttons: %v", ids)
+	}
+
+	confirmed := time.Now()
+	r.ConfirmedAt = &confirmed
+	if ids := customIDs(reminderButtons(r)); len(ids) != 1 || ids[0] != newCustomID(actionReservationCancel, "abc") {
+		t.Errorf("Expected only the cancel button after confirming, got %v", ids)
+	}
+
+	r.Status = models.StatusCancelled
+	if ids := customIDs(reminderButtons(r)); len(ids) != 0 {
+		t.Errorf("Expected no buttons for a cancelled reservation, got %v", ids)
+	}
+}
@@ -0,0 +1,450 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/logging"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// extendStep は「延長」ボタンで終了時間を延ばす長さ
+const extendStep = 30 * time.Minute
+
+// reservationButtonLabels は予約の操作ボタンの表示名（履歴の変更元の表示にも使う）
+var reservationButtonLabels = map[string]string{
+	actionReservationCancel:   "取り消し",
+	actionReservationCheckIn:  "チェックイン",
+	actionReservationComplete: "完了",
+	actionReservationEdit:     "編集",
+	actionReservationExtend:   fmt.Sprintf("%s延長", formatDuration(extendStep)),
+}
+
+// reservationButtons は予約の状態に応じた操作ボタンを返す
+// 予約中は全ての操作、利用中は延長と完了のみ、それ以外はボタンなし
+func reservationButtons(r *models.Reservation) []discordgo.MessageComponent {
+	switch r.Status {
+	case models.StatusPending:
+		return buttonRows([]discordgo.Button{
+			{Label: reservationButtonLabels[actionReservationCheckIn], Style: discordgo.SuccessButton, CustomID: newCustomID(actionReservationCheckIn, r.ID)},
+			{Label: reservationButtonLabels[actionReservationExtend], Style: discordgo.SecondaryButton, CustomID: newCustomID(actionReservationExtend, r.ID)},
+			{Label: reservationButtonLabels[actionReservationEdit], Style: discordgo.SecondaryButton, CustomID: newCustomID(actionReservationEdit, r.ID)},
+			{Label: reservationButtonLabels[actionReservationComplete], Style: discordgo.PrimaryButton, CustomID: newCustomID(actionReservationComplete, r.ID)},
+			{Label: reservationButtonLabels[actionReservationCancel], Style: discordgo.DangerButton, CustomID: newCustomID(actionReservationCancel, r.ID)},
+		})
+	case models.StatusCheckedIn:
+		return buttonRows([]discordgo.Button{
+			{Label: reservationButtonLabels[actionReservationExtend], Style: discordgo.SecondaryButton, CustomID: newCustomID(actionReservationExtend, r.ID)},
+			{Label: reservationButtonLabels[actionReservationComplete], Style: discordgo.PrimaryButton, CustomID: newCustomID(actionReservationComplete, r.ID)},
+		})
+	default:
+		return []discordgo.MessageComponent{}
+	}
+}
+
+// reservationEmbed は予約の内容を表示する埋め込みメッセージを作成する
+// withID が true の場合は予約IDを、false の場合は予約者を表示する（公開通知には予約IDを含めない）
+func reservationEmbed(r *models.Reservation, title string, color int, withID bool) *discordgo.MessageEmbed {
+	first := &discordgo.MessageEmbedField{
+		Name:   "👤 予約者",
+		Value:  fmt.Sprintf("<@%s>", r.UserID),
+		Inline: false,
+	}
+	if withID {
+		first = &discordgo.MessageEmbedField{
+			Name:   "予約ID",
+			Value:  fmt.Sprintf("`%s`", r.ID),
+			Inline: false,
+		}
+	}
+	fields := []*discordgo.MessageEmbedField{
+		first,
+		{
+			Name:   "📅 日付",
+			Value:  formatDateRange(r),
+			Inline: true,
+		},
+		{
+			Name:   "🕐 時間",
+			Value:  formatTimeRange(r),
+			Inline: true,
+		},
+	}
+	fields = appendResourceField(fields, r.ResourceID)
+	if r.Comment != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "💬 コメント",
+			Value:  r.Comment,
+			Inline: false,
+		})
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:     title,
+		Fields:    fields,
+		Color:     color,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "部室予約システム  |  reserve",
+		},
+	}
+}
+
+// noticeTitle は予約の状態に応じた公開通知のタイトルと色を返す
+func noticeTitle(r *models.Reservation) (string, int) {
+	switch r.Status {
+	case models.StatusCancelled:
+		return "🔴 この予約は取り消されました", 0xED4245 // Discord Red
+	case models.StatusCompleted:
+		return "🔵 この予約は終わりました", 0x5865F2 // Discord Blue
+	case models.StatusCheckedIn:
+		return "🟣 利用中です", 0x9B59B6 // Purple
+	case models.StatusNoShow:
+		return "⚪ チェックインがなかったため解放されました", 0x99AAB5 // Discord Greyple
+	default:
+		return "🟢 新しい予約が追加されました", 0x57F287 // Discord Green
+	}
+}
+
+// sendReservationNotice は予約追加の公開通知を操作ボタン付きで送信し、後から更新できるようにメッセージを記録する
+func sendReservationNotice(s *discordgo.Session, store storage.Repository, logger *logging.Logger, channelID string, r *models.Reservation) {
+	title, color := noticeTitle(r)
+	message, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{reservationEmbed(r, title, color, false)},
+		Components: reservationButtons(r),
+	})
+	if err != nil {
+		return
+	}
+
+	if _, err := store.Mutate(r.ID, func(stored *models.Reservation) error {
+		stored.NoticeChannelID = message.ChannelID
+		stored.NoticeMessageID = message.ID
+		return nil
+	}); err != nil {
+		logger.LogError("ERROR", "sendReservationNotice", "Failed to record notice message", err, map[string]interface{}{
+			"reservation_id": r.ID,
+		})
+		return
+	}
+	if err := store.Save(); err != nil {
+		logger.LogError("ERROR", "sendReservationNotice", "Failed to save reservations", err, map[string]interface{}{
+			"reservation_id": r.ID,
+		})
+	}
+}
+
+// refreshReservationNotice は予約追加の公開通知を現在の予約の内容に更新する（予約中でなくなった場合はボタンを外す）
+// skipMessageID のメッセージ（ボタンが押されたメッセージ）はインタラクションの応答で更新するため対象外にする
+func refreshReservationNotice(s *discordgo.Session, r *models.Reservation, skipMessageID string) {
+	if r.NoticeMessageID == "" || r.NoticeMessageID == skipMessageID {
+		return
+	}
+	title, color := noticeTitle(r)
+	s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         r.NoticeMessageID,
+		Channel:    r.NoticeChannelID,
+		Embeds:     []*discordgo.MessageEmbed{reservationEmbed(r, title, color, false)},
+		Components: reservationButtons(r),
+	})
+}
+
+// updatePressedMessage はボタンが押されたメッセージを現在の予約の内容に置き換える
+// 予約中のままであれば元のタイトルを残し、利用中・取り消し・完了した場合は状態を表すタイトルにする
+func updatePressedMessage(s *discordgo.Session, i *discordgo.InteractionCreate, r *models.Reservation) {
+	title, color := noticeTitle(r)
+	withID := i.Message != nil && i.Message.Flags&discordgo.MessageFlagsEphemeral != 0
+	if r.Status == models.StatusPending && i.Message != nil && len(i.Message.Embeds) > 0 {
+		title, color = i.Message.Embeds[0].Title, i.Message.Embeds[0].Color
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{reservationEmbed(r, title, color, withID)},
+			Components: reservationButtons(r),
+		},
+	})
+}
+
+// newButtonHistoryEntry はボタンを押したユーザーによる変更履歴を作成する
+func newButtonHistoryEntry(a actor, action string, historyAction models.HistoryAction) models.HistoryEntry {
+	entry := newHistoryEntry(a, "", historyAction)
+	entry.Source = models.ButtonSource(action)
+	return entry
+}
+
+// reservationButtonTarget はボタンの引数から予約を取得し、操作できるかを確認する
+// 予約中・利用中でない場合はボタンが押されたメッセージを現在の状態に更新し、nil を返す
+func reservationButtonTarget(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, a actor, args []string, forbidden string) *models.Reservation {
+	if len(args) != 1 {
+		respondError(s, i, "この操作は利用できません。もう一度コマンドを実行してください。")
+		return nil
+	}
+	reservation, err := store.GetReservation(args[0])
+	if err != nil {
+		respondError(s, i, "予約が見つかりませんでした。")
+		return nil
+	}
+	if !a.canModify(reservation) {
+		respondError(s, i, forbidden)
+		return nil
+	}
+	if !reservation.Status.IsActive() {
+		updatePressedMessage(s, i, reservation)
+		return nil
+	}
+	return reservation
+}
+
+// handleReservationStatusButton は「取り消し」「完了」ボタンが押されたときに予約の状態を変更する
+func handleReservationStatusButton(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool, action string, args []string) {
+	a := getActor(i, isDM)
+	status, historyAction, command := models.StatusCancelled, models.HistoryCancelled, "cancel"
+	forbidden := "他のユーザーの予約は取り消せません。"
+	if action == actionReservationComplete {
+		status, historyAction, command = models.StatusCompleted, models.HistoryCompleted, "complete"
+		forbidden = "他のユーザーの予約は完了にできません。"
+	}
+
+	target := reservationButtonTarget(s, i, store, a, args, forbidden)
+	if target == nil {
+		return
+	}
+
+	// 権限と状態の確認と変更をストレージのロック内で行う（同時に押された場合も1回だけ変更する）
+	now := models.Now()
+	stale, late := false, false
+	reservation, err := store.Mutate(target.ID, func(r *models.Reservation) error {
+		if err := a.authorize(r); err != nil {
+			return err
+		}
+		if !r.Status.IsActive() {
+			stale = true
+			return nil
+		}
+		late = status == models.StatusCancelled && isLateCancel(a, r, now)
+		before := r.Clone()
+		r.Status = status
+		r.UpdatedAt = time.Now()
+		r.RecordHistory(before, newButtonHistoryEntry(a, action, historyAction))
+		return nil
+	})
+	if err == errForbidden {
+		respondError(s, i, forbidden)
+		return
+	}
+	if err != nil {
+		respondError(s, i, "予約の更新に失敗しました")
+		logger.LogError("ERROR", "handleReservationStatusButton", "Failed to update reservation", err, map[string]interface{}{
+			"reservation_id": target.ID,
+			"action":         action,
+		})
+		return
+	}
+	if stale {
+		updatePressedMessage(s, i, reservation)
+		return
+	}
+
+	if err := store.Save(); err != nil {
+		respondError(s, i, "予約の保存に失敗しました")
+		logger.LogError("ERROR", "handleReservationStatusButton", "Failed to save reservations", err, map[string]interface{}{
+			"reservation_id": reservation.ID,
+			"action":         action,
+		})
+		return
+	}
+
+	a.logOverride(logger, command, reservation, map[string]interface{}{"source": models.ButtonSource(action)})
+
+	updatePressedMessage(s, i, reservation)
+	refreshReservationNotice(s, reservation, i.Message.ID)
+	if late && recordStrike(logger, reservation, models.StrikeLateCancel, now) {
+		sendLateCancelNotice(s, i, reservation.UserID, now)
+	}
+	if status == models.StatusCancelled {
+		sendCancelNotice(s, allowedChannelID, a, reservation, "")
+	} else {
+		sendCompleteNotice(s, allowedChannelID, a, reservation, "")
+	}
+
+	// 空いた時間帯をキャンセル待ちのユーザーに案内する
+	notifyWaitlist(s, store, logger)
+
+	// Botステータスを更新
+	if UpdateStatusCallback != nil {
+		UpdateStatusCallback()
+	}
+}
+
+// handleReservationExtendButton は「延長」ボタンが押されたときに終了時間を延ばす
+func handleReservationExtendButton(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, isDM bool, args []string) {
+	a := getActor(i, isDM)
+	reservation := reservationButtonTarget(s, i, store, a, args, "他のユーザーの予約は延長できません。")
+	if reservation == nil {
+		return
+	}
+
+	// 保存済みの予約を延長し、重複チェックと更新を不可分に実行（自分の予約以外との重複を確認）
+	now := models.Now()
+	updated, overlapping, err := store.UpdateIfFree(reservation.ID, func(r *models.Reservation) error {
+		if err := a.authorize(r); err != nil {
+			return err
+		}
+		if !r.Status.IsActive() {
+			return errNotActive
+		}
+		extended, inputErr := extendedReservation(r, now)
+		if inputErr != nil {
+			return inputErr
+		}
+		extended.RecordHistory(r, newButtonHistoryEntry(a, actionReservationExtend, models.HistoryEdited))
+		*r = *extended
+		return nil
+	})
+	if err == errForbidden {
+		respondError(s, i, "他のユーザーの予約は延長できません。")
+		return
+	}
+	if err == errNotActive {
+		respondError(s, i, notActiveMessage)
+		return
+	}
+	var inputErr *inputError
+	if errors.As(err, &inputErr) {
+		respondError(s, i, inputErr.Message)
+		return
+	}
+	if err != nil {
+		respondError(s, i, "予約の更新に失敗しました。")
+		logger.LogError("ERROR", "handleReservationExtendButton", "Failed to update reservation", err, map[string]interface{}{
+			"reservation_id": reservation.ID,
+		})
+		return
+	}
+	if overlapping != nil {
+		respondError(s, i, fmt.Sprintf("延長すると %s %s の予約と重複するため、延長できません。",
+			formatDateRange(overlapping), formatTimeRange(overlapping)))
+		return
+	}
+
+	if err := store.Save(); err != nil {
+		respondError(s, i, "予約の保存に失敗しました")
+		logger.LogError("ERROR", "handleReservationExtendButton", "Failed to save reservations", err, map[string]interface{}{
+			"reservation_id": reservation.ID,
+		})
+		return
+	}
+
+	a.logOverride(logger, "edit", updated, map[string]interface{}{
+		"source":   models.ButtonSource(actionReservationExtend),
+		"end_time": updated.EndTime,
+	})
+
+	updatePressedMessage(s, i, updated)
+	refreshReservationNotice(s, updated, i.Message.ID)
+
+	// Botステータスを更新
+	if UpdateStatusCallback != nil {
+		UpdateStatusCallback()
+	}
+}
+
+// extendedReservation は予約の終了時間を extendStep 延ばしたコピーを返す
+// 終了した予約と、延ばすと利用時間が24時間以上になる・翌日より後に終わる予約は延長できない
+func extendedReservation(r *models.Reservation, now time.Time) (*models.Reservation, *inputError) {
+	start, err := r.GetStartDateTime()
+	if err != nil {
+		return nil, newInputError("予約の日時を読み取れませんでした。")
+	}
+	end, err := r.GetEndDateTime()
+	if err != nil {
+		return nil, newInputError("予約の日時を読み取れませんでした。")
+	}
+	if end.Before(now) {
+		return nil, newInputError("終了した予約は延長できません。")
+	}
+
+	extended := end.Add(extendStep)
+	lastDay := start.AddDate(0, 0, 1).Format("2006-01-02")
+	if extended.Sub(start) >= 24*time.Hour || extended.Format("2006-01-02") > lastDay {
+		return nil, newInputError("予約は24時間未満で翌日までに終わる必要があるため、これ以上延長できません。")
+	}
+
+	updated := r.Clone()
+	updated.EndDate = extended.Format("2006-01-02")
+	updated.EndTime = extended.Format("15:04")
+	updated.UpdatedAt = time.Now()
+	return updated, nil
+}
+
+// handleReservationEditButton は「編集」ボタンが押されたときに、現在の内容を入力済みの編集フォームを表示する
+func handleReservationEditButton(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, isDM bool, args []string) {
+	reservation := reservationButtonTarget(s, i, store, getActor(i, isDM), args, "他のユーザーの予約は編集できません。")
+	if reservation == nil {
+		return
+	}
+	if reservation.Status != models.StatusPending {
+		updatePressedMessage(s, i, reservation)
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: newCustomID(actionReservationEdit, reservation.ID),
+			Title:    "予約の編集",
+			Components: []discordgo.MessageComponent{
+				textInputRow("date", "予約日", formatDate(reservation.Date), "YYYY/MM/DD", discordgo.TextInputShort, true, 10),
+				textInputRow("start_time", "開始時間", reservation.StartTime, "HH:MM", discordgo.TextInputShort, true, 5),
+				textInputRow("end_time", "終了時間", reservation.EndTime, "HH:MM（開始より前の時刻は翌日）", discordgo.TextInputShort, true, 5),
+				textInputRow("comment", "コメント（任意）", reservation.Comment, "", discordgo.TextInputParagraph, false, 200),
+			},
+		},
+	})
+}
+
+// handleReservationEditModal は編集フォームの送信を /edit と同じ手順で予約に反映する
+func handleReservationEditModal(s *discordgo.Session, i *discordgo.InteractionCreate, store storage.Repository, logger *logging.Logger, allowedChannelID string, isDM bool, args []string, values map[string]string) {
+	a := getActor(i, isDM)
+	if len(args) != 1 {
+		respondError(s, i, "この操作は利用できません。もう一度コマンドを実行してください。")
+		return
+	}
+	reservation, err := store.GetReservation(args[0])
+	if err != nil {
+		respondError(s, i, "指定された予約が見つかりません。")
+		return
+	}
+	if !a.canModify(reservation) {
+		respondError(s, i, "他のユーザーの予約は編集できません。")
+		return
+	}
+	if reservation.Status != models.StatusPending {
+		respondError(s, i, "利用中・完了・キャンセルされた予約は編集できません。")
+		return
+	}
+
+	slot, inputErr := parseEditSlotInput(reservation, values["date"], values["start_time"], values["end_time"])
+	if inputErr != nil {
+		respondError(s, i, inputErr.Message)
+		return
+	}
+	req := editRequest{
+		Date:       slot.Date,
+		StartTime:  slot.StartTime,
+		EndTime:    slot.EndTime,
+		Comment:    values["comment"],
+		ResourceID: reservation.GetResourceID(),
+	}
+	if req.Date == reservation.Date && req.StartTime == reservation.StartTime && req.EndTime == reservation.EndTime && req.Comment == reservation.Comment {
+		respondError(s, i, "変更された項目がありません。")
+		return
+	}
+
+	applyEdit(s, i, store, logger, allowedChannelID, isDM, a, reservation, req)
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestReservationButtons(t *testing.T) {
+	r := &models.Reservation{ID: "0123456789abcdef0123456789abcdef", Status: models.StatusPending}
+	rows := reservationButtons(r)
+	if len(rows) != 1 {
+		t.Fatalf("Expected one row of buttons, got %d", len(rows))
+	}
+
+	actions := map[string]bool{}
+	for _, component := range rows[0].(discordgo.ActionsRow).Components {
+		button := component.(discordgo.Button)
+		if len(button.CustomID) > customIDMaxLength {
+			t.Errorf("Custom ID %q is too long", button.CustomID)
+		}
+		action, args := parseCustomID(button.CustomID)
+		if len(args) != 1 || args[0] != r.ID {
+			t.Errorf("Expected the reservation ID in %q", button.CustomID)
+		}
+		actions[action] = true
+	}
+	for _, action := range []string{actionReservationCancel, actionReservationCheckIn, actionReservationComplete, actionReservationEdit, actionReservationExtend} {
+		if !actions[action] {
+			t.Errorf("Expected a %s button", action)
+		}
+	}
+
+	r.Status = models.StatusCheckedIn
+	checkedIn := []string{}
+	for _, row := range reservationButtons(r) {
+		for _, component := range row.(discordgo.ActionsRow).Components {
+			action, _ := parseCustomID(component.(discordgo.Button).CustomID)
+			checkedIn = append(checkedIn, action)
+		}
+	}
+	if len(checkedIn) != 2 || checkedIn[0] != actionReservationExtend || checkedIn[1] != actionReservationComplete {
+		t.Errorf("Expected only extend and complete buttons for a checked-in reservation, got %v", checkedIn)
+	}
+
+	for _, status := range []models.ReservationStatus{models.StatusCancelled, models.StatusCompleted, models.StatusNoShow} {
+		r.Status = status
+		if rows := reservationButtons(r); len(rows) != 0 {
+			t.Errorf("Expected no buttons for a %s reservation, got %d row(s)", status, len(rows))
+		}
+	}
+}
+
+func TestReservationEmbedHidesIDInPublicNotice(t *testing.T) {
+	r := &models.Reservation{ID: "secret-id", UserID: "user-1", Date: "2030-01-10", EndDate: "2030-01-10", StartTime: "10:00", EndTime: "11:00", Status: models.StatusPending}
+
+	title, _ := noticeTitle(r)
+	for _, field := range reservationEmbed(r, title, 0, false).Fields {
+		if field.Name == "予約ID" {
+			t.Error("Expected the public notice not to include the reservation ID")
+		}
+	}
+	if fields := reservationEmbed(r, title, 0, true).Fields; fields[0].Value != "`secret-id`" {
+		t.Errorf("Expected the confirmation to start with the reservation ID, got %q", fields[0].Value)
+	}
+}
+
+func TestHistorySourceLabelForButtons(t *testing.T) {
+	if got := historySourceLabel(models.ButtonSource(actionReservationExtend)); got != "「30分延長」ボタン" {
+		t.Errorf("Unexpected label %q", got)
+	}
+}
+
+func TestExtendedReservation(t *testing.T) {
+	now := time.Date(2030, 5, 15, 12, 0, 0, 0, models.Location())
+	tests := []struct {
+		name     string
+		r        *models.Reservation
+		wantDate string
+		wantEnd  string
+	}{
+		{"same day", &models.Reservation{Date: "2030-05-15", EndDate: "2030-05-15", StartTime: "12:00", EndTime: "13:00"}, "2030-05-15", "13:30"},
+		{"past midnight", &models.Reservation{Date: "2030-05-15", EndDate: "2030-05-15", StartTime: "22:00", EndTime: "23:50"}, "2030-05-16", "00:20"},
+		{"24 hours", &models.Reservation{Date: "2030-05-15", EndDate: "2030-05-16", StartTime: "22:00", EndTime: "21:30"}, "", ""},
+		{"ended", &models.Reservation{Date: "2030-05-15", EndDate: "2030-05-15", StartTime: "10:00", EndTime: "11:00"}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, inputErr := extendedReservation(tt.r, now)
+			if tt.wantEnd == "" {
+				if inputErr == nil {
+					t.Errorf("Expected the extension to be rejected, got %s %s", updated.EndDate, updated.EndTime)
+				}
+				return
+			}
+			if inputErr != nil {
+				t.Fatalf("extendedReservation failed: %s", inputErr.Message)
+			}
+			if updated.EndDate != tt.wantDate || updated.EndTime != tt.wantEnd {
+				t.Errorf("Expected the end to be %s %s, got %s %s", tt.wantDate, tt.wantEnd, updated.EndDate, updated.EndTime)
+			}
+			if tt.r.EndTime == updated.EndTime {
+				t.Error("Expected the original reservation to be left unchanged")
+			}
+		})
+	}
+}
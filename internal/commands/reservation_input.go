@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/timeparse"
+)
+
+// slotInput は検証・正規化済みの予約日時
+type slotInput struct {
+	Date      string // 予約日（YYYY-MM-DD形式）
+	EndDate   string // 終了日（YYYY-MM-DD形式、日を跨ぐ場合は予約日の翌日）
+	StartTime string // 開始時間（HH:MM形式）
+	EndTime   string // 終了時間（HH:MM形式）
+}
+
+// inputError はユーザーに表示する入力エラー
+type inputError struct {
+	Message string // ユーザー向けのメッセージ
+	Reason  string // ログに記録する理由
+}
+
+func (e *inputError) Error() string {
+	return e.Reason
+}
+
+// newInputError はメッセージをそのままログにも記録する入力エラーを作成する
+func newInputError(message string) *inputError {
+	return &inputError{Message: message, Reason: message}
+}
+
+// parseDateInput は日付の入力を検証する（YYYY-MM-DD、YYYY/MM/DD のほか「明日」「来週火曜」「next fri」なども許可）
+func parseDateInput(date string) (time.Time, *inputError) {
+	parsed, err := timeparse.ParseDate(date, models.Now())
+	if err != nil {
+		return time.Time{}, newInputError("日付の形式が正しくありません（例: 2025/10/15、10/15、明日、来週火曜）")
+	}
+	return parsed, nil
+}
+
+// parseClockInput は時刻の入力を HH:MM 形式に正規化する（「15時半」「午後3時」「3pm」なども許可）
+// label はエラーメッセージに表示する項目名
+func parseClockInput(value, label string) (string, *inputError) {
+	clock, err := timeparse.ParseClock(value)
+	if err != nil {
+		return "", newInputError(label + "の形式が正しくありません（例: 14:00、15時半、3pm）")
+	}
+	return clock, nil
+}
+
+// parseEndTimeInput は終了時間の入力を HH:MM 形式に正規化する
+// 「2時間」「90分」のように単位付きの長さを指定した場合は、開始時刻からの利用時間とみなす
+func parseEndTimeInput(startTime, value string) (string, *inputError) {
+	if clock, err := timeparse.ParseClock(value); err == nil {
+		return clock, nil
+	}
+	normalized := timeparse.Normalize(value)
+	if _, err := strconv.Atoi(normalized); err != nil && !strings.Contains(normalized, ":") {
+		if _, err := timeparse.ParseDuration(normalized); err == nil {
+			return endTimeFromDuration(startTime, normalized)
+		}
+	}
+	return "", newInputError("終了時間の形式が正しくありません（例: 15:00、16時半、2時間）")
+}
+
+// endTimeFromDuration は利用時間の入力（例: "30m"、"1h30m"、"2時間"）から終了時間を HH:MM 形式で求める
+// startTime は HH:MM 形式に正規化済みであること
+func endTimeFromDuration(startTime, value string) (string, *inputError) {
+	duration, inputErr := parseDurationInput(value)
+	if inputErr != nil {
+		return "", inputErr
+	}
+	start, err := time.Parse("15:04", startTime)
+	if err != nil {
+		return "", newInputError("開始時間の形式が正しくありません（例: 14:00、15時半、3pm）")
+	}
+	return start.Add(duration).Format("15:04"), nil
+}
+
+// splitDateTimeInput は日付の入力に時刻も含まれている場合（例: "金曜 15時"）、開始時間が未入力であれば
+// その時刻を開始時間として使う（日付は YYYY-MM-DD 形式にする）
+func splitDateTimeInput(date, startTime string) (string, string) {
+	if date == "" || startTime != "" {
+		return date, startTime
+	}
+	result, err := timeparse.ParseDateTime(date, models.Now())
+	if err != nil || !result.HasDate || !result.HasTime {
+		return date, startTime
+	}
+	return result.Date.Format("2006-01-02"), result.Clock
+}
+
+// parseSlotInput は予約日時の入力を検証して正規化する
+// endTime が空の場合は開始時刻+1時間を終了時間とし、終了時刻が開始時刻より前の場合は翌日に終わる予約とする
+func parseSlotInput(date, startTime, endTime string) (slotInput, *inputError) {
+	reservationDate, inputErr := parseDateInput(date)
+	if inputErr != nil {
+		return slotInput{}, inputErr
+	}
+	date = reservationDate.Format("2006-01-02")
+
+	// 時刻を正規化（H:MM、15時半 → HH:MM）
+	startTime, inputErr = parseClockInput(startTime, "開始時間")
+	if inputErr != nil {
+		return slotInput{}, inputErr
+	}
+	startTimeParsed, _ := time.Parse("15:04", startTime)
+
+	if endTime != "" {
+		if endTime, inputErr = parseEndTimeInput(startTime, endTime); inputErr != nil {
+			return slotInput{}, inputErr
+		}
+	} else {
+		// 終了時間が指定されていない場合は開始時刻+1時間
+		endTime = startTimeParsed.Add(1 * time.Hour).Format("15:04")
+	}
+
+	// 終了時刻が開始時刻と同じ場合はエラー（開始時刻より前の場合は翌日に終わる予約とみなす）
+	if endTime == startTime {
+		return slotInput{}, &inputError{
+			Message: fmt.Sprintf("❌ 終了時刻は開始時刻と異なる時刻である必要があります\n\n"+
+				"**開始時刻:** %s\n"+
+				"**終了時刻:** %s\n\n"+
+				"日付を跨ぐ場合は、終了時刻に翌日の時刻（例: 02:00）を指定してください。",
+				startTime,
+				endTime,
+			),
+			Reason: "End time equals start time",
+		}
+	}
+
+	// 過去日時のチェック（日付 + 開始時刻を部室のタイムゾーンで解釈する）
+	now := models.Now()
+	reservationDateTime := time.Date(
+		reservationDate.Year(),
+		reservationDate.Month(),
+		reservationDate.Day(),
+		startTimeParsed.Hour(),
+		startTimeParsed.Minute(),
+		0, 0, models.Location(),
+	)
+	if reservationDateTime.Before(now) {
+		return slotInput{}, &inputError{
+			Message: fmt.Sprintf("❌ 過去の日時は予約できません\n\n"+
+				"**指定された日時:** %s %s\n"+
+				"**現在日時:** %s\n\n"+
+				"現在時刻以降の日時を指定してください。",
+				formatDate(date),
+				startTime,
+				now.Format("2006-01-02 15:04"),
+			),
+			Reason: "Past datetime",
+		}
+	}
+
+	return slotInput{
+		Date:      date,
+		EndDate:   models.ResolveEndDate(date, startTime, endTime),
+		StartTime: startTime,
+		EndTime:   endTime,
+	}, nil
+}
+
+// parseResourceOption は部屋の指定を部屋IDに変換する（省略時は既定の部屋）
+func parseResourceOption(value string) (string, *inputError) {
+	if value == "" {
+		return defaultResourceID(), nil
+	}
+	resource, found := findResource(value)
+	if !found {
+		return "", newInputError("指定された部屋が見つかりません。候補から選択してください。")
+	}
+	return resource.ID, nil
+}
+
+// parseEditSlotInput は編集後の予約日時の入力を検証して正規化する
+// 開始日時を変更しない場合は過去日時のチェックを行わない（開始済みの予約の終了時間やコメントも変更できるようにする）
+func parseEditSlotInput(r *models.Reservation, date, startTime, endTime string) (slotInput, *inputError) {
+	reservationDate, inputErr := parseDateInput(date)
+	if inputErr != nil {
+		return slotInput{}, inputErr
+	}
+	date = reservationDate.Format("2006-01-02")
+	if startTime, inputErr = parseClockInput(startTime, "開始時間"); inputErr != nil {
+		return slotInput{}, inputErr
+	}
+	if date != r.Date || startTime != r.StartTime {
+		return parseSlotInput(date, startTime, endTime)
+	}
+
+	if endTime, inputErr = parseEndTimeInput(startTime, endTime); inputErr != nil {
+		return slotInput{}, inputErr
+	}
+	if endTime == startTime {
+		return slotInput{}, newInputError("終了時間は開始時間と異なる時刻である必要があります。")
+	}
+	return slotInput{
+		Date:      date,
+		EndDate:   models.ResolveEndDate(date, startTime, endTime),
+		StartTime: startTime,
+		EndTime:   endTime,
+	}, nil
+}
+
+// parseDurationInput は利用時間の入力を解釈する（"60"、"90分"、"1:30"、"1時間30分"、"1.5h" など、数字のみの場合は分）
+func parseDurationInput(value string) (time.Duration, *inputError) {
+	duration, err := timeparse.ParseDuration(value)
+	if err != nil {
+		return 0, newInputError("利用時間の形式が正しくありません（例: 60、90分、1:30、1時間30分）")
+	}
+
+	if duration <= 0 || duration%time.Minute != 0 {
+		return 0, newInputError("利用時間は1分単位の正の長さで指定してください")
+	}
+	if duration >= 24*time.Hour {
+		return 0, newInputError("利用時間は24時間未満で指定してください")
+	}
+	return duration, nil
+}
@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// useClubTimezone はテスト中だけ部室のタイムゾーンとサーバーのローカルタイムゾーンを切り替える
+func useClubTimezone(t *testing.T, club, local *time.Location) {
+	t.Helper()
+	oldClub, oldLocal := models.Location(), time.Local
+	models.SetLocation(club)
+	time.Local = local
+	t.Cleanup(func() {
+		models.SetLocation(oldClub)
+		time.Local = oldLocal
+	})
+}
+
+func TestParseSlotInputPastCheckUsesClubTimezone(t *testing.T) {
+	useClubTimezone(t, time.FixedZone("UTC-10", -10*60*60), time.FixedZone("UTC+9", 9*60*60))
+
+	now := models.Now()
+	tests := []struct {
+		name     string
+		start    time.Time
+		wantPast bool
+	}{
+		{"one hour ago", now.Add(-time.Hour), true},
+		{"in one hour", now.Add(time.Hour), false},
+		{"in ten hours", now.Add(10 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, inputErr := parseSlotInput(tt.start.Format("2006/01/02"), tt.start.Format("15:04"), "")
+			gotPast := inputErr != nil && inputErr.Reason == "Past datetime"
+			if gotPast != tt.wantPast {
+				t.Errorf("past = %v, want %v (err: %v)", gotPast, tt.wantPast, inputErr)
+			}
+		})
+	}
+}
+
+func TestParseSlotInputOvernight(t *testing.T) {
+	date := models.Now().AddDate(0, 0, 7).Format("2006-01-02")
+	next := models.Now().AddDate(0, 0, 8).Format("2006-01-02")
+
+	slot, inputErr := parseSlotInput(date, "20:00", "2:00")
+	if inputErr != nil {
+		t.Fatalf("parseSlotInput failed: %v", inputErr)
+	}
+	if slot.EndDate != next || slot.EndTime != "02:00" {
+		t.Errorf("Expected overnight slot ending %s 02:00, got %s %s", next, slot.EndDate, slot.EndTime)
+	}
+
+	if _, inputErr := parseSlotInput(date, "20:00", "20:00"); inputErr == nil {
+		t.Error("Expected error when end time equals start time")
+	}
+}
+
+func TestParseEditSlotInputAllowsStartedReservation(t *testing.T) {
+	started := models.Now().Add(-time.Hour)
+	r := &models.Reservation{Date: started.Format("2006-01-02"), StartTime: started.Format("15:04")}
+
+	slot, inputErr := parseEditSlotInput(r, started.Format("2006/01/02"), r.StartTime, started.Add(3*time.Hour).Format("15:04"))
+	if inputErr != nil {
+		t.Fatalf("Expected the end time of a started reservation to be editable, got %v", inputErr)
+	}
+	if slot.EndTime != started.Add(3*time.Hour).Format("15:04") {
+		t.Errorf("Unexpected end time %s", slot.EndTime)
+	}
+
+	moved := started.Add(-time.Hour)
+	if _, inputErr := parseEditSlotInput(r, moved.Format("2006/01/02"), moved.Format("15:04"), started.Format("15:04")); inputErr == nil || inputErr.Reason != "Past datetime" {
+		t.Errorf("Expected moving the start into the past to be rejected, got %v", inputErr)
+	}
+}
+
+func TestParseDurationInput(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"60", time.Hour, false},
+		{"90分", 90 * time.Minute, false},
+		{"1:30", 90 * time.Minute, false},
+		{"1時間", time.Hour, false},
+		{"1時間30分", 90 * time.Minute, false},
+		{"1.5h", 90 * time.Minute, false},
+		{"2h 15m", 2*time.Hour + 15*time.Minute, false},
+		{"", 0, true},
+		{"0", 0, true},
+		{"1:75", 0, true},
+		{"24時間", 0, true},
+		{"長め", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, inputErr := parseDurationInput(tt.input)
+			if (inputErr != nil) != tt.wantErr {
+				t.Fatalf("parseDurationInput(%q) error = %v, wantErr %v", tt.input, inputErr, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseDurationInput(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndTimeFromDuration(t *testing.T) {
+	tests := []struct {
+		startTime, duration, want string
+	}{
+		{"14:00", "30m", "14:30"},
+		{"14:00", "1h30m", "15:30"},
+		{"14:00", "2時間", "16:00"},
+		{"14:00", "90", "15:30"},
+		{"23:00", "2h", "01:00"},
+	}
+	for _, tt := range tests {
+		got, inputErr := endTimeFromDuration(tt.startTime, tt.duration)
+		if inputErr != nil || got != tt.want {
+			t.Errorf("endTimeFromDuration(%q, %q) = %q, %v, want %q", tt.startTime, tt.duration, got, inputErr, tt.want)
+		}
+	}
+	if _, inputErr := endTimeFromDuration("14:00", "長め"); inputErr == nil {
+		t.Error("Expected an error for an invalid duration")
+	}
+}
+
+func TestParseSlotInputNaturalLanguage(t *testing.T) {
+	tomorrow := models.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	tests := []struct {
+		date, start, end string
+		wantStart        string
+		wantEnd          string
+	}{
+		{"明日", "15時半", "", "15:30", "16:30"},
+		{"tomorrow", "3pm", "5:30pm", "15:00", "17:30"},
+		{"明日", "午後3時", "2時間", "15:00", "17:00"},
+		{"明日", "22時", "90分", "22:00", "23:30"},
+	}
+	for _, tt := range tests {
+		slot, inputErr := parseSlotInput(tt.date, tt.start, tt.end)
+		if inputErr != nil {
+			t.Errorf("parseSlotInput(%q, %q, %q) failed: %v", tt.date, tt.start, tt.end, inputErr)
+			continue
+		}
+		if slot.Date != tomorrow || slot.StartTime != tt.wantStart || slot.EndTime != tt.wantEnd {
+			t.Errorf("parseSlotInput(%q, %q, %q) = %+v", tt.date, tt.start, tt.end, slot)
+		}
+	}
+
+	// 単位のない数字は長さではなく時刻として扱う
+	if _, inputErr := parseSlotInput(tomorrow, "10:00", "90"); inputErr == nil {
+		t.Error("Expected a bare number to be rejected as an end time")
+	}
+	if _, inputErr := parseSlotInput(tomorrow, "10:00", "25時間"); inputErr == nil {
+		t.Error("Expected a duration of 24 hours or more to be rejected")
+	}
+}
+
+func TestSplitDateTimeInput(t *testing.T) {
+	tomorrow := models.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	tests := []struct {
+		date, start         string
+		wantDate, wantStart string
+	}{
+		{"明日 15時", "", tomorrow, "15:00"},
+		{"tomorrow 3pm", "", tomorrow, "15:00"},
+		{"明日 15時", "10:00", "明日 15時", "10:00"}, // 開始時間の指定を優先する（日付はエラーになる）
+		{"明日", "", "明日", ""},
+		{"", "", "", ""},
+	}
+	for _, tt := range tests {
+		date, start := splitDateTimeInput(tt.date, tt.start)
+		if date != tt.wantDate || start != tt.wantStart {
+			t.Errorf("splitDateTimeInput(%q, %q) = %q, %q", tt.date, tt.start, date, start)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// resources は予約できる部屋・設備の一覧（先頭が既定の部屋）
+var resources = []models.Resource{models.DefaultResource()}
+
+// SetResources は予約できる部屋・設備の一覧を設定する
+func SetResources(list []models.Resource) {
+	if len(list) == 0 {
+		list = []models.Resource{models.DefaultResource()}
+	}
+	resources = list
+}
+
+// hasMultipleResources は部屋が複数設定されているかを返す
+func hasMultipleResources() bool {
+	return len(resources) > 1
+}
+
+// defaultResourceID は部屋の指定がない場合に使う部屋IDを返す
+func defaultResourceID() string {
+	for _, resource := range resources {
+		if resource.ID == models.DefaultResourceID {
+			return resource.ID
+		}
+	}
+	return resources[0].ID
+}
+
+// findResource は部屋IDまたは表示名から部屋を探す
+func findResource(idOrName string) (models.Resource, bool) {
+	for _, resource := range resources {
+		if resource.ID == idOrName || resource.Name == idOrName {
+			return resource, true
+		}
+	}
+	return models.Resource{}, false
+}
+
+// resourceName は部屋IDの表示名を返す（設定にない場合はIDをそのまま返す）
+func resourceName(id string) string {
+	if id == "" {
+		id = models.DefaultResourceID
+	}
+	if resource, ok := findResource(id); ok {
+		return resource.Name
+	}
+	return id
+}
+
+// notificationChannel は部屋の通知先チャンネルを返す（未設定の場合は fallback）
+func notificationChannel(resourceID, fallback string) string {
+	if resource, ok := findResource(resourceID); ok && resource.ChannelID != "" {
+		return resource.ChannelID
+	}
+	return fallback
+}
+
+// appendResourceField は部屋が複数ある場合に部屋のフィールドを追加する
+func appendResourceField(fields []*discordgo.MessageEmbedField, resourceID string) []*discordgo.MessageEmbedField {
+	if !hasMultipleResources() {
+		return fields
+	}
+	return append(fields, &discordgo.MessageEmbedField{
+		Name:   "🚪 部屋",
+		Value:  resourceName(resourceID),
+		Inline: true,
+	})
+}
+
+// getResourceSuggestions は部屋の候補を生成する
+func getResourceSuggestions(input string) []*discordgo.ApplicationCommandOptionChoice {
+	suggestions := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, resource := range resources {
+		if input != "" && !strings.Contains(resource.Name, input) && !strings.Contains(resource.ID, input) {
+			continue
+		}
+
+		name := resource.Name
+		if resource.Capacity > 0 {
+			name = fmt.Sprintf("%s (定員%d名)", name, resource.Capacity)
+		}
+		suggestions = append(suggestions, &discordgo.ApplicationCommandOptionChoice{
+			Name:  name,
+			Value: resource.ID,
+		})
+	}
+	return suggestions
+}
@@ -26,6 +26,15 @@ func respondError(s *discordgo.Session, i *discordgo.InteractionCreate, message
 	})
 }
 
+// resultResponseType は操作結果の応答の種類を返す
+// ボタンへの応答ではボタンが押されたメッセージを結果で置き換え、それ以外では新しいメッセージを送信する
+func resultResponseType(i *discordgo.InteractionCreate) discordgo.InteractionResponseType {
+	if i.Type == discordgo.InteractionMessageComponent {
+		return discordgo.InteractionResponseUpdateMessage
+	}
+	return discordgo.InteractionResponseChannelMessageWithSource
+}
+
 // respondEphemeral はエフェメラルメッセージを送信する
 func respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -96,55 +105,6 @@ func getUserInfo(i *discordgo.InteractionCreate, isDM bool) (userID, username st
 	return i.Member.User.ID, getDisplayName(i.Member)
 }
 
-// normalizeTime は時刻をHH:MM形式に正規化する（H:MM → HH:MM）
-func normalizeTime(timeStr string) string {
-	parts := strings.Split(timeStr, ":")
-	if len(parts) != 2 {
-		return timeStr
-	}
-
-	// 時と分を2桁にパディング
-	hour := parts[0]
-	minute := parts[1]
-
-	if len(hour) == 1 {
-		hour = "0" + hour
-	}
-	if len(minute) == 1 {
-		minute = "0" + minute
-	}
-
-	return hour + ":" + minute
-}
-
-// normalizeDate は日付をYYYY/MM/DD形式に正規化する
-func normalizeDate(dateStr string) string {
-	// /または-で分割
-	separator := "/"
-	if strings.Contains(dateStr, "-") {
-		separator = "-"
-	}
-
-	parts := strings.Split(dateStr, separator)
-	if len(parts) != 3 {
-		return dateStr
-	}
-
-	year := parts[0]
-	month := parts[1]
-	day := parts[2]
-
-	// 月と日を2桁にパディング
-	if len(month) == 1 {
-		month = "0" + month
-	}
-	if len(day) == 1 {
-		day = "0" + day
-	}
-
-	return year + "/" + month + "/" + day
-}
-
 // formatDate は日付をYYYY/MM/DD形式にフォーマットする
 func formatDate(date string) string {
 	parts := strings.Split(date, "-")
@@ -157,16 +117,53 @@ func formatDate(date string) string {
 	return fmt.Sprintf("%s/%s/%s", year, month, day)
 }
 
+// formatTimeRange は予約の時間帯を表示用の文字列にする（日を跨ぐ場合は終了時刻に「翌」や日付を付ける）
+func formatTimeRange(r *models.Reservation) string {
+	switch days := r.SpanDays(); {
+	case days <= 0:
+		return fmt.Sprintf("%s - %s", r.StartTime, r.EndTime)
+	case days == 1:
+		return fmt.Sprintf("%s - 翌%s", r.StartTime, r.EndTime)
+	default:
+		return fmt.Sprintf("%s - %s %s", r.StartTime, formatDate(r.GetEndDate()), r.EndTime)
+	}
+}
+
+// formatDateRange は予約日を表示用の文字列にする（日を跨ぐ場合は終了日も表示する）
+func formatDateRange(r *models.Reservation) string {
+	if r.SpanDays() <= 0 {
+		return formatDate(r.Date)
+	}
+	return fmt.Sprintf("%s 〜 %s", formatDate(r.Date), formatDate(r.GetEndDate()))
+}
+
 // getStatusEmoji はステータスに対応する絵文字を返す
 func getStatusEmoji(status models.ReservationStatus) string {
 	switch status {
 	case models.StatusPending:
 		return "📅"
+	case models.StatusCheckedIn:
+		return "🟣"
 	case models.StatusCompleted:
 		return "✅"
 	case models.StatusCancelled:
 		return "🚫"
+	case models.StatusNoShow:
+		return "👻"
 	default:
 		return "❓"
 	}
 }
+
+// formatDuration は時間の長さを「1時間30分」形式にする
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%d分", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%d時間", minutes/60)
+	default:
+		return fmt.Sprintf("%d時間%d分", minutes/60, minutes%60)
+	}
+}
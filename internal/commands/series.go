@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/dice/hxs_reservation_system/internal/models"
+	"github.com/dice/hxs_reservation_system/internal/storage"
+)
+
+// 繰り返し予約を編集・キャンセルする範囲
+const (
+	scopeThis      = "this"      // この予約のみ
+	scopeFollowing = "following" // この予約以降
+	scopeSeries    = "series"    // シリーズ全体
+)
+
+// embedFieldValueLimit は埋め込みフィールドの値の最大文字数
+const embedFieldValueLimit = 1024
+
+// getScopeOption は scope オプションの値を返す（省略時はこの予約のみ）
+func getScopeOption(optionMap map[string]*discordgo.ApplicationCommandInteractionDataOption) string {
+	if opt, ok := optionMap["scope"]; ok {
+		switch scope := opt.StringValue(); scope {
+		case scopeFollowing, scopeSeries:
+			return scope
+		}
+	}
+	return scopeThis
+}
+
+// scopeLabel は範囲の表示名を返す
+func scopeLabel(scope string) string {
+	switch scope {
+	case scopeFollowing:
+		return "この予約以降"
+	case scopeSeries:
+		return "シリーズ全体"
+	default:
+		return "この予約のみ"
+	}
+}
+
+// seriesTargets は scope に応じて操作対象となる予約中の予約を日時順に返す
+// 単発の予約や scopeThis の場合は reservation のみを返す
+func seriesTargets(store storage.Repository, reservation *models.Reservation, scope string) ([]*models.Reservation, error) {
+	if scope == scopeThis || !reservation.IsRecurring() {
+		return []*models.Reservation{reservation}, nil
+	}
+
+	occurrences, err := store.QueryReservations(storage.Query{
+		SeriesID: reservation.SeriesID,
+		Statuses: []models.ReservationStatus{models.StatusPending},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]*models.Reservation, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		if scope == scopeFollowing && occurrenceKey(occurrence) < occurrenceKey(reservation) {
+			continue
+		}
+		targets = append(targets, occurrence)
+	}
+
+	sort.Slice(targets, func(a, b int) bool {
+		return occurrenceKey(targets[a]) < occurrenceKey(targets[b])
+	})
+	return targets, nil
+}
+
+// occurrenceKey は予約を日時順に並べるためのキーを返す
+func occurrenceKey(r *models.Reservation) string {
+	return r.Date + " " + r.StartTime
+}
+
+// formatOccurrence は予約の日時を1行で表示する
+func formatOccurrence(r *models.Reservation) string {
+	return fmt.Sprintf("%s %s", formatDate(r.Date), formatTimeRange(r))
+}
+
+// formatRecurrence は繰り返しルールを表示用の文字列にする
+func formatRecurrence(rule *models.Recurrence) string {
+	if rule == nil {
+		return "なし"
+	}
+	text := rule.Frequency.Label()
+	if rule.Until != "" {
+		text += fmt.Sprintf("（%s まで）", formatDate(rule.Until))
+	}
+	if rule.Count > 0 {
+		text += fmt.Sprintf("（%d回）", rule.Count)
+	}
+	return text
+}
+
+// joinLines は行を改行で連結し、埋め込みフィールドに収まらない分は件数のみ表示する
+func joinLines(lines []string) string {
+	var builder strings.Builder
+	for idx, line := range lines {
+		more := fmt.Sprintf("…ほか%d件", len(lines)-idx)
+		if builder.Len()+len(line)+1 > embedFieldValueLimit-len(more)-1 {
+			builder.WriteString(more)
+			break
+		}
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
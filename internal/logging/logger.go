@@ -31,6 +31,17 @@ type ErrorLog struct {
 	Details   map[string]interface{} `json:"details,omitempty"`
 }
 
+// AdminActionLog は管理者が他のユーザーの予約を操作した記録の構造体
+type AdminActionLog struct {
+	Timestamp     time.Time              `json:"timestamp"`
+	Action        string                 `json:"action"` // 実行したコマンド（cancel, complete, edit など）
+	AdminID       string                 `json:"admin_id"`
+	AdminName     string                 `json:"admin_name"`
+	ReservationID string                 `json:"reservation_id"`
+	OwnerID       string                 `json:"owner_id"` // 予約者のDiscord ID
+	Details       map[string]interface{} `json:"details,omitempty"`
+}
+
 // CommandStats はコマンド統計の構造体
 type CommandStats struct {
 	TotalCommands int                    `json:"total_commands"`
@@ -56,6 +67,7 @@ type Logger struct {
 	currentMonth string
 	monthlyFile  string
 	errorFile    string
+	adminFile    string
 	stats        *CommandStats
 	mutex        sync.RWMutex
 }
@@ -70,6 +82,7 @@ func NewLogger(logDir string) *Logger {
 	currentMonth := time.Now().Format("2006-01")
 	monthlyFile := filepath.Join(logDir, fmt.Sprintf("commands_%s.log", currentMonth))
 	errorFile := filepath.Join(logDir, fmt.Sprintf("errors_%s.log", currentMonth))
+	adminFile := filepath.Join(logDir, fmt.Sprintf("admin_%s.log", currentMonth))
 
 	logger := &Logger{
 		logDir:       logDir,
@@ -77,6 +90,7 @@ func NewLogger(logDir string) *Logger {
 		currentMonth: currentMonth,
 		monthlyFile:  monthlyFile,
 		errorFile:    errorFile,
+		adminFile:    adminFile,
 		stats: &CommandStats{
 			TotalCommands: 0,
 			CommandCounts: make(map[string]int),
@@ -222,11 +236,52 @@ func (l *Logger) writeToErrorLog(entry ErrorLog) {
 	file.WriteString("\n")
 }
 
+// LogAdminAction は管理者が他のユーザーの予約を操作したことを記録する
+// 管理者操作の記録は監査用のため、CleanupOldLogs では削除しない
+func (l *Logger) LogAdminAction(action, adminID, adminName, reservationID, ownerID string, details map[string]interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// 月が変わった場合はローテーション
+	currentMonth := time.Now().Format("2006-01")
+	if l.currentMonth != currentMonth {
+		l.rotateLogs()
+		l.currentMonth = currentMonth
+	}
+
+	entry := AdminActionLog{
+		Timestamp:     time.Now(),
+		Action:        action,
+		AdminID:       adminID,
+		AdminName:     adminName,
+		ReservationID: reservationID,
+		OwnerID:       ownerID,
+		Details:       details,
+	}
+
+	file, err := os.OpenFile(l.adminFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Printf("Failed to open admin log file: %v\n", err)
+		return
+	}
+	defer file.Close()
+
+	jsonData, err := json.Marshal(entry)
+	if err != nil {
+		fmt.Printf("Failed to marshal admin log entry: %v\n", err)
+		return
+	}
+
+	file.Write(jsonData)
+	file.WriteString("\n")
+}
+
 // rotateLogs は月次ログローテーションを実行する
 func (l *Logger) rotateLogs() {
 	// 新しい月次ファイル名を設定
 	l.monthlyFile = filepath.Join(l.logDir, fmt.Sprintf("commands_%s.log", l.currentMonth))
 	l.errorFile = filepath.Join(l.logDir, fmt.Sprintf("errors_%s.log", l.currentMonth))
+	l.adminFile = filepath.Join(l.logDir, fmt.Sprintf("admin_%s.log", l.currentMonth))
 }
 
 // loadStats は既存の統計ファイルを読み込む
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultOpeningHours は設定がない場合の部室の利用可能時間
+var DefaultOpeningHours = OpeningHours{Open: "09:00", Close: "22:00"}
+
+// OpeningHours は空き時間を探す対象となる1日の利用可能時間（HH:MM形式、Close は 24:00 も可）
+type OpeningHours struct {
+	Open  string
+	Close string
+}
+
+// ParseOpeningHours は "09:00-22:00" 形式の利用可能時間を解釈する（空の場合は既定の利用可能時間）
+func ParseOpeningHours(value string) (OpeningHours, error) {
+	if value == "" {
+		return DefaultOpeningHours, nil
+	}
+	open, closing, found := strings.Cut(value, "-")
+	if !found {
+		return OpeningHours{}, fmt.Errorf("invalid opening hours %q: expected HH:MM-HH:MM", value)
+	}
+	hours := OpeningHours{Open: strings.TrimSpace(open), Close: strings.TrimSpace(closing)}
+
+	openMinutes, err := clockMinutes(hours.Open)
+	if err != nil {
+		return OpeningHours{}, fmt.Errorf("invalid opening time %q: %w", hours.Open, err)
+	}
+	closeMinutes, err := clockMinutes(hours.Close)
+	if err != nil {
+		return OpeningHours{}, fmt.Errorf("invalid closing time %q: %w", hours.Close, err)
+	}
+	if closeMinutes <= openMinutes {
+		return OpeningHours{}, fmt.Errorf("invalid opening hours %q: closing time must be after opening time", value)
+	}
+	return hours, nil
+}
+
+// String は利用可能時間を "09:00-22:00" 形式で返す
+func (h OpeningHours) String() string {
+	return h.Open + "-" + h.Close
+}
+
+// Window は指定した日（YYYY-MM-DD形式）の利用可能時間を部室のタイムゾーンの時刻で返す
+func (h OpeningHours) Window(date string) (TimeSlot, error) {
+	day, err := time.ParseInLocation("2006-01-02", date, location)
+	if err != nil {
+		return TimeSlot{}, err
+	}
+	openMinutes, err := clockMinutes(h.Open)
+	if err != nil {
+		return TimeSlot{}, err
+	}
+	closeMinutes, err := clockMinutes(h.Close)
+	if err != nil {
+		return TimeSlot{}, err
+	}
+	return TimeSlot{
+		Start: day.Add(time.Duration(openMinutes) * time.Minute),
+		End:   day.Add(time.Duration(closeMinutes) * time.Minute),
+	}, nil
+}
+
+// clockMinutes は HH:MM 形式の時刻を0時からの分数に変換する（24:00 は1440分）
+func clockMinutes(clock string) (int, error) {
+	if clock == "24:00" {
+		return 24 * 60, nil
+	}
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// TimeSlot は開始から終了までの時間帯を表す
+type TimeSlot struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration は時間帯の長さを返す
+func (t TimeSlot) Duration() time.Duration {
+	return t.End.Sub(t.Start)
+}
+
+// BusySlots は有効な（予約中・利用中の）予約が埋めている時間帯を開始時刻順に返す
+func BusySlots(reservations []*Reservation) []TimeSlot {
+	busy := make([]TimeSlot, 0, len(reservations))
+	for _, r := range reservations {
+		if !r.Status.IsActive() {
+			continue
+		}
+		start, err := r.GetStartDateTime()
+		if err != nil {
+			continue
+		}
+		end, err := r.GetEndDateTime()
+		if err != nil {
+			continue
+		}
+		busy = append(busy, TimeSlot{Start: start, End: end})
+	}
+	sort.Slice(busy, func(a, b int) bool {
+		return busy[a].Start.Before(busy[b].Start)
+	})
+	return busy
+}
+
+// FreeSlots は window のうち busy に含まれない、minDuration 以上の時間帯を返す
+// busy は開始時刻順に並んでいる必要がある
+func FreeSlots(window TimeSlot, busy []TimeSlot, minDuration time.Duration) []TimeSlot {
+	free := []TimeSlot{}
+	cursor := window.Start
+	for _, b := range busy {
+		if !b.End.After(cursor) {
+			continue
+		}
+		if !b.Start.Before(window.End) {
+			break
+		}
+		if b.Start.After(cursor) {
+			appendFreeSlot(&free, TimeSlot{Start: cursor, End: b.Start}, minDuration)
+		}
+		cursor = b.End
+	}
+	if cursor.Before(window.End) {
+		appendFreeSlot(&free, TimeSlot{Start: cursor, End: window.End}, minDuration)
+	}
+	return free
+}
+
+// appendFreeSlot は minDuration 以上の長さがある場合に空き時間を追加する
+func appendFreeSlot(free *[]TimeSlot, slot TimeSlot, minDuration time.Duration) {
+	if slot.Duration() > 0 && slot.Duration() >= minDuration {
+		*free = append(*free, slot)
+	}
+}
+
+// NearestSlots は空き時間ごとに、length の長さで開始時刻が target に最も近い時間帯を1つずつ返す
+// 開始時刻は step 単位にそろえ、空き時間に収まらない場合は候補にしない
+func NearestSlots(free []TimeSlot, length time.Duration, target time.Time, step time.Duration) []TimeSlot {
+	candidates := []TimeSlot{}
+	for _, slot := range free {
+		earliest := slot.Start.Add(step - 1).Truncate(step)
+		latest := slot.End.Add(-length).Truncate(step)
+		if latest.Before(earliest) {
+			continue
+		}
+		start := target.Round(step)
+		if start.Before(earliest) {
+			start = earliest
+		}
+		if start.After(latest) {
+			start = latest
+		}
+		candidates = append(candidates, TimeSlot{Start: start, End: start.Add(length)})
+	}
+	return candidates
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseOpeningHours(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    OpeningHours
+		wantErr bool
+	}{
+		{"", DefaultOpeningHours, false},
+		{"08:30-21:00", OpeningHours{Open: "08:30", Close: "21:00"}, false},
+		{" 10:00 - 24:00 ", OpeningHours{Open: "10:00", Close: "24:00"}, false},
+		{"22:00-09:00", OpeningHours{}, true},
+		{"09:00", OpeningHours{}, true},
+		{"9時-22時", OpeningHours{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseOpeningHours(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeSlots(t *testing.T) {
+	window, err := OpeningHours{Open: "09:00", Close: "24:00"}.Window("2030-01-10")
+	if err != nil {
+		t.Fatalf("Window failed: %v", err)
+	}
+
+	busy := BusySlots([]*Reservation{
+		// 前日から日を跨いで 10:00 まで
+		{Date: "2030-01-09", EndDate: "2030-01-10", StartTime: "22:00", EndTime: "10:00", Status: StatusPending},
+		{Date: "2030-01-10", StartTime: "12:00", EndTime: "12:20", Status: StatusPending},
+		{Date: "2030-01-10", StartTime: "12:10", EndTime: "13:00", Status: StatusPending},
+		// キャンセル済みの予約は空きとして扱う
+		{Date: "2030-01-10", StartTime: "15:00", EndTime: "18:00", Status: StatusCancelled},
+		// 13:00〜13:20 の隙間は最短時間に満たない
+		{Date: "2030-01-10", StartTime: "13:20", EndTime: "14:00", Status: StatusPending},
+		{Date: "2030-01-10", StartTime: "23:00", EndTime: "01:00", EndDate: "2030-01-11", Status: StatusPending},
+	})
+
+	got := FreeSlots(window, busy, 30*time.Minute)
+	want := [][2]string{{"10:00", "12:00"}, {"14:00", "23:00"}}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d slots, got %+v", len(want), got)
+	}
+	for idx, slot := range got {
+		if slot.Start.Format("15:04") != want[idx][0] || slot.End.Format("15:04") != want[idx][1] {
+			t.Errorf("slot %d: got %s-%s, want %s-%s", idx, slot.Start.Format("15:04"), slot.End.Format("15:04"), want[idx][0], want[idx][1])
+		}
+	}
+}
+
+func TestNearestSlots(t *testing.T) {
+	window, _ := OpeningHours{Open: "09:00", Close: "22:00"}.Window("2030-01-10")
+	busy := BusySlots([]*Reservation{
+		{Date: "2030-01-10", StartTime: "12:00", EndTime: "15:10", Status: StatusPending},
+		{Date: "2030-01-10", StartTime: "17:00", EndTime: "18:00", Status: StatusPending},
+	})
+	free := FreeSlots(window, busy, 0)
+	target, _ := parseDateTime("2030-01-10", "13:00")
+
+	got := NearestSlots(free, 2*time.Hour, target, 15*time.Minute)
+	// 15:15〜17:00 は2時間に満たないため候補にならない
+	want := [][2]string{{"10:00", "12:00"}, {"18:00", "20:00"}}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d candidates, got %+v", len(want), got)
+	}
+	for idx, slot := range got {
+		if slot.Start.Format("15:04") != want[idx][0] || slot.End.Format("15:04") != want[idx][1] {
+			t.Errorf("candidate %d: got %s-%s, want %s-%s", idx, slot.Start.Format("15:04"), slot.End.Format("15:04"), want[idx][0], want[idx][1])
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package models
+
+import "time"
+
+// HistoryAction は予約の変更履歴の操作の種類を表す
+type HistoryAction string
+
+const (
+	HistoryCreated   HistoryAction = "created"    // 作成
+	HistoryEdited    HistoryAction = "edited"     // 編集
+	HistoryCancelled HistoryAction = "cancelled"  // 取り消し
+	HistoryCompleted HistoryAction = "completed"  // 完了
+	HistoryCheckedIn HistoryAction = "checked_in" // チェックイン
+	HistoryNoShow    HistoryAction = "no_show"    // 無断欠席として解放
+)
+
+// Label は操作の表示名を返す
+func (a HistoryAction) Label() string {
+	switch a {
+	case HistoryCreated:
+		return "作成"
+	case HistoryEdited:
+		return "編集"
+	case HistoryCancelled:
+		return "取り消し"
+	case HistoryCompleted:
+		return "完了"
+	case HistoryCheckedIn:
+		return "チェックイン"
+	case HistoryNoShow:
+		return "無断欠席で解放"
+	default:
+		return string(a)
+	}
+}
+
+// 変更履歴の Source に使う、コマンド以外の変更元
+const (
+	SourceAutoComplete = "job:auto-complete" // 期限切れ予約の自動完了
+	SourceNoShow       = "job:no-show"       // チェックインのない予約の自動解放
+)
+
+// FieldChange は1つの項目の変更前後の値を表す
+type FieldChange struct {
+	Field string `json:"field"` // 項目名（date, start_time など JSON のキー）
+	From  string `json:"from"`  // 変更前の値
+	To    string `json:"to"`    // 変更後の値
+}
+
+// HistoryEntry は予約の変更履歴の1件を表す（追記のみで、記録後は変更しない）
+type HistoryEntry struct {
+	At        time.Time     `json:"at"`                   // 変更日時
+	ActorID   string        `json:"actor_id,omitempty"`   // 変更したユーザーのDiscord ID（自動処理の場合は空）
+	ActorName string        `json:"actor_name,omitempty"` // 変更したユーザーの表示名
+	Source    string        `json:"source"`               // 変更元（command:edit、job:auto-complete など）
+	Action    HistoryAction `json:"action"`               // 操作の種類
+	Changes   []FieldChange `json:"changes,omitempty"`    // 変更された項目
+	Note      string        `json:"note,omitempty"`       // 取り消し理由などのコメント
+}
+
+// CommandSource はスラッシュコマンドによる変更の Source を返す
+func CommandSource(command string) string {
+	return "command:" + command
+}
+
+// ButtonSource はメッセージのボタンによる変更の Source を返す
+func ButtonSource(action string) string {
+	return "button:" + action
+}
+
+// IsSystem は自動処理による変更であるかを返す
+func (e HistoryEntry) IsSystem() bool {
+	return e.ActorID == ""
+}
+
+// clone は変更履歴のコピーを返す
+func (e HistoryEntry) clone() HistoryEntry {
+	if e.Changes != nil {
+		e.Changes = append([]FieldChange(nil), e.Changes...)
+	}
+	return e
+}
+
+// RecordHistory は予約の変更履歴に1件追記する
+// 変更された項目は before と現在の予約の差分から求める（作成時は before に nil を渡す）
+func (r *Reservation) RecordHistory(before *Reservation, entry HistoryEntry) {
+	if entry.At.IsZero() {
+		entry.At = time.Now()
+	}
+	if before != nil && entry.Changes == nil {
+		entry.Changes = DiffReservations(before, r)
+	}
+	r.History = append(r.History, entry)
+}
+
+// DiffReservations は予約の変更された項目を返す
+func DiffReservations(before, after *Reservation) []FieldChange {
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"date", before.Date, after.Date},
+		{"end_date", before.GetEndDate(), after.GetEndDate()},
+		{"start_time", before.StartTime, after.StartTime},
+		{"end_time", before.EndTime, after.EndTime},
+		{"resource_id", before.GetResourceID(), after.GetResourceID()},
+		{"comment", before.Comment, after.Comment},
+		{"status", string(before.Status), string(after.Status)},
+	}
+
+	var changes []FieldChange
+	for _, f := range fields {
+		if f.from != f.to {
+			changes = append(changes, FieldChange{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,42 @@
+package models
+
+import "testing"
+
+func TestDiffReservations(t *testing.T) {
+	before := &Reservation{Date: "2030-01-10", StartTime: "10:00", EndTime: "11:00", Status: StatusPending}
+	after := before.Clone()
+	after.Date = "2030-01-11"
+	after.EndTime = "12:00"
+
+	changes := DiffReservations(before, after)
+	want := []FieldChange{
+		{Field: "date", From: "2030-01-10", To: "2030-01-11"},
+		{Field: "end_date", From: "2030-01-10", To: "2030-01-11"},
+		{Field: "end_time", From: "11:00", To: "12:00"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d: expected %+v, got %+v", i, want[i], changes[i])
+		}
+	}
+}
+
+func TestCloneCopiesHistory(t *testing.T) {
+	r := &Reservation{Status: StatusPending}
+	r.RecordHistory(nil, HistoryEntry{Source: CommandSource("reserve"), Action: HistoryCreated})
+	r.History[0].Changes = []FieldChange{{Field: "comment", From: "", To: "a"}}
+
+	clone := r.Clone()
+	clone.History[0].Changes[0].To = "b"
+	clone.RecordHistory(r, HistoryEntry{Source: CommandSource("edit"), Action: HistoryEdited})
+
+	if len(r.History) != 1 || r.History[0].Changes[0].To != "a" {
+		t.Errorf("Changing the clone modified the original history: %+v", r.History)
+	}
+	if r.History[0].At.IsZero() {
+		t.Error("Expected RecordHistory to set the timestamp")
+	}
+}
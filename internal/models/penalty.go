@@ -0,0 +1,108 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// StrikeKind は違反の種類
+type StrikeKind string
+
+const (
+	StrikeNoShow     StrikeKind = "no_show"     // チェックインのない無断欠席
+	StrikeLateCancel StrikeKind = "late_cancel" // 開始直前の取り消し
+)
+
+// strikeKindLabels は違反の種類の表示名
+var strikeKindLabels = map[StrikeKind]string{
+	StrikeNoShow:     "無断欠席",
+	StrikeLateCancel: "直前の取り消し",
+}
+
+// Label は違反の種類の表示名を返す
+func (k StrikeKind) Label() string {
+	if label, ok := strikeKindLabels[k]; ok {
+		return label
+	}
+	return string(k)
+}
+
+// Strike はユーザーの違反1件の記録
+type Strike struct {
+	At            time.Time  `json:"at"`             // 違反が記録された日時
+	Kind          StrikeKind `json:"kind"`           // 違反の種類
+	ReservationID string     `json:"reservation_id"` // 対象の予約ID
+}
+
+// PenaltyPolicy は違反の数え方と、違反が続いたユーザーへの予約の制限を表す
+type PenaltyPolicy struct {
+	LateCancelWithin time.Duration // 開始のこの時間前以降の取り消しを違反にする（0の場合は数えない）
+	Threshold        int           // Window 内の違反がこの回数に達すると予約を制限する（0の場合は制限しない）
+	Window           time.Duration // 違反を数える期間
+	RestrictFor      time.Duration // 制限の期間（回数に達した違反の日時から）
+	MaxActive        int           // 制限中に同時に持てる予約中・利用中の予約の数
+}
+
+// DefaultPenaltyPolicy は違反と制限の既定の設定
+// 開始3時間以内の取り消しと無断欠席を違反とし、30日間に3回で2週間、予約を1件までに制限する
+var DefaultPenaltyPolicy = PenaltyPolicy{
+	LateCancelWithin: 3 * time.Hour,
+	Threshold:        3,
+	Window:           30 * 24 * time.Hour,
+	RestrictFor:      14 * 24 * time.Hour,
+	MaxActive:        1,
+}
+
+// IsLateCancel は予約中の予約を at の時点で取り消すと直前の取り消しになるかを返す
+func (p PenaltyPolicy) IsLateCancel(r *Reservation, at time.Time) bool {
+	if r.Status != StatusPending || p.LateCancelWithin <= 0 {
+		return false
+	}
+	start, err := r.GetStartDateTime()
+	if err != nil {
+		return false
+	}
+	end, err := r.GetEndDateTime()
+	if err != nil {
+		return false
+	}
+	return !at.Before(start.Add(-p.LateCancelWithin)) && at.Before(end)
+}
+
+// RecentStrikes は now の時点で数える期間内の違反を新しい順に返す
+func (p PenaltyPolicy) RecentStrikes(strikes []Strike, now time.Time) []Strike {
+	recent := []Strike{}
+	for _, s := range strikes {
+		if s.At.After(now.Add(-p.Window)) && !s.At.After(now) {
+			recent = append(recent, s)
+		}
+	}
+	sort.SliceStable(recent, func(a, b int) bool {
+		return recent[a].At.After(recent[b].At)
+	})
+	return recent
+}
+
+// RestrictedUntil は違反の記録から予約の制限が終わる日時を返す（制限されたことがない場合はゼロ値）
+// 違反のたびに、その時点から数える期間内の違反が回数に達していれば、その日時から制限の期間だけ制限する
+func (p PenaltyPolicy) RestrictedUntil(strikes []Strike) time.Time {
+	var until time.Time
+	if p.Threshold <= 0 {
+		return until
+	}
+	for _, s := range strikes {
+		if len(p.RecentStrikes(strikes, s.At)) < p.Threshold {
+			continue
+		}
+		if end := s.At.Add(p.RestrictFor); end.After(until) {
+			until = end
+		}
+	}
+	return until
+}
+
+// Restricted は now の時点で予約が制限されているかと、制限が終わる日時を返す
+func (p PenaltyPolicy) Restricted(strikes []Strike, now time.Time) (time.Time, bool) {
+	until := p.RestrictedUntil(strikes)
+	return until, now.Before(until)
+}
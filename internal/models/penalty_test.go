@@ -0,0 +1,73 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsLateCancel(t *testing.T) {
+	policy := DefaultPenaltyPolicy
+	start := time.Date(2030, 1, 10, 14, 0, 0, 0, location)
+	tests := []struct {
+		name   string
+		status ReservationStatus
+		at     time.Time
+		want   bool
+	}{
+		{"well in advance", StatusPending, start.Add(-3*time.Hour - time.Minute), false},
+		{"within the window", StatusPending, start.Add(-3 * time.Hour), true},
+		{"after start", StatusPending, start.Add(30 * time.Minute), true},
+		{"after end", StatusPending, start.Add(time.Hour), false},
+		{"checked in", StatusCheckedIn, start.Add(30 * time.Minute), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newOvernightTestReservation("2030-01-10", "14:00", "15:00")
+			r.Status = tt.status
+			if got := policy.IsLateCancel(r, tt.at); got != tt.want {
+				t.Errorf("IsLateCancel(%s) = %v, want %v", tt.at.Format("15:04"), got, tt.want)
+			}
+		})
+	}
+
+	policy.LateCancelWithin = 0
+	if policy.IsLateCancel(newOvernightTestReservation("2030-01-10", "14:00", "15:00"), start) {
+		t.Error("IsLateCancel should be disabled when LateCancelWithin is 0")
+	}
+}
+
+func TestPenaltyRestriction(t *testing.T) {
+	policy := DefaultPenaltyPolicy
+	day := func(d int) time.Time { return time.Date(2030, 1, d, 12, 0, 0, 0, location) }
+	strikes := []Strike{
+		{At: day(1), Kind: StrikeNoShow, ReservationID: "a"},
+		{At: day(5), Kind: StrikeLateCancel, ReservationID: "b"},
+	}
+
+	if _, restricted := policy.Restricted(strikes, day(6)); restricted {
+		t.Error("Two strikes should not restrict reservations")
+	}
+	if got := policy.RecentStrikes(strikes, day(6)); len(got) != 2 || got[0].ReservationID != "b" {
+		t.Errorf("Expected both strikes newest first, got %+v", got)
+	}
+
+	strikes = append(strikes, Strike{At: day(20), Kind: StrikeNoShow, ReservationID: "c"})
+	until, restricted := policy.Restricted(strikes, day(21))
+	if !restricted || !until.Equal(day(20).Add(14*24*time.Hour)) {
+		t.Errorf("Expected a restriction for two weeks from the third strike, got %v (%v)", until, restricted)
+	}
+	if _, restricted := policy.Restricted(strikes, day(20).Add(15*24*time.Hour)); restricted {
+		t.Error("The restriction should end after two weeks")
+	}
+
+	// 期間外の違反は数えない
+	spread := []Strike{strikes[0], strikes[1], {At: day(1).Add(31 * 24 * time.Hour), Kind: StrikeNoShow, ReservationID: "d"}}
+	if _, restricted := policy.Restricted(spread, spread[2].At); restricted {
+		t.Error("Strikes outside the window should not count")
+	}
+
+	policy.Threshold = 0
+	if _, restricted := policy.Restricted(strikes, day(21)); restricted {
+		t.Error("Restrictions should be disabled when Threshold is 0")
+	}
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// RecurrenceFrequency は繰り返しの間隔を表す
+type RecurrenceFrequency string
+
+const (
+	FrequencyWeekly   RecurrenceFrequency = "weekly"   // 毎週
+	FrequencyBiweekly RecurrenceFrequency = "biweekly" // 隔週
+	FrequencyMonthly  RecurrenceFrequency = "monthly"  // 毎月（同じ日付）
+)
+
+// MaxOccurrences は1つの繰り返し予約で作成できる最大回数
+const MaxOccurrences = 52
+
+// Recurrence は繰り返し予約のルールを表す構造体
+type Recurrence struct {
+	Frequency RecurrenceFrequency `json:"frequency"`       // 繰り返しの間隔
+	Until     string              `json:"until,omitempty"` // 終了日（YYYY-MM-DD形式、この日を含む）
+	Count     int                 `json:"count,omitempty"` // 回数（Until と併用した場合は先に到達した方で終了）
+}
+
+// Label は繰り返し間隔の表示名を返す
+func (f RecurrenceFrequency) Label() string {
+	switch f {
+	case FrequencyWeekly:
+		return "毎週"
+	case FrequencyBiweekly:
+		return "隔週"
+	case FrequencyMonthly:
+		return "毎月"
+	default:
+		return string(f)
+	}
+}
+
+// Validate は繰り返しルールが正しいかを検証する
+func (rule Recurrence) Validate() error {
+	switch rule.Frequency {
+	case FrequencyWeekly, FrequencyBiweekly, FrequencyMonthly:
+	default:
+		return errors.New("unknown recurrence frequency")
+	}
+	if rule.Until == "" && rule.Count <= 0 {
+		return errors.New("recurrence needs an end date or an occurrence count")
+	}
+	if rule.Count < 0 || rule.Count > MaxOccurrences {
+		return errors.New("recurrence count is out of range")
+	}
+	if rule.Until != "" {
+		if _, err := time.Parse("2006-01-02", rule.Until); err != nil {
+			return errors.New("recurrence end date must be YYYY-MM-DD")
+		}
+	}
+	return nil
+}
+
+// Occurrences は first（最初の予約日）から始まる予約日を YYYY-MM-DD 形式で返す
+// 最大 MaxOccurrences 件まで。毎月の場合、その日付が存在しない月（31日など）は飛ばす
+func (rule Recurrence) Occurrences(first string) ([]string, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	start, err := time.Parse("2006-01-02", first)
+	if err != nil {
+		return nil, err
+	}
+
+	var until time.Time
+	if rule.Until != "" {
+		until, _ = time.Parse("2006-01-02", rule.Until)
+		if until.Before(start) {
+			return nil, errors.New("recurrence end date is before the first occurrence")
+		}
+	}
+
+	limit := MaxOccurrences
+	if rule.Count > 0 {
+		limit = rule.Count
+	}
+
+	dates := make([]string, 0, limit)
+	for step := 0; len(dates) < limit && step < MaxOccurrences*2; step++ {
+		var date time.Time
+		switch rule.Frequency {
+		case FrequencyWeekly:
+			date = start.AddDate(0, 0, 7*step)
+		case FrequencyBiweekly:
+			date = start.AddDate(0, 0, 14*step)
+		case FrequencyMonthly:
+			date = start.AddDate(0, step, 0)
+			// AddDateは存在しない日付を翌月に繰り越すため、日が変わった月は飛ばす
+			if date.Day() != start.Day() {
+				continue
+			}
+		}
+
+		if !until.IsZero() && date.After(until) {
+			break
+		}
+		dates = append(dates, date.Format("2006-01-02"))
+	}
+
+	return dates, nil
+}
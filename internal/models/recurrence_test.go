@@ -0,0 +1,79 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecurrenceOccurrences(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  Recurrence
+		first string
+		want  []string
+	}{
+		{
+			name:  "weekly by count",
+			rule:  Recurrence{Frequency: FrequencyWeekly, Count: 3},
+			first: "2030-01-10",
+			want:  []string{"2030-01-10", "2030-01-17", "2030-01-24"},
+		},
+		{
+			name:  "biweekly until inclusive",
+			rule:  Recurrence{Frequency: FrequencyBiweekly, Until: "2030-02-07"},
+			first: "2030-01-10",
+			want:  []string{"2030-01-10", "2030-01-24", "2030-02-07"},
+		},
+		{
+			name:  "monthly skips short months",
+			rule:  Recurrence{Frequency: FrequencyMonthly, Count: 3},
+			first: "2030-01-31",
+			want:  []string{"2030-01-31", "2030-03-31", "2030-05-31"},
+		},
+		{
+			name:  "count and until stop at whichever comes first",
+			rule:  Recurrence{Frequency: FrequencyWeekly, Count: 10, Until: "2030-01-17"},
+			first: "2030-01-10",
+			want:  []string{"2030-01-10", "2030-01-17"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.Occurrences(tt.first)
+			if err != nil {
+				t.Fatalf("Occurrences failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Occurrences = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceValidate(t *testing.T) {
+	invalid := []Recurrence{
+		{Frequency: "daily", Count: 2},
+		{Frequency: FrequencyWeekly},
+		{Frequency: FrequencyWeekly, Count: MaxOccurrences + 1},
+		{Frequency: FrequencyWeekly, Until: "2030/01/10"},
+	}
+	for _, rule := range invalid {
+		if err := rule.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", rule)
+		}
+	}
+
+	if _, err := (Recurrence{Frequency: FrequencyWeekly, Until: "2030-01-01"}).Occurrences("2030-01-10"); err == nil {
+		t.Error("Expected error when end date is before the first occurrence")
+	}
+}
+
+func TestCloneCopiesRecurrence(t *testing.T) {
+	original := &Reservation{ID: "r", SeriesID: "s", Recurrence: &Recurrence{Frequency: FrequencyWeekly, Count: 2}}
+	clone := original.Clone()
+	clone.Recurrence.Count = 5
+	if original.Recurrence.Count != 2 {
+		t.Error("Clone should not share the recurrence rule")
+	}
+}
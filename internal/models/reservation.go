@@ -10,24 +10,43 @@ import (
 type ReservationStatus string
 
 const (
-	StatusPending   ReservationStatus = "pending"   // 予約中
-	StatusCompleted ReservationStatus = "completed" // 完了
-	StatusCancelled ReservationStatus = "cancelled" // キャンセル済み
+	StatusPending   ReservationStatus = "pending"    // 予約中
+	StatusCheckedIn ReservationStatus = "checked_in" // 利用中（チェックイン済み）
+	StatusCompleted ReservationStatus = "completed"  // 完了
+	StatusCancelled ReservationStatus = "cancelled"  // キャンセル済み
+	StatusNoShow    ReservationStatus = "no_show"    // 無断欠席（チェックインがなく解放された）
 )
 
+// ActiveStatuses は部屋を使う予定がある（時間帯を埋めている）予約の状態
+var ActiveStatuses = []ReservationStatus{StatusPending, StatusCheckedIn}
+
+// IsActive は部屋を使う予定がある（予約中または利用中の）状態かを返す
+func (s ReservationStatus) IsActive() bool {
+	return s == StatusPending || s == StatusCheckedIn
+}
+
 // Reservation は予約情報を表す構造体
 type Reservation struct {
-	ID        string            `json:"id"`         // 予約ID（推測しにくい英数字列）
-	UserID    string            `json:"user_id"`    // 予約者のDiscord ID
-	Username  string            `json:"username"`   // 予約者の表示名
-	Date      string            `json:"date"`       // 予約日（YYYY-MM-DD形式）
-	StartTime string            `json:"start_time"` // 開始時間（HH:MM形式）
-	EndTime   string            `json:"end_time"`   // 終了時間（HH:MM形式）
-	Comment   string            `json:"comment"`    // コメント（オプション）
-	Status    ReservationStatus `json:"status"`     // 予約状態
-	CreatedAt time.Time         `json:"created_at"` // 作成日時
-	UpdatedAt time.Time         `json:"updated_at"` // 更新日時
-	ChannelID string            `json:"channel_id"` // 予約が行われたチャンネルID
+	ID              string            `json:"id"`                          // 予約ID（推測しにくい英数字列）
+	UserID          string            `json:"user_id"`                     // 予約者のDiscord ID
+	Username        string            `json:"username"`                    // 予約者の表示名
+	Date            string            `json:"date"`                        // 予約日（開始日、YYYY-MM-DD形式）
+	EndDate         string            `json:"end_date"`                    // 終了日（YYYY-MM-DD形式、日を跨ぐ予約では予約日の翌日）
+	StartTime       string            `json:"start_time"`                  // 開始時間（HH:MM形式）
+	EndTime         string            `json:"end_time"`                    // 終了時間（HH:MM形式）
+	Comment         string            `json:"comment"`                     // コメント（オプション）
+	Status          ReservationStatus `json:"status"`                      // 予約状態
+	CreatedAt       time.Time         `json:"created_at"`                  // 作成日時
+	UpdatedAt       time.Time         `json:"updated_at"`                  // 更新日時
+	ChannelID       string            `json:"channel_id"`                  // 予約が行われたチャンネルID
+	ResourceID      string            `json:"resource_id"`                 // 予約対象の部屋ID
+	SeriesID        string            `json:"series_id,omitempty"`         // 繰り返し予約のシリーズID（単発の予約は空）
+	Recurrence      *Recurrence       `json:"recurrence,omitempty"`        // 繰り返し予約のルール（シリーズの全予約で共通）
+	History         []HistoryEntry    `json:"history,omitempty"`           // 変更履歴（古い順、追記のみ）
+	NoticeChannelID string            `json:"notice_channel_id,omitempty"` // 予約追加の公開通知を送信したチャンネルID
+	NoticeMessageID string            `json:"notice_message_id,omitempty"` // 予約追加の公開通知のメッセージID（状態が変わったときに更新する）
+	RemindedAt      *time.Time        `json:"reminded_at,omitempty"`       // 開始前のリマインダーを送信した日時（未送信はnil）
+	ConfirmedAt     *time.Time        `json:"confirmed_at,omitempty"`      // リマインダーで「行きます」を押した日時
 }
 
 // GenerateReservationID は推測しにくいランダムな予約IDを生成する
@@ -39,33 +58,174 @@ func GenerateReservationID() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-// GetDateTime は予約日時をtime.Time型で返す
-func (r *Reservation) GetDateTime(timeStr string) (time.Time, error) {
+// Clone は予約のコピーを返す（ストレージ外で変更しても保存済みデータに影響しない）
+func (r *Reservation) Clone() *Reservation {
+	clone := *r
+	if r.Recurrence != nil {
+		recurrence := *r.Recurrence
+		clone.Recurrence = &recurrence
+	}
+	if r.RemindedAt != nil {
+		remindedAt := *r.RemindedAt
+		clone.RemindedAt = &remindedAt
+	}
+	if r.ConfirmedAt != nil {
+		confirmedAt := *r.ConfirmedAt
+		clone.ConfirmedAt = &confirmedAt
+	}
+	if r.History != nil {
+		clone.History = make([]HistoryEntry, len(r.History))
+		for i, entry := range r.History {
+			clone.History[i] = entry.clone()
+		}
+	}
+	return &clone
+}
+
+// IsRecurring は繰り返し予約の一部であるかを返す
+func (r *Reservation) IsRecurring() bool {
+	return r.SeriesID != ""
+}
+
+// GetResourceID は予約対象の部屋IDを返す（未設定の場合は既定の部屋）
+func (r *Reservation) GetResourceID() string {
+	if r.ResourceID == "" {
+		return DefaultResourceID
+	}
+	return r.ResourceID
+}
+
+// GetEndDate は予約の終了日を返す（未設定の場合は予約日と同じ）
+func (r *Reservation) GetEndDate() string {
+	if r.EndDate == "" {
+		return r.Date
+	}
+	return r.EndDate
+}
+
+// SpanDays は予約が開始日から何日後に終わるかを返す（同じ日に終わる場合は0）
+func (r *Reservation) SpanDays() int {
+	start, err := time.Parse("2006-01-02", r.Date)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse("2006-01-02", r.GetEndDate())
+	if err != nil {
+		return 0
+	}
+	return int(end.Sub(start).Hours() / 24)
+}
+
+// ResolveEndDate は予約日と開始・終了時刻から終了日を求める
+// 終了時刻が開始時刻以前の場合は、翌日に終わる日跨ぎの予約とみなす
+func ResolveEndDate(date, startTime, endTime string) string {
+	if endTime > startTime {
+		return date
+	}
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return parsed.AddDate(0, 0, 1).Format("2006-01-02")
+}
+
+// parseDateTime は日付と時刻を部室のタイムゾーンの時刻として変換する
+func parseDateTime(date, timeStr string) (time.Time, error) {
 	layout := "2006-01-02 15:04"
-	dateTimeStr := r.Date + " " + timeStr
-	return time.Parse(layout, dateTimeStr)
+	dateTimeStr := date + " " + timeStr
+	return time.ParseInLocation(layout, dateTimeStr, location)
+}
+
+// GetDateTime は予約日の日時をtime.Time型で返す
+func (r *Reservation) GetDateTime(timeStr string) (time.Time, error) {
+	return parseDateTime(r.Date, timeStr)
 }
 
 // GetStartDateTime は予約開始日時をtime.Time型で返す
 func (r *Reservation) GetStartDateTime() (time.Time, error) {
-	return r.GetDateTime(r.StartTime)
+	return parseDateTime(r.Date, r.StartTime)
 }
 
-// GetEndDateTime は予約終了日時をtime.Time型で返す
+// GetEndDateTime は予約終了日時をtime.Time型で返す（日を跨ぐ予約では終了日の時刻）
 func (r *Reservation) GetEndDateTime() (time.Time, error) {
-	return r.GetDateTime(r.EndTime)
+	return parseDateTime(r.GetEndDate(), r.EndTime)
+}
+
+// ReminderDue は now の時点で開始前のリマインダーを送るべきか（開始の lead 前から開始までの間で未送信）を返す
+// リマインダーの送信時刻を過ぎてから作成された予約には送らない
+func (r *Reservation) ReminderDue(now time.Time, lead time.Duration) bool {
+	if r.Status != StatusPending || r.RemindedAt != nil || lead <= 0 {
+		return false
+	}
+	start, err := r.GetStartDateTime()
+	if err != nil {
+		return false
+	}
+	remindAt := start.Add(-lead)
+	if now.Before(remindAt) || !now.Before(start) {
+		return false
+	}
+	return r.CreatedAt.Before(remindAt)
+}
+
+// CheckInOpensBefore は予約開始の何分前からチェックインできるか
+const CheckInOpensBefore = 15 * time.Minute
+
+// CanCheckIn は now の時点でチェックインできるか（予約中で、開始の少し前から終了までの間）を返す
+func (r *Reservation) CanCheckIn(now time.Time) bool {
+	if r.Status != StatusPending {
+		return false
+	}
+	start, err := r.GetStartDateTime()
+	if err != nil {
+		return false
+	}
+	end, err := r.GetEndDateTime()
+	if err != nil {
+		return false
+	}
+	return !now.Before(start.Add(-CheckInOpensBefore)) && now.Before(end)
+}
+
+// NoShowDue は now の時点で、開始から grace 経ってもチェックインされていない予約を解放すべきかを返す
+// 終了時刻を過ぎた予約は解放しても意味がないため対象外にする（期限切れの自動完了に任せる）
+func (r *Reservation) NoShowDue(now time.Time, grace time.Duration) bool {
+	if r.Status != StatusPending || grace <= 0 {
+		return false
+	}
+	start, err := r.GetStartDateTime()
+	if err != nil {
+		return false
+	}
+	end, err := r.GetEndDateTime()
+	if err != nil {
+		return false
+	}
+	return !now.Before(start.Add(grace)) && now.Before(end)
+}
+
+// ResetReminder は開始日時が変わった予約のリマインダーを未送信に戻す
+func (r *Reservation) ResetReminder(before *Reservation) {
+	if r.Date != before.Date || r.StartTime != before.StartTime {
+		r.RemindedAt = nil
+		r.ConfirmedAt = nil
+	}
 }
 
 // OverlapsWith は他の予約と時間が重複しているかチェックする
 func (r *Reservation) OverlapsWith(other *Reservation) (bool, error) {
-	// キャンセル済み・完了済みの予約は重複チェックしない
-	if r.Status == StatusCancelled || r.Status == StatusCompleted ||
-		other.Status == StatusCancelled || other.Status == StatusCompleted {
+	// キャンセル済み・完了済み・無断欠席の予約は重複チェックしない
+	if !r.Status.IsActive() || !other.Status.IsActive() {
+		return false, nil
+	}
+
+	// 部屋が異なる場合は重複しない
+	if r.GetResourceID() != other.GetResourceID() {
 		return false, nil
 	}
 
-	// 日付が異なる場合は重複しない
-	if r.Date != other.Date {
+	// 期間の日付が重ならない場合は重複しない（日を跨ぐ予約は終了日まで含める）
+	if r.GetEndDate() < other.Date || other.GetEndDate() < r.Date {
 		return false, nil
 	}
 
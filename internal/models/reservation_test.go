@@ -0,0 +1,169 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func newOvernightTestReservation(date, start, end string) *Reservation {
+	return &Reservation{
+		Date:      date,
+		EndDate:   ResolveEndDate(date, start, end),
+		StartTime: start,
+		EndTime:   end,
+		Status:    StatusPending,
+	}
+}
+
+func TestResolveEndDate(t *testing.T) {
+	tests := []struct {
+		date, start, end string
+		want             string
+	}{
+		{"2030-01-10", "14:00", "15:00", "2030-01-10"},
+		{"2030-01-10", "20:00", "02:00", "2030-01-11"},
+		{"2030-12-31", "22:00", "00:00", "2031-01-01"},
+	}
+	for _, tt := range tests {
+		if got := ResolveEndDate(tt.date, tt.start, tt.end); got != tt.want {
+			t.Errorf("ResolveEndDate(%s, %s, %s) = %s, want %s", tt.date, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestOverlapsWithAcrossMidnight(t *testing.T) {
+	overnight := newOvernightTestReservation("2030-01-10", "20:00", "02:00")
+
+	tests := []struct {
+		name  string
+		other *Reservation
+		want  bool
+	}{
+		{"same evening", newOvernightTestReservation("2030-01-10", "21:00", "22:00"), true},
+		{"after midnight", newOvernightTestReservation("2030-01-11", "01:00", "03:00"), true},
+		{"right after end", newOvernightTestReservation("2030-01-11", "02:00", "03:00"), false},
+		{"before start", newOvernightTestReservation("2030-01-10", "18:00", "20:00"), false},
+		{"previous night", newOvernightTestReservation("2030-01-09", "22:00", "03:00"), false},
+		{"next night", newOvernightTestReservation("2030-01-11", "23:00", "01:00"), false},
+		{"legacy record without end date", &Reservation{Date: "2030-01-11", StartTime: "00:30", EndTime: "01:00", Status: StatusPending}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := overnight.OverlapsWith(tt.other)
+			if err != nil {
+				t.Fatalf("OverlapsWith failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("OverlapsWith = %v, want %v", got, tt.want)
+			}
+			// 重複判定は対称であること
+			if reverse, _ := tt.other.OverlapsWith(overnight); reverse != got {
+				t.Errorf("OverlapsWith is not symmetric: %v vs %v", got, reverse)
+			}
+		})
+	}
+}
+
+func TestEndDateTimeUsesEndDate(t *testing.T) {
+	r := newOvernightTestReservation("2030-01-10", "20:00", "02:00")
+	end, err := r.GetEndDateTime()
+	if err != nil {
+		t.Fatalf("GetEndDateTime failed: %v", err)
+	}
+	if got := end.Format("2006-01-02 15:04"); got != "2030-01-11 02:00" {
+		t.Errorf("GetEndDateTime = %s, want 2030-01-11 02:00", got)
+	}
+	if r.SpanDays() != 1 {
+		t.Errorf("SpanDays = %d, want 1", r.SpanDays())
+	}
+}
+
+func TestReminderDue(t *testing.T) {
+	created := time.Date(2030, 1, 1, 0, 0, 0, 0, location)
+	start := time.Date(2030, 1, 10, 14, 0, 0, 0, location)
+	sent := start.Add(-time.Hour)
+	tests := []struct {
+		name   string
+		modify func(r *Reservation)
+		now    time.Time
+		want   bool
+	}{
+		{"before the window", nil, start.Add(-31 * time.Minute), false},
+		{"window opens", nil, start.Add(-30 * time.Minute), true},
+		{"just before start", nil, start.Add(-time.Minute), true},
+		{"started", nil, start, false},
+		{"already sent", func(r *Reservation) { r.RemindedAt = &sent }, start.Add(-10 * time.Minute), false},
+		{"cancelled", func(r *Reservation) { r.Status = StatusCancelled }, start.Add(-10 * time.Minute), false},
+		{"created inside the window", func(r *Reservation) { r.CreatedAt = start.Add(-20 * time.Minute) }, start.Add(-10 * time.Minute), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newOvernightTestReservation("2030-01-10", "14:00", "15:00")
+			r.CreatedAt = created
+			if tt.modify != nil {
+				tt.modify(r)
+			}
+			if got := r.ReminderDue(tt.now, 30*time.Minute); got != tt.want {
+				t.Errorf("ReminderDue(%s) = %v, want %v", tt.now.Format("15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckInAndNoShowWindows(t *testing.T) {
+	start := time.Date(2030, 1, 10, 14, 0, 0, 0, location)
+	tests := []struct {
+		name       string
+		status     ReservationStatus
+		now        time.Time
+		wantCheck  bool
+		wantNoShow bool
+	}{
+		{"before check-in opens", StatusPending, start.Add(-16 * time.Minute), false, false},
+		{"check-in opens", StatusPending, start.Add(-15 * time.Minute), true, false},
+		{"within the grace period", StatusPending, start.Add(14 * time.Minute), true, false},
+		{"grace period over", StatusPending, start.Add(15 * time.Minute), true, true},
+		{"ended", StatusPending, start.Add(time.Hour), false, false},
+		{"already checked in", StatusCheckedIn, start.Add(20 * time.Minute), false, false},
+		{"cancelled", StatusCancelled, start.Add(20 * time.Minute), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newOvernightTestReservation("2030-01-10", "14:00", "15:00")
+			r.Status = tt.status
+			if got := r.CanCheckIn(tt.now); got != tt.wantCheck {
+				t.Errorf("CanCheckIn(%s) = %v, want %v", tt.now.Format("15:04"), got, tt.wantCheck)
+			}
+			if got := r.NoShowDue(tt.now, 15*time.Minute); got != tt.wantNoShow {
+				t.Errorf("NoShowDue(%s) = %v, want %v", tt.now.Format("15:04"), got, tt.wantNoShow)
+			}
+			if r.NoShowDue(tt.now, 0) {
+				t.Error("NoShowDue should be disabled when the grace period is 0")
+			}
+		})
+	}
+}
+
+func TestResetReminder(t *testing.T) {
+	sent := time.Date(2030, 1, 10, 13, 30, 0, 0, location)
+	before := newOvernightTestReservation("2030-01-10", "14:00", "15:00")
+	before.RemindedAt = &sent
+
+	extended := before.Clone()
+	extended.EndTime = "16:00"
+	extended.ResetReminder(before)
+	if extended.RemindedAt == nil {
+		t.Error("Changing only the end time should keep the reminder state")
+	}
+
+	moved := before.Clone()
+	moved.StartTime = "18:00"
+	moved.ResetReminder(before)
+	if moved.RemindedAt != nil {
+		t.Error("Moving the start time should reset the reminder")
+	}
+	if before.RemindedAt == nil || before.RemindedAt == moved.RemindedAt {
+		t.Error("Clone should copy the reminder timestamp")
+	}
+}
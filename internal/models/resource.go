@@ -0,0 +1,20 @@
+package models
+
+// DefaultResourceID は部屋の指定がない予約に割り当てる既定の部屋ID
+const DefaultResourceID = "main"
+
+// Resource は予約対象の部屋や設備を表す構造体
+type Resource struct {
+	ID        string `json:"id"`                   // 部屋ID（英数字、予約データに保存される）
+	Name      string `json:"name"`                 // 表示名
+	Capacity  int    `json:"capacity,omitempty"`   // 定員（0は未設定）
+	ChannelID string `json:"channel_id,omitempty"` // 予約通知を送るチャンネルID（空の場合は既定のチャンネル）
+}
+
+// DefaultResource は部屋の設定がない場合に使う既定の部屋を返す
+func DefaultResource() Resource {
+	return Resource{
+		ID:   DefaultResourceID,
+		Name: "部室",
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultTimezone は設定がない場合に使う部室のタイムゾーン
+const DefaultTimezone = "Asia/Tokyo"
+
+// location は予約の日付・時刻を解釈するタイムゾーン（起動時に SetLocation で設定する）
+var location = defaultLocation()
+
+// defaultLocation は既定のタイムゾーンを返す（タイムゾーンデータがない環境では固定オフセットを使う）
+func defaultLocation() *time.Location {
+	if loc, err := time.LoadLocation(DefaultTimezone); err == nil {
+		return loc
+	}
+	return time.FixedZone(DefaultTimezone, 9*60*60)
+}
+
+// LoadLocation はタイムゾーン名（例: Asia/Tokyo）からタイムゾーンを読み込む（空の場合は既定のタイムゾーン）
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return defaultLocation(), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// SetLocation は予約の日付・時刻を解釈するタイムゾーンを設定する
+func SetLocation(loc *time.Location) {
+	if loc == nil {
+		loc = defaultLocation()
+	}
+	location = loc
+}
+
+// Location は予約の日付・時刻を解釈するタイムゾーンを返す
+func Location() *time.Location {
+	return location
+}
+
+// Now は部室のタイムゾーンでの現在時刻を返す
+func Now() time.Time {
+	return time.Now().In(location)
+}
+
+// Today は部室のタイムゾーンでの今日の日付を YYYY-MM-DD 形式で返す
+func Today() string {
+	return Now().Format("2006-01-02")
+}
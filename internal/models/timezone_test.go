@@ -0,0 +1,62 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// useTimezones はテスト中だけ部室のタイムゾーンとサーバーのローカルタイムゾーンを切り替える
+func useTimezones(t *testing.T, club, local *time.Location) {
+	t.Helper()
+	oldLocation, oldLocal := location, time.Local
+	SetLocation(club)
+	time.Local = local
+	t.Cleanup(func() {
+		location = oldLocation
+		time.Local = oldLocal
+	})
+}
+
+func TestReservationTimesUseClubTimezone(t *testing.T) {
+	club := time.FixedZone("UTC-5", -5*60*60)
+	useTimezones(t, club, time.FixedZone("UTC+9", 9*60*60))
+
+	r := &Reservation{Date: "2030-01-10", StartTime: "20:00", EndTime: "02:00", EndDate: "2030-01-11"}
+	start, err := r.GetStartDateTime()
+	if err != nil {
+		t.Fatalf("GetStartDateTime failed: %v", err)
+	}
+	if want := time.Date(2030, 1, 11, 1, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("GetStartDateTime = %v, want %v", start.UTC(), want)
+	}
+
+	end, err := r.GetEndDateTime()
+	if err != nil {
+		t.Fatalf("GetEndDateTime failed: %v", err)
+	}
+	if want := time.Date(2030, 1, 11, 7, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("GetEndDateTime = %v, want %v", end.UTC(), want)
+	}
+}
+
+func TestTodayUsesClubTimezone(t *testing.T) {
+	// 日付変更線をまたぐ2つのタイムゾーンでは、同じ瞬間でも日付が異なる
+	club := time.FixedZone("UTC+14", 14*60*60)
+	useTimezones(t, club, time.FixedZone("UTC-12", -12*60*60))
+
+	if got, want := Today(), time.Now().In(club).Format("2006-01-02"); got != want {
+		t.Errorf("Today = %s, want %s", got, want)
+	}
+	if Now().Location() != club {
+		t.Errorf("Now should be in the club timezone, got %v", Now().Location())
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	if loc, err := LoadLocation(""); err != nil || loc == nil {
+		t.Errorf("Expected default location, got %v (%v)", loc, err)
+	}
+	if _, err := LoadLocation("Not/AZone"); err == nil {
+		t.Error("Expected error for unknown timezone")
+	}
+}
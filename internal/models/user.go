@@ -0,0 +1,6 @@
+package models
+
+// UserSettings はユーザーごとの設定を表す構造体
+type UserSettings struct {
+	ReminderOptOut bool `json:"reminder_opt_out,omitempty"` // 開始前のリマインダーを受け取らない
+}
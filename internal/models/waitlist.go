@@ -0,0 +1,101 @@
+package models
+
+import "time"
+
+// WaitlistEntry はキャンセル待ち1件を表す構造体
+// 希望の時間帯が空くと、登録の古い順に予約の案内（オファー）をDMで送る
+type WaitlistEntry struct {
+	ID             string     `json:"id"`                         // キャンセル待ちID
+	UserID         string     `json:"user_id"`                    // 登録したユーザーのDiscord ID
+	Username       string     `json:"username"`                   // 登録したユーザーの表示名
+	ResourceID     string     `json:"resource_id,omitempty"`      // 部屋ID（空の場合は既定の部屋）
+	Date           string     `json:"date"`                       // 希望日（YYYY-MM-DD形式）
+	EndDate        string     `json:"end_date,omitempty"`         // 終了日（日を跨ぐ場合は翌日）
+	StartTime      string     `json:"start_time"`                 // 開始時間（HH:MM形式）
+	EndTime        string     `json:"end_time"`                   // 終了時間（HH:MM形式）
+	Comment        string     `json:"comment,omitempty"`          // 予約に付けるコメント
+	CreatedAt      time.Time  `json:"created_at"`                 // 登録日時（案内の順番）
+	OfferedAt      *time.Time `json:"offered_at,omitempty"`       // 予約の案内を送った日時（未送信の場合は nil）
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"` // 案内の有効期限
+	OfferChannelID string     `json:"offer_channel_id,omitempty"` // 案内のDMのチャンネルID
+	OfferMessageID string     `json:"offer_message_id,omitempty"` // 案内のDMのメッセージID
+}
+
+// Clone は登録内容のコピーを返す
+func (e *WaitlistEntry) Clone() *WaitlistEntry {
+	clone := *e
+	if e.OfferedAt != nil {
+		offeredAt := *e.OfferedAt
+		clone.OfferedAt = &offeredAt
+	}
+	if e.OfferExpiresAt != nil {
+		expiresAt := *e.OfferExpiresAt
+		clone.OfferExpiresAt = &expiresAt
+	}
+	return &clone
+}
+
+// Slot は希望の時間帯を予約中の予約として返す（重複の確認や予約の作成に使う）
+func (e *WaitlistEntry) Slot() *Reservation {
+	return &Reservation{
+		UserID:     e.UserID,
+		Username:   e.Username,
+		Date:       e.Date,
+		EndDate:    e.EndDate,
+		StartTime:  e.StartTime,
+		EndTime:    e.EndTime,
+		Comment:    e.Comment,
+		Status:     StatusPending,
+		ResourceID: e.ResourceID,
+	}
+}
+
+// SlotFrom は希望の時間帯のうち now 以降の部分を返す（開始時刻を過ぎている場合は次の分から終了まで）
+func (e *WaitlistEntry) SlotFrom(now time.Time) *Reservation {
+	slot := e.Slot()
+	start, err := slot.GetStartDateTime()
+	if err != nil || !start.Before(now) {
+		return slot
+	}
+	from := now.In(Location()).Truncate(time.Minute).Add(time.Minute)
+	slot.Date = from.Format("2006-01-02")
+	slot.StartTime = from.Format("15:04")
+	if slot.GetEndDate() == slot.Date {
+		slot.EndDate = ""
+	}
+	return slot
+}
+
+// Overlaps は他のキャンセル待ちと同じ部屋で希望の時間帯が重なるかを返す
+func (e *WaitlistEntry) Overlaps(other *WaitlistEntry) bool {
+	a, b := e.Slot(), other.Slot()
+	if a.GetResourceID() != b.GetResourceID() {
+		return false
+	}
+	overlaps, err := a.OverlapsWith(b)
+	return err == nil && overlaps
+}
+
+// Offered は now の時点で有効な予約の案内が出ているかを返す
+func (e *WaitlistEntry) Offered(now time.Time) bool {
+	return e.OfferExpiresAt != nil && now.Before(*e.OfferExpiresAt)
+}
+
+// OfferExpired は予約の案内を送ったが now の時点で期限が切れているかを返す
+func (e *WaitlistEntry) OfferExpired(now time.Time) bool {
+	return e.OfferExpiresAt != nil && !now.Before(*e.OfferExpiresAt)
+}
+
+// Ended は希望の時間帯が now の時点で終わっているかを返す
+func (e *WaitlistEntry) Ended(now time.Time) bool {
+	end, err := e.Slot().GetEndDateTime()
+	return err != nil || !now.Before(end)
+}
+
+// ClearOffer は予約の案内を取り消し、順番待ちに戻す
+func (e *WaitlistEntry) ClearOffer() {
+	e.OfferedAt = nil
+	e.OfferExpiresAt = nil
+	e.OfferChannelID = ""
+	e.OfferMessageID = ""
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaitlistSlotFrom(t *testing.T) {
+	entry := &WaitlistEntry{Date: "2030-01-10", EndDate: "2030-01-11", StartTime: "23:00", EndTime: "01:00"}
+
+	// 開始前はそのままの時間帯
+	if got := entry.SlotFrom(time.Date(2030, 1, 10, 22, 0, 0, 0, location)); got.Date != "2030-01-10" || got.StartTime != "23:00" || got.GetEndDate() != "2030-01-11" {
+		t.Errorf("Unexpected slot before start: %+v", got)
+	}
+	// 開始を過ぎている場合は次の分から終了まで（日を跨いだ後は同じ日の時間帯になる）
+	got := entry.SlotFrom(time.Date(2030, 1, 11, 0, 10, 30, 0, location))
+	if got.Date != "2030-01-11" || got.EndDate != "" || got.StartTime != "00:11" || got.EndTime != "01:00" {
+		t.Errorf("Unexpected slot after start: %+v", got)
+	}
+}
+
+func TestWaitlistOfferState(t *testing.T) {
+	now := time.Date(2030, 1, 10, 14, 0, 0, 0, location)
+	entry := &WaitlistEntry{Date: "2030-01-10", StartTime: "14:30", EndTime: "15:00"}
+	if entry.Offered(now) || entry.OfferExpired(now) || entry.Ended(now) {
+		t.Fatalf("Expected a waiting entry, got offered %v expired %v ended %v", entry.Offered(now), entry.OfferExpired(now), entry.Ended(now))
+	}
+
+	expiresAt := now.Add(15 * time.Minute)
+	entry.OfferedAt, entry.OfferExpiresAt = &now, &expiresAt
+	if !entry.Offered(now) || entry.OfferExpired(now) {
+		t.Error("Expected the offer to be open before it expires")
+	}
+	if entry.Offered(expiresAt) || !entry.OfferExpired(expiresAt) {
+		t.Error("Expected the offer to expire at OfferExpiresAt")
+	}
+	entry.ClearOffer()
+	if entry.OfferExpiresAt != nil || entry.OfferExpired(expiresAt) {
+		t.Error("Expected ClearOffer to return the entry to the queue")
+	}
+	if !entry.Ended(time.Date(2030, 1, 10, 15, 0, 0, 0, location)) {
+		t.Error("Expected the entry to end at its end time")
+	}
+
+	other := &WaitlistEntry{Date: "2030-01-10", StartTime: "14:45", EndTime: "16:00"}
+	if !entry.Overlaps(other) {
+		t.Error("Expected overlapping entries in the same room to overlap")
+	}
+	other.ResourceID = "studio"
+	if entry.Overlaps(other) {
+		t.Error("Expected entries in different rooms not to overlap")
+	}
+}
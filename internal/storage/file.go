@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxBackups は保持する直近の正常なデータファイルの世代数
+const maxBackups = 5
+
+// writeFileAtomic は一時ファイルに書き込んでfsyncした後、renameで置き換える
+// 途中で電源断やディスクフルが起きても、元のファイルか新しいファイルのどちらかが必ず残る
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	// 失敗時は一時ファイルを残さない
+	success := false
+	defer func() {
+		if !success {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	success = true
+
+	// renameをディスクに反映させるためディレクトリもfsyncする
+	return syncDir(dir)
+}
+
+// syncDir はディレクトリエントリの変更をディスクに書き出す
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil && !isUnsupportedSync(err) {
+		return err
+	}
+	return nil
+}
+
+// isUnsupportedSync はディレクトリのfsyncに対応していない環境のエラーかを判定する
+func isUnsupportedSync(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "invalid argument") || strings.Contains(msg, "not supported")
+}
+
+// backupDir はデータファイルのバックアップを置くディレクトリを返す
+func backupDir(path string) string {
+	return filepath.Join(filepath.Dir(path), "backups")
+}
+
+// backupFile は現在のデータファイルをタイムスタンプ付きでバックアップし、古い世代を削除する
+func backupFile(path string, data []byte) error {
+	name := fmt.Sprintf("%s.%s", filepath.Base(path), time.Now().Format("20060102-150405.000000000"))
+	if err := writeFileAtomic(filepath.Join(backupDir(path), name), data, 0644); err != nil {
+		return err
+	}
+
+	backups, err := listBackups(path)
+	if err != nil {
+		return err
+	}
+	for idx := maxBackups; idx < len(backups); idx++ {
+		os.Remove(backups[idx])
+	}
+	return nil
+}
+
+// listBackups はデータファイルのバックアップを新しい順に返す
+func listBackups(path string) ([]string, error) {
+	entries, err := os.ReadDir(backupDir(path))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := filepath.Base(path) + "."
+	backups := make([]string, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || strings.Contains(name, ".tmp-") {
+			continue
+		}
+		backups = append(backups, filepath.Join(backupDir(path), name))
+	}
+
+	// ファイル名のタイムスタンプで新しい順に並べる
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	return backups, nil
+}
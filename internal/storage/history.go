@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// ErrHistoryRewritten は保存済みの変更履歴を書き換える・削除する更新の場合に返される
+var ErrHistoryRewritten = errors.New("reservation history is append-only")
+
+// checkHistoryAppendOnly は更新後の変更履歴が保存済みの履歴に追記しただけであるかを確認する
+// 古いコピーを元にした更新で他の変更の履歴が失われる場合もエラーになる
+func checkHistoryAppendOnly(stored, updated *models.Reservation) error {
+	if len(updated.History) < len(stored.History) {
+		return ErrHistoryRewritten
+	}
+	for i, entry := range stored.History {
+		if !sameHistoryEntry(entry, updated.History[i]) {
+			return ErrHistoryRewritten
+		}
+	}
+	return nil
+}
+
+// sameHistoryEntry は2つの変更履歴が同じ記録であるかを返す
+func sameHistoryEntry(a, b models.HistoryEntry) bool {
+	if !a.At.Equal(b.At) || a.ActorID != b.ActorID || a.Source != b.Source || a.Action != b.Action || a.Note != b.Note {
+		return false
+	}
+	if len(a.Changes) != len(b.Changes) {
+		return false
+	}
+	for i := range a.Changes {
+		if a.Changes[i] != b.Changes[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// autoCompleteHistoryEntry は期限切れ予約の自動完了の変更履歴を返す
+func autoCompleteHistoryEntry(at time.Time) models.HistoryEntry {
+	return models.HistoryEntry{
+		At:     at,
+		Source: models.SourceAutoComplete,
+		Action: models.HistoryCompleted,
+	}
+}
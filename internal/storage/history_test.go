@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// newHistoryEntry はテスト用の変更履歴を作成する
+func newHistoryEntry(action models.HistoryAction) models.HistoryEntry {
+	return models.HistoryEntry{
+		At:        time.Now(),
+		ActorID:   "user1",
+		ActorName: "Test User",
+		Source:    models.CommandSource("edit"),
+		Action:    action,
+	}
+}
+
+func TestRepositoryHistoryIsAppendOnly(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			r := newTestReservation("history-1", "user1", "2030-02-01", "10:00", "11:00")
+			r.RecordHistory(nil, newHistoryEntry(models.HistoryCreated))
+			if err := repo.AddReservation(r); err != nil {
+				t.Fatalf("AddReservation failed: %v", err)
+			}
+
+			// 追記はできる
+			stale, _ := repo.GetReservation("history-1")
+			updated, err := repo.Mutate("history-1", func(r *models.Reservation) error {
+				before := r.Clone()
+				r.Comment = "changed"
+				r.RecordHistory(before, newHistoryEntry(models.HistoryEdited))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Mutate failed: %v", err)
+			}
+			if len(updated.History) != 2 {
+				t.Fatalf("Expected 2 history entries, got %d", len(updated.History))
+			}
+			want := models.FieldChange{Field: "comment", From: "", To: "changed"}
+			if changes := updated.History[1].Changes; len(changes) != 1 || changes[0] != want {
+				t.Errorf("Expected change %+v, got %+v", want, changes)
+			}
+
+			// 削除・書き換えはできない
+			truncated, _ := repo.GetReservation("history-1")
+			truncated.History = truncated.History[:1]
+			if err := repo.UpdateReservation(truncated); err != ErrHistoryRewritten {
+				t.Errorf("UpdateReservation: expected ErrHistoryRewritten, got %v", err)
+			}
+			if _, err := repo.Mutate("history-1", func(r *models.Reservation) error {
+				r.History[0].ActorID = "someone-else"
+				return nil
+			}); err != ErrHistoryRewritten {
+				t.Errorf("Mutate: expected ErrHistoryRewritten, got %v", err)
+			}
+
+			if _, _, err := repo.UpdateIfFree("history-1", func(r *models.Reservation) error {
+				r.History = r.History[:1]
+				return nil
+			}); err != ErrHistoryRewritten {
+				t.Errorf("UpdateIfFree: expected ErrHistoryRewritten, got %v", err)
+			}
+
+			// 古いコピーからの更新は他の変更の履歴を消すため拒否される
+			stale.RecordHistory(stale.Clone(), newHistoryEntry(models.HistoryEdited))
+			if err := repo.UpdateReservation(stale); err != ErrHistoryRewritten {
+				t.Errorf("UpdateReservation from stale copy: expected ErrHistoryRewritten, got %v", err)
+			}
+
+			got, _ := repo.GetReservation("history-1")
+			if len(got.History) != 2 || got.Comment != "changed" {
+				t.Errorf("Expected stored reservation to be unchanged, got %d entries and comment %q", len(got.History), got.Comment)
+			}
+		})
+	}
+}
+
+func TestAutoCompleteRecordsHistory(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			if err := repo.AddReservation(newReservationBetween("ended", now.Add(-3*time.Hour), now.Add(-time.Hour))); err != nil {
+				t.Fatalf("AddReservation failed: %v", err)
+			}
+			if _, err := repo.AutoCompleteExpiredReservations(); err != nil {
+				t.Fatalf("AutoCompleteExpiredReservations failed: %v", err)
+			}
+
+			r, err := repo.GetReservation("ended")
+			if err != nil {
+				t.Fatalf("GetReservation failed: %v", err)
+			}
+			if len(r.History) != 1 {
+				t.Fatalf("Expected 1 history entry, got %d", len(r.History))
+			}
+			entry := r.History[0]
+			if entry.Source != models.SourceAutoComplete || entry.Action != models.HistoryCompleted || !entry.IsSystem() {
+				t.Errorf("Unexpected history entry: %+v", entry)
+			}
+			want := models.FieldChange{Field: "status", From: string(models.StatusPending), To: string(models.StatusCompleted)}
+			if len(entry.Changes) != 1 || entry.Changes[0] != want {
+				t.Errorf("Expected change %+v, got %+v", want, entry.Changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// currentSchemaVersion は現在のデータ形式のバージョン
+// models.Reservation やデータファイルの構造を変更した場合は、migrations に移行処理を追加してこの値を上げる
+const currentSchemaVersion = 3
+
+// ErrSchemaTooNew はデータが現在のバージョンより新しい形式で保存されている場合に返される
+var ErrSchemaTooNew = errors.New("data schema version is newer than supported")
+
+// dataFile はJSONデータファイルのトップレベル構造
+type dataFile struct {
+	SchemaVersion int                            `json:"schema_version"`
+	Reservations  map[string]*models.Reservation `json:"reservations"`
+}
+
+// migration は1つ前のバージョンから version への移行処理
+// document はデータファイル全体（JSONバックエンドのみ）、reservation は予約1件ごと（全バックエンド共通）に適用される
+type migration struct {
+	version     int
+	description string
+	document    func(doc map[string]interface{}) (map[string]interface{}, error)
+	reservation func(record map[string]interface{}) error
+}
+
+// migrations はバージョン順に並べた移行処理の一覧
+var migrations = []migration{
+	{
+		version:     1,
+		description: "wrap the bare reservation map in a versioned envelope",
+		document: func(doc map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{
+				"schema_version": 1,
+				"reservations":   doc,
+			}, nil
+		},
+	},
+	{
+		version:     2,
+		description: "assign existing reservations to the default resource",
+		reservation: func(record map[string]interface{}) error {
+			if id, _ := record["resource_id"].(string); id == "" {
+				record["resource_id"] = models.DefaultResourceID
+			}
+			return nil
+		},
+	},
+	{
+		version:     3,
+		description: "record an explicit end date so reservations can span midnight",
+		reservation: func(record map[string]interface{}) error {
+			if endDate, _ := record["end_date"].(string); endDate == "" {
+				record["end_date"] = record["date"]
+			}
+			return nil
+		},
+	},
+}
+
+// detectSchemaVersion はデータファイルのバージョンを判定する
+// schema_version を持たないファイルはバージョン導入前の形式（バージョン0）として扱う
+func detectSchemaVersion(doc map[string]interface{}) (int, error) {
+	raw, ok := doc["schema_version"]
+	if !ok {
+		return 0, nil
+	}
+
+	version, ok := raw.(float64)
+	if !ok || version < 0 || version != float64(int(version)) {
+		return 0, fmt.Errorf("invalid schema_version: %v", raw)
+	}
+	return int(version), nil
+}
+
+// decodeDataFile はデータファイルを読み込み、必要であれば現在のバージョンまで移行する
+// 戻り値の fromVersion は読み込んだ時点のバージョン
+func decodeDataFile(data []byte) (reservations map[string]*models.Reservation, fromVersion int, err error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+
+	fromVersion, err = detectSchemaVersion(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if fromVersion > currentSchemaVersion {
+		return nil, fromVersion, fmt.Errorf("%w: file is v%d, supported up to v%d", ErrSchemaTooNew, fromVersion, currentSchemaVersion)
+	}
+
+	for _, m := range migrations {
+		if m.version <= fromVersion {
+			continue
+		}
+		if doc, err = applyDocumentMigration(doc, m); err != nil {
+			return nil, fromVersion, fmt.Errorf("migration to v%d (%s) failed: %w", m.version, m.description, err)
+		}
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fromVersion, err
+	}
+
+	var file dataFile
+	if err := json.Unmarshal(migrated, &file); err != nil {
+		return nil, fromVersion, err
+	}
+	if file.Reservations == nil {
+		file.Reservations = make(map[string]*models.Reservation)
+	}
+	return file.Reservations, fromVersion, nil
+}
+
+// applyDocumentMigration はデータファイル全体に1つの移行処理を適用する
+func applyDocumentMigration(doc map[string]interface{}, m migration) (map[string]interface{}, error) {
+	var err error
+	if m.document != nil {
+		if doc, err = m.document(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.reservation != nil {
+		records, _ := doc["reservations"].(map[string]interface{})
+		for id, raw := range records {
+			record, ok := raw.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("reservation %s is not an object", id)
+			}
+			if err := m.reservation(record); err != nil {
+				return nil, fmt.Errorf("reservation %s: %w", id, err)
+			}
+		}
+	}
+
+	doc["schema_version"] = m.version
+	return doc, nil
+}
+
+// migrateReservationRecord は予約1件のJSONを fromVersion から現在のバージョンまで移行する
+func migrateReservationRecord(data []byte, fromVersion int) (*models.Reservation, error) {
+	var record map[string]interface{}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+
+	for _, m := range migrations {
+		if m.version <= fromVersion || m.reservation == nil {
+			continue
+		}
+		if err := m.reservation(record); err != nil {
+			return nil, fmt.Errorf("migration to v%d (%s) failed: %w", m.version, m.description, err)
+		}
+	}
+
+	migrated, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	var reservation models.Reservation
+	if err := json.Unmarshal(migrated, &reservation); err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// preMigrationBackupPath は移行前のデータを保存するパスを返す
+// 通常のローテーション対象（listBackups）とは別名にして、ロールバック用に残し続ける
+func preMigrationBackupPath(path string, fromVersion int) string {
+	name := fmt.Sprintf("%s-schema-v%d.%s", filepath.Base(path), fromVersion, time.Now().Format("20060102-150405"))
+	return filepath.Join(backupDir(path), name)
+}
+
+// backupBeforeMigration は移行前のデータファイルをそのまま保存する
+func backupBeforeMigration(path string, data []byte, fromVersion int) (string, error) {
+	backupPath := preMigrationBackupPath(path, fromVersion)
+	if err := os.MkdirAll(filepath.Dir(backupPath), 0755); err != nil {
+		return "", err
+	}
+	return backupPath, writeFileAtomic(backupPath, data, 0644)
+}
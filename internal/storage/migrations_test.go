@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestMigrationsAreOrdered(t *testing.T) {
+	for idx, m := range migrations {
+		if m.version != idx+1 {
+			t.Errorf("Migration %d has version %d, expected %d", idx, m.version, idx+1)
+		}
+	}
+	if migrations[len(migrations)-1].version != currentSchemaVersion {
+		t.Errorf("Last migration should target v%d", currentSchemaVersion)
+	}
+}
+
+func TestLoadMigratesLegacyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reservations.json")
+	legacy := []byte(`{
+  "legacy-1": {
+    "id": "legacy-1",
+    "user_id": "user1",
+    "username": "Test User",
+    "date": "2030-01-10",
+    "start_time": "10:00",
+    "end_time": "11:00",
+    "status": "pending"
+  }
+}`)
+	if err := os.WriteFile(path, legacy, 0644); err != nil {
+		t.Fatalf("Failed to write legacy file: %v", err)
+	}
+
+	store := NewStorageWithPath(path)
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	migrated, err := store.GetReservation("legacy-1")
+	if err != nil {
+		t.Fatalf("Expected legacy reservation to be loaded: %v", err)
+	}
+	if migrated.ResourceID != models.DefaultResourceID {
+		t.Errorf("Expected legacy reservation to be assigned to %q, got %q", models.DefaultResourceID, migrated.ResourceID)
+	}
+
+	// 移行後のファイルにはバージョンが記録される
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read migrated file: %v", err)
+	}
+	var file dataFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("Migrated file is not a valid envelope: %v", err)
+	}
+	if file.SchemaVersion != currentSchemaVersion || len(file.Reservations) != 1 {
+		t.Errorf("Unexpected migrated file: version=%d reservations=%d", file.SchemaVersion, len(file.Reservations))
+	}
+
+	// 移行前のファイルがそのまま残っている
+	matches, err := filepath.Glob(filepath.Join(backupDir(path), "reservations.json-schema-v0.*"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("Expected one pre-migration backup, got %v (%v)", matches, err)
+	}
+	backup, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatalf("Failed to read pre-migration backup: %v", err)
+	}
+	if !bytes.Equal(backup, legacy) {
+		t.Error("Pre-migration backup does not match the original file")
+	}
+}
+
+func TestLoadRejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reservations.json")
+	future := []byte(`{"schema_version": 999, "reservations": {}}`)
+	if err := os.WriteFile(path, future, 0644); err != nil {
+		t.Fatalf("Failed to write data file: %v", err)
+	}
+
+	store := NewStorageWithPath(path)
+	if err := store.Load(); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("Expected ErrSchemaTooNew, got %v", err)
+	}
+
+	// 新しい形式のファイルは書き換えない
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read data file: %v", err)
+	}
+	if !bytes.Equal(data, future) {
+		t.Error("Data file with a newer schema must not be modified")
+	}
+}
+
+func TestSQLiteRecordsSchemaVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reservations.db")
+	store := NewSQLiteStorage(path)
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	defer store.Close()
+
+	var version int
+	if err := store.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatalf("Failed to read user_version: %v", err)
+	}
+	if version != currentSchemaVersion {
+		t.Errorf("Expected user_version %d, got %d", currentSchemaVersion, version)
+	}
+}
+
+func TestSQLiteMigratesExistingRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reservations.db")
+	store := NewSQLiteStorage(path)
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// 部屋導入前（v1）のデータベースを再現する
+	if _, err := store.db.Exec(
+		`INSERT INTO reservations (id, user_id, date, start_time, end_time, status, updated_at, data) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		"old-1", "user1", "2030-01-10", "10:00", "11:00", "pending", 0,
+		`{"id":"old-1","user_id":"user1","date":"2030-01-10","start_time":"10:00","end_time":"11:00","status":"pending"}`,
+	); err != nil {
+		t.Fatalf("Failed to insert legacy row: %v", err)
+	}
+	if _, err := store.db.Exec(`PRAGMA user_version = 1`); err != nil {
+		t.Fatalf("Failed to set user_version: %v", err)
+	}
+	store.Close()
+
+	reopened := NewSQLiteStorage(path)
+	if err := reopened.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	defer reopened.Close()
+
+	r, err := reopened.GetReservation("old-1")
+	if err != nil {
+		t.Fatalf("GetReservation failed: %v", err)
+	}
+	if r.ResourceID != models.DefaultResourceID {
+		t.Errorf("Expected row to be migrated to %q, got %q", models.DefaultResourceID, r.ResourceID)
+	}
+	if r.EndDate != "2030-01-10" {
+		t.Errorf("Expected end date to be filled from date, got %q", r.EndDate)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(backupDir(path), "reservations.db-schema-v1.*"))
+	if err != nil || len(matches) != 1 {
+		t.Errorf("Expected one pre-migration database copy, got %v (%v)", matches, err)
+	}
+}
+
+func TestSQLiteAddsEndDateColumnToOldDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reservations.db")
+
+	// end_date 列がない（v2以前の）テーブルを再現する
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE reservations (
+			id TEXT PRIMARY KEY, user_id TEXT NOT NULL, date TEXT NOT NULL, start_time TEXT NOT NULL,
+			end_time TEXT NOT NULL, status TEXT NOT NULL, updated_at INTEGER NOT NULL, data TEXT NOT NULL
+		)`,
+		`INSERT INTO reservations VALUES ('old-1', 'user1', '2030-01-10', '10:00', '11:00', 'pending', 0,
+			'{"id":"old-1","user_id":"user1","date":"2030-01-10","start_time":"10:00","end_time":"11:00","status":"pending","resource_id":"main"}')`,
+		`PRAGMA user_version = 2`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to prepare legacy database: %v", err)
+		}
+	}
+	db.Close()
+
+	store := NewSQLiteStorage(path)
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	defer store.Close()
+
+	conflict, err := store.CheckOverlap(newTestReservation("new", "user2", "2030-01-10", "10:30", "11:30"))
+	if err != nil {
+		t.Fatalf("CheckOverlap failed: %v", err)
+	}
+	if conflict == nil || conflict.ID != "old-1" {
+		t.Errorf("Expected migrated row to be found by overlap check, got %v", conflict)
+	}
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// DefaultPenaltiesPath は違反の記録ファイルの既定のパス
+const DefaultPenaltiesPath = "data/penalties.json"
+
+// Penalties はユーザーごとの違反の記録をJSONファイルで管理する
+// 予約は完了後30日で削除されるため、違反は予約とは別に保存する
+type Penalties struct {
+	mu    sync.RWMutex
+	path  string
+	users map[string][]models.Strike
+}
+
+// NewPenalties は指定したファイルを使う違反の記録を作成する
+func NewPenalties(path string) *Penalties {
+	return &Penalties{
+		path:  path,
+		users: make(map[string][]models.Strike),
+	}
+}
+
+// Load はファイルから違反の記録を読み込む（ファイルがない場合は空の記録）
+func (p *Penalties) Load() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	data, err := os.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	users := make(map[string][]models.Strike)
+	if err := json.Unmarshal(data, &users); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", p.path, err)
+	}
+	p.users = users
+	return nil
+}
+
+// Strikes はユーザーの違反の記録のコピーを返す
+func (p *Penalties) Strikes(userID string) []models.Strike {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]models.Strike{}, p.users[userID]...)
+}
+
+// All は違反の記録があるすべてのユーザーの記録のコピーを返す
+func (p *Penalties) All() map[string][]models.Strike {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	users := make(map[string][]models.Strike, len(p.users))
+	for id, strikes := range p.users {
+		users[id] = append([]models.Strike{}, strikes...)
+	}
+	return users
+}
+
+// AddStrike はユーザーに違反を記録してファイルに書き込む
+// 同じ予約・同じ種類の違反が記録済みの場合は何もせず false を返す
+func (p *Penalties) AddStrike(userID string, strike models.Strike) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range p.users[userID] {
+		if s.ReservationID == strike.ReservationID && s.Kind == strike.Kind {
+			return false, nil
+		}
+	}
+	strikes := append(append([]models.Strike{}, p.users[userID]...), strike)
+	if err := p.write(userID, strikes); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Reset はユーザーの違反の記録を消去してファイルに書き込み、消去した件数を返す
+func (p *Penalties) Reset(userID string) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	count := len(p.users[userID])
+	if count == 0 {
+		return 0, nil
+	}
+	if err := p.write(userID, nil); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// write はユーザーの記録を置き換えてファイルに書き込む（書き込みに失敗した場合は変更しない）
+// 呼び出し側でロックを取得しておくこと
+func (p *Penalties) write(userID string, strikes []models.Strike) error {
+	users := make(map[string][]models.Strike, len(p.users)+1)
+	for id, s := range p.users {
+		users[id] = s
+	}
+	if len(strikes) == 0 {
+		delete(users, userID)
+	} else {
+		users[userID] = strikes
+	}
+
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(p.path, data, 0644); err != nil {
+		return err
+	}
+	p.users = users
+	return nil
+}
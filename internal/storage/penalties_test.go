@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestPenaltiesPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "penalties.json")
+	penalties := NewPenalties(path)
+	if err := penalties.Load(); err != nil {
+		t.Fatalf("Load without a file failed: %v", err)
+	}
+
+	strike := models.Strike{At: time.Date(2030, 1, 10, 14, 15, 0, 0, time.UTC), Kind: models.StrikeNoShow, ReservationID: "a"}
+	if added, err := penalties.AddStrike("user1", strike); err != nil || !added {
+		t.Fatalf("AddStrike failed: %v (added %v)", err, added)
+	}
+	// 同じ予約の同じ違反は二重に記録しない
+	if added, err := penalties.AddStrike("user1", strike); err != nil || added {
+		t.Errorf("Expected a duplicate strike to be ignored, got %v (%v)", added, err)
+	}
+
+	reloaded := NewPenalties(path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := reloaded.Strikes("user1"); len(got) != 1 || got[0].ReservationID != "a" || !got[0].At.Equal(strike.At) {
+		t.Errorf("Unexpected strikes after reload: %+v", got)
+	}
+
+	if count, err := reloaded.Reset("user1"); err != nil || count != 1 {
+		t.Fatalf("Reset failed: %v (count %d)", err, count)
+	}
+	if len(reloaded.All()) != 0 {
+		t.Errorf("Expected no strikes after reset, got %+v", reloaded.All())
+	}
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// DefaultPreferencesPath はユーザー設定ファイルの既定のパス
+const DefaultPreferencesPath = "data/preferences.json"
+
+// Preferences はユーザーごとの設定をJSONファイルで管理する
+// 設定の変更は頻度が低いため、変更のたびにファイルへ書き込む
+type Preferences struct {
+	mu    sync.RWMutex
+	path  string
+	users map[string]models.UserSettings
+}
+
+// NewPreferences は指定したファイルを使うユーザー設定を作成する
+func NewPreferences(path string) *Preferences {
+	return &Preferences{
+		path:  path,
+		users: make(map[string]models.UserSettings),
+	}
+}
+
+// Load はファイルからユーザー設定を読み込む（ファイルがない場合は空の設定）
+func (p *Preferences) Load() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	data, err := os.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	users := make(map[string]models.UserSettings)
+	if err := json.Unmarshal(data, &users); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", p.path, err)
+	}
+	p.users = users
+	return nil
+}
+
+// Get はユーザーの設定を返す（未設定の場合はゼロ値）
+func (p *Preferences) Get(userID string) models.UserSettings {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.users[userID]
+}
+
+// Update はユーザーの設定を変更してファイルに書き込む（書き込みに失敗した場合は変更しない）
+func (p *Preferences) Update(userID string, fn func(*models.UserSettings)) (models.UserSettings, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	settings := p.users[userID]
+	fn(&settings)
+
+	users := make(map[string]models.UserSettings, len(p.users)+1)
+	for id, s := range p.users {
+		users[id] = s
+	}
+	if settings == (models.UserSettings{}) {
+		delete(users, userID)
+	} else {
+		users[userID] = settings
+	}
+
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return p.users[userID], err
+	}
+	if err := writeFileAtomic(p.path, data, 0644); err != nil {
+		return p.users[userID], err
+	}
+	p.users = users
+	return settings, nil
+}
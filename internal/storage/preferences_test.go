@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestPreferencesPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preferences.json")
+	prefs := NewPreferences(path)
+	if err := prefs.Load(); err != nil {
+		t.Fatalf("Load without a file failed: %v", err)
+	}
+	if prefs.Get("user1").ReminderOptOut {
+		t.Fatal("Expected reminders to be enabled by default")
+	}
+
+	if _, err := prefs.Update("user1", func(s *models.UserSettings) { s.ReminderOptOut = true }); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	reloaded := NewPreferences(path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reloaded.Get("user1").ReminderOptOut || reloaded.Get("user2").ReminderOptOut {
+		t.Errorf("Unexpected settings after reload: %+v %+v", reloaded.Get("user1"), reloaded.Get("user2"))
+	}
+
+	// 既定値に戻した設定はファイルから取り除く
+	if _, err := reloaded.Update("user1", func(s *models.UserSettings) { s.ReminderOptOut = false }); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if len(reloaded.users) != 0 {
+		t.Errorf("Expected default settings to be dropped, got %+v", reloaded.users)
+	}
+}
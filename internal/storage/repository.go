@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// バックエンド名
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
+)
+
+const defaultSQLitePath = "data/reservations.db"
+
+// Repository は予約データの永続化バックエンドが満たすインターフェース
+// 取得系のメソッドは常に予約のコピーを返すため、呼び出し側で変更しても保存済みデータには影響しない
+// 保存済みの予約を変更する場合は Mutate・UpdateReservation・UpdateIfFree を使う
+type Repository interface {
+	// Load はバックエンドを初期化し、既存データを読み込む
+	Load() error
+	// Save は未保存の変更を永続化する（即時書き込みのバックエンドでは何もしない）
+	Save() error
+	// Close はバックエンドが保持するリソースを解放する
+	Close() error
+
+	AddReservation(reservation *models.Reservation) error
+	GetReservation(id string) (*models.Reservation, error)
+	UpdateReservation(reservation *models.Reservation) error
+	// Mutate は予約をロック（トランザクション）内で変更し、変更後のコピーを返す
+	Mutate(id string, fn func(*models.Reservation) error) (*models.Reservation, error)
+	DeleteReservation(id string) error
+	GetAllReservations() []*models.Reservation
+	GetUserReservations(userID string) []*models.Reservation
+	// QueryReservations は条件に一致する予約を取得する
+	QueryReservations(query Query) ([]*models.Reservation, error)
+	// CheckOverlap は時間が重複する予約を返す（重複がなければnil）
+	CheckOverlap(newReservation *models.Reservation) (*models.Reservation, error)
+	// ReserveIfFree は重複チェックと追加を不可分に行い、重複があれば追加せずにその予約を返す
+	ReserveIfFree(reservation *models.Reservation) (*models.Reservation, error)
+	// UpdateIfFree は予約をロック（トランザクション）内で変更し、重複チェックと更新を不可分に行う
+	// 重複がなければ変更後のコピーを、重複があれば更新せずにその予約を返す
+	UpdateIfFree(id string, fn func(*models.Reservation) error) (updated, conflict *models.Reservation, err error)
+
+	AutoCompleteExpiredReservations() (int, error)
+	CleanupOldReservations(retentionDays int) (int, error)
+}
+
+// Query は予約検索の条件を表す（ゼロ値の項目は条件に含めない）
+type Query struct {
+	UserID     string                     // 予約者のDiscord ID
+	ResourceID string                     // 部屋ID
+	Statuses   []models.ReservationStatus // いずれかに一致するステータス
+	DateFrom   string                     // この日付以降に終わる（YYYY-MM-DD形式、日を跨ぐ予約は終了日で判定）
+	DateTo     string                     // この日付以前（YYYY-MM-DD形式）
+	SeriesID   string                     // 繰り返し予約のシリーズID
+}
+
+// Matches は予約が検索条件に一致するかを返す
+func (q Query) Matches(r *models.Reservation) bool {
+	if q.UserID != "" && r.UserID != q.UserID {
+		return false
+	}
+	if q.ResourceID != "" && r.GetResourceID() != q.ResourceID {
+		return false
+	}
+	if len(q.Statuses) > 0 {
+		matched := false
+		for _, status := range q.Statuses {
+			if r.Status == status {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if q.DateFrom != "" && r.GetEndDate() < q.DateFrom {
+		return false
+	}
+	if q.DateTo != "" && r.Date > q.DateTo {
+		return false
+	}
+	if q.SeriesID != "" && r.SeriesID != q.SeriesID {
+		return false
+	}
+	return true
+}
+
+// Open は指定されたバックエンドのRepositoryを作成する
+// path が空の場合は各バックエンドの既定のパスを使用する
+func Open(backend, path string) (Repository, error) {
+	switch backend {
+	case "", BackendJSON:
+		if path == "" {
+			path = dataFilePath
+		}
+		return NewStorageWithPath(path), nil
+	case BackendSQLite:
+		if path == "" {
+			path = defaultSQLitePath
+		}
+		return NewSQLiteStorage(path), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", backend)
+	}
+}
+
+var (
+	_ Repository = (*Storage)(nil)
+	_ Repository = (*SQLiteStorage)(nil)
+)
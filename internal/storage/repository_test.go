@@ -0,0 +1,351 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// newTestRepositories はテスト用に各バックエンドのRepositoryを作成する
+func newTestRepositories(t *testing.T) map[string]Repository {
+	t.Helper()
+	dir := t.TempDir()
+
+	repos := map[string]Repository{
+		BackendJSON:   NewStorageWithPath(filepath.Join(dir, "reservations.json")),
+		BackendSQLite: NewSQLiteStorage(filepath.Join(dir, "reservations.db")),
+	}
+	for name, repo := range repos {
+		if err := repo.Load(); err != nil {
+			t.Fatalf("%s: Load failed: %v", name, err)
+		}
+		t.Cleanup(func() { repo.Close() })
+	}
+	return repos
+}
+
+func newTestReservation(id, userID, date, start, end string) *models.Reservation {
+	return &models.Reservation{
+		ID:        id,
+		UserID:    userID,
+		Username:  "Test User",
+		Date:      date,
+		StartTime: start,
+		EndTime:   end,
+		Status:    models.StatusPending,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		ChannelID: "channel1",
+	}
+}
+
+func TestRepositoryCRUD(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			r := newTestReservation("crud-1", "user1", "2030-01-10", "10:00", "11:00")
+			if err := repo.AddReservation(r); err != nil {
+				t.Fatalf("AddReservation failed: %v", err)
+			}
+			if err := repo.AddReservation(r); err != ErrAlreadyExists {
+				t.Errorf("Expected ErrAlreadyExists, got %v", err)
+			}
+
+			got, err := repo.GetReservation("crud-1")
+			if err != nil {
+				t.Fatalf("GetReservation failed: %v", err)
+			}
+			got.Comment = "updated"
+			if err := repo.UpdateReservation(got); err != nil {
+				t.Fatalf("UpdateReservation failed: %v", err)
+			}
+
+			got, err = repo.GetReservation("crud-1")
+			if err != nil {
+				t.Fatalf("GetReservation failed: %v", err)
+			}
+			if got.Comment != "updated" {
+				t.Errorf("Expected comment to be updated, got %q", got.Comment)
+			}
+
+			if err := repo.DeleteReservation("crud-1"); err != nil {
+				t.Fatalf("DeleteReservation failed: %v", err)
+			}
+			if _, err := repo.GetReservation("crud-1"); err != ErrNotFound {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRepositoryQueryAndOverlap(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			cancelled := newTestReservation("q-3", "user1", "2030-01-10", "13:00", "14:00")
+			cancelled.Status = models.StatusCancelled
+			for _, r := range []*models.Reservation{
+				newTestReservation("q-1", "user1", "2030-01-10", "10:00", "11:00"),
+				newTestReservation("q-2", "user2", "2030-01-11", "10:00", "11:00"),
+				cancelled,
+			} {
+				if err := repo.AddReservation(r); err != nil {
+					t.Fatalf("AddReservation failed: %v", err)
+				}
+			}
+
+			results, err := repo.QueryReservations(Query{
+				UserID:   "user1",
+				Statuses: []models.ReservationStatus{models.StatusPending},
+			})
+			if err != nil {
+				t.Fatalf("QueryReservations failed: %v", err)
+			}
+			if len(results) != 1 || results[0].ID != "q-1" {
+				t.Errorf("Expected only q-1, got %v", results)
+			}
+
+			results, err = repo.QueryReservations(Query{DateFrom: "2030-01-11", DateTo: "2030-01-11"})
+			if err != nil {
+				t.Fatalf("QueryReservations failed: %v", err)
+			}
+			if len(results) != 1 || results[0].ID != "q-2" {
+				t.Errorf("Expected only q-2, got %v", results)
+			}
+
+			conflict, err := repo.CheckOverlap(newTestReservation("new", "user3", "2030-01-10", "10:30", "11:30"))
+			if err != nil {
+				t.Fatalf("CheckOverlap failed: %v", err)
+			}
+			if conflict == nil || conflict.ID != "q-1" {
+				t.Errorf("Expected overlap with q-1, got %v", conflict)
+			}
+
+			// キャンセル済みの予約とは重複しない
+			conflict, err = repo.CheckOverlap(newTestReservation("new", "user3", "2030-01-10", "13:00", "14:00"))
+			if err != nil {
+				t.Fatalf("CheckOverlap failed: %v", err)
+			}
+			if conflict != nil {
+				t.Errorf("Expected no overlap, got %v", conflict)
+			}
+		})
+	}
+}
+
+func TestOpenUnknownBackend(t *testing.T) {
+	if _, err := Open("postgres", ""); err == nil {
+		t.Error("Expected error for unknown backend")
+	}
+}
+
+func TestRepositoryOverlapIsScopedPerResource(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			main := newTestReservation("room-1", "user1", "2030-05-01", "10:00", "12:00")
+			main.ResourceID = models.DefaultResourceID
+			if err := repo.AddReservation(main); err != nil {
+				t.Fatalf("AddReservation failed: %v", err)
+			}
+
+			// 別の部屋なら同じ時間帯でも予約できる
+			meeting := newTestReservation("room-2", "user2", "2030-05-01", "10:30", "11:30")
+			meeting.ResourceID = "meeting"
+			conflict, err := repo.ReserveIfFree(meeting)
+			if err != nil || conflict != nil {
+				t.Fatalf("Expected reservation in another room to succeed, got conflict=%v err=%v", conflict, err)
+			}
+
+			// 部屋未設定の予約は既定の部屋として扱う
+			unassigned := newTestReservation("room-3", "user3", "2030-05-01", "11:00", "11:30")
+			conflict, err = repo.CheckOverlap(unassigned)
+			if err != nil {
+				t.Fatalf("CheckOverlap failed: %v", err)
+			}
+			if conflict == nil || conflict.ID != "room-1" {
+				t.Errorf("Expected overlap with room-1, got %v", conflict)
+			}
+
+			results, err := repo.QueryReservations(Query{ResourceID: "meeting"})
+			if err != nil {
+				t.Fatalf("QueryReservations failed: %v", err)
+			}
+			if len(results) != 1 || results[0].ID != "room-2" {
+				t.Errorf("Expected only room-2, got %v", results)
+			}
+		})
+	}
+}
+
+func TestRepositoryQueryBySeries(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			rule := &models.Recurrence{Frequency: models.FrequencyWeekly, Count: 2}
+			for _, r := range []*models.Reservation{
+				newTestReservation("s-1", "user1", "2030-01-10", "10:00", "11:00"),
+				newTestReservation("s-2", "user1", "2030-01-17", "10:00", "11:00"),
+				newTestReservation("single", "user1", "2030-01-24", "10:00", "11:00"),
+			} {
+				if r.ID != "single" {
+					r.SeriesID = "series-a"
+					r.Recurrence = rule
+				}
+				if err := repo.AddReservation(r); err != nil {
+					t.Fatalf("AddReservation failed: %v", err)
+				}
+			}
+
+			results, err := repo.QueryReservations(Query{SeriesID: "series-a"})
+			if err != nil {
+				t.Fatalf("QueryReservations failed: %v", err)
+			}
+			if len(results) != 2 {
+				t.Fatalf("Expected 2 occurrences, got %d", len(results))
+			}
+			for _, r := range results {
+				if r.Recurrence == nil || r.Recurrence.Frequency != models.FrequencyWeekly {
+					t.Errorf("Expected recurrence rule to be stored, got %v", r.Recurrence)
+				}
+			}
+		})
+	}
+}
+
+func TestRepositoryOverlapAcrossMidnight(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			overnight := newTestReservation("night", "user1", "2030-01-10", "20:00", "02:00")
+			overnight.EndDate = "2030-01-11"
+			if err := repo.AddReservation(overnight); err != nil {
+				t.Fatalf("AddReservation failed: %v", err)
+			}
+
+			conflict, err := repo.CheckOverlap(newTestReservation("morning", "user2", "2030-01-11", "01:00", "03:00"))
+			if err != nil {
+				t.Fatalf("CheckOverlap failed: %v", err)
+			}
+			if conflict == nil || conflict.ID != "night" {
+				t.Errorf("Expected overlap with the overnight reservation, got %v", conflict)
+			}
+
+			conflict, err = repo.CheckOverlap(newTestReservation("later", "user2", "2030-01-11", "02:00", "03:00"))
+			if err != nil {
+				t.Fatalf("CheckOverlap failed: %v", err)
+			}
+			if conflict != nil {
+				t.Errorf("Expected no overlap after the overnight reservation ends, got %v", conflict)
+			}
+
+			// 終了日で検索しても日を跨ぐ予約が含まれる
+			results, err := repo.QueryReservations(Query{DateFrom: "2030-01-11", DateTo: "2030-01-11"})
+			if err != nil {
+				t.Fatalf("QueryReservations failed: %v", err)
+			}
+			if len(results) != 1 || results[0].ID != "night" {
+				t.Errorf("Expected the overnight reservation, got %v", results)
+			}
+		})
+	}
+}
+
+// useClubTimezone はテスト中だけ部室のタイムゾーンとサーバーのローカルタイムゾーンを切り替える
+func useClubTimezone(t *testing.T, club, local *time.Location) {
+	t.Helper()
+	oldClub, oldLocal := models.Location(), time.Local
+	models.SetLocation(club)
+	time.Local = local
+	t.Cleanup(func() {
+		models.SetLocation(oldClub)
+		time.Local = oldLocal
+	})
+}
+
+// newReservationBetween は部室のタイムゾーンでの開始・終了時刻から予約を作成する
+func newReservationBetween(id string, start, end time.Time) *models.Reservation {
+	start, end = start.In(models.Location()), end.In(models.Location())
+	r := newTestReservation(id, "user1", start.Format("2006-01-02"), start.Format("15:04"), end.Format("15:04"))
+	r.EndDate = end.Format("2006-01-02")
+	return r
+}
+
+func TestAutoCompleteUsesClubTimezone(t *testing.T) {
+	// サーバーのローカルタイムゾーンと部室のタイムゾーンが19時間ずれている環境
+	useClubTimezone(t, time.FixedZone("UTC-10", -10*60*60), time.FixedZone("UTC+9", 9*60*60))
+
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			for _, r := range []*models.Reservation{
+				newReservationBetween("ended", now.Add(-3*time.Hour), now.Add(-time.Hour)),
+				newReservationBetween("upcoming", now.Add(time.Hour), now.Add(2*time.Hour)),
+			} {
+				if err := repo.AddReservation(r); err != nil {
+					t.Fatalf("AddReservation failed: %v", err)
+				}
+			}
+
+			count, err := repo.AutoCompleteExpiredReservations()
+			if err != nil {
+				t.Fatalf("AutoCompleteExpiredReservations failed: %v", err)
+			}
+			if count != 1 {
+				t.Errorf("Expected 1 reservation to be completed, got %d", count)
+			}
+
+			for id, want := range map[string]models.ReservationStatus{
+				"ended":    models.StatusCompleted,
+				"upcoming": models.StatusPending,
+			} {
+				r, err := repo.GetReservation(id)
+				if err != nil {
+					t.Fatalf("GetReservation failed: %v", err)
+				}
+				if r.Status != want {
+					t.Errorf("Expected %s to be %s, got %s", id, want, r.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestRepositoryCheckInStatuses(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			inUse := newReservationBetween("in-use", now.Add(-3*time.Hour), now.Add(-time.Hour))
+			inUse.Status = models.StatusCheckedIn
+			noShow := newReservationBetween("no-show", now.Add(time.Hour), now.Add(2*time.Hour))
+			noShow.Status = models.StatusNoShow
+			noShow.UpdatedAt = now.AddDate(0, 0, -31)
+			for _, r := range []*models.Reservation{inUse, noShow} {
+				if err := repo.AddReservation(r); err != nil {
+					t.Fatalf("AddReservation failed: %v", err)
+				}
+			}
+
+			// 利用中の予約は時間帯を埋め、無断欠席で解放された予約は埋めない
+			overlapping, err := repo.CheckOverlap(newReservationBetween("new", now.Add(-2*time.Hour), now.Add(90*time.Minute)))
+			if err != nil {
+				t.Fatalf("CheckOverlap failed: %v", err)
+			}
+			if overlapping == nil || overlapping.ID != "in-use" {
+				t.Errorf("Expected the checked-in reservation to overlap, got %+v", overlapping)
+			}
+			if overlapping, _ := repo.CheckOverlap(newReservationBetween("new", now.Add(time.Hour), now.Add(2*time.Hour))); overlapping != nil {
+				t.Errorf("Expected the released slot to be free, got %+v", overlapping)
+			}
+
+			// 終了した利用中の予約は自動完了の対象
+			if count, err := repo.AutoCompleteExpiredReservations(); err != nil || count != 1 {
+				t.Errorf("Expected 1 reservation to be completed, got %d (%v)", count, err)
+			}
+			// 古い無断欠席の予約は削除の対象
+			if count, err := repo.CleanupOldReservations(30); err != nil || count != 1 {
+				t.Errorf("Expected 1 reservation to be deleted, got %d (%v)", count, err)
+			}
+			if _, err := repo.GetReservation("no-show"); err != ErrNotFound {
+				t.Errorf("Expected the no-show reservation to be deleted, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// LoadResources は部屋・設備の設定ファイル（JSON配列）を読み込む
+// ファイルが存在しない場合は既定の部屋のみを返す
+// 既存の予約は既定の部屋（models.DefaultResourceID）に移行されるため、設定に含まれていなければ先頭に追加する
+func LoadResources(path string) ([]models.Resource, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []models.Resource{models.DefaultResource()}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []models.Resource
+	if err := json.Unmarshal(data, &resources); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		if resource.ID == "" || resource.Name == "" {
+			return nil, fmt.Errorf("%s: every resource needs an id and a name", path)
+		}
+		if seen[resource.ID] {
+			return nil, fmt.Errorf("%s: duplicate resource id %q", path, resource.ID)
+		}
+		seen[resource.ID] = true
+	}
+
+	if !seen[models.DefaultResourceID] {
+		resources = append([]models.Resource{models.DefaultResource()}, resources...)
+	}
+	return resources, nil
+}
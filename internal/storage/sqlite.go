@@ -0,0 +1,595 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+	_ "modernc.org/sqlite" // database/sql 用のpure-Go SQLiteドライバ
+)
+
+// sqliteSchema は予約テーブルとインデックスの定義
+// 検索に使う列だけを個別に持ち、予約全体はdata列にJSONで保存する
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS reservations (
+		id         TEXT PRIMARY KEY,
+		user_id    TEXT NOT NULL,
+		date       TEXT NOT NULL,
+		end_date   TEXT NOT NULL DEFAULT '',
+		start_time TEXT NOT NULL,
+		end_time   TEXT NOT NULL,
+		status     TEXT NOT NULL,
+		updated_at INTEGER NOT NULL,
+		data       TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_reservations_user ON reservations(user_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_reservations_date_status ON reservations(date, status)`,
+	`CREATE INDEX IF NOT EXISTS idx_reservations_status_updated ON reservations(status, updated_at)`,
+}
+
+// sqliteAddedColumns は作成後に追加した列の定義（古いデータベースに不足している場合に追加する）
+// 既存の行の値は移行処理（migrate）で埋める
+var sqliteAddedColumns = []struct {
+	name       string
+	definition string
+}{
+	{name: "end_date", definition: "TEXT NOT NULL DEFAULT ''"},
+}
+
+// SQLiteStorage は予約データをSQLiteデータベースで管理する
+type SQLiteStorage struct {
+	path string
+	db   *sql.DB
+}
+
+// execer は *sql.DB と *sql.Tx の共通メソッド
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// NewSQLiteStorage は新しいSQLiteStorageインスタンスを作成する（接続はLoadで開く）
+func NewSQLiteStorage(path string) *SQLiteStorage {
+	return &SQLiteStorage{path: path}
+}
+
+// Load はデータベースを開き、スキーマを作成する
+func (s *SQLiteStorage) Load() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	// _txlock=immediate: トランザクション開始時に書き込みロックを取り、重複チェックと書き込みの間に他の書き込みを挟ませない
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_txlock=immediate", s.path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return err
+	}
+	// SQLiteは書き込みが直列化されるため、接続を1本に絞ってロック競合を避ける
+	db.SetMaxOpenConns(1)
+
+	for _, stmt := range sqliteSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return fmt.Errorf("failed to initialize sqlite schema: %w", err)
+		}
+	}
+	if err := addMissingSQLiteColumns(db); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to initialize sqlite schema: %w", err)
+	}
+
+	s.db = db
+	if err := s.migrate(); err != nil {
+		db.Close()
+		s.db = nil
+		return err
+	}
+	return nil
+}
+
+// addMissingSQLiteColumns は古いデータベースに不足している列を追加する
+func addMissingSQLiteColumns(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('reservations')`)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, column := range sqliteAddedColumns {
+		if existing[column.name] {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf(`ALTER TABLE reservations ADD COLUMN %s %s`, column.name, column.definition)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrate はPRAGMA user_versionに記録したバージョンから現在のバージョンまで予約データを移行する
+// 移行前にはデータベース全体のコピーを保存する
+func (s *SQLiteStorage) migrate() error {
+	var version int
+	if err := s.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+	if version > currentSchemaVersion {
+		return fmt.Errorf("%w: database is v%d, supported up to v%d", ErrSchemaTooNew, version, currentSchemaVersion)
+	}
+	if version == currentSchemaVersion {
+		return nil
+	}
+
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM reservations`).Scan(&count); err != nil {
+		return err
+	}
+
+	backupPath := ""
+	if count > 0 {
+		backupPath = preMigrationBackupPath(s.path, version)
+		if err := os.MkdirAll(filepath.Dir(backupPath), 0755); err != nil {
+			return err
+		}
+		if _, err := s.db.Exec(`VACUUM INTO ?`, backupPath); err != nil {
+			return fmt.Errorf("failed to back up database before migration: %w", err)
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT data FROM reservations`)
+	if err != nil {
+		return err
+	}
+	records := make([]string, 0, count)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			rows.Close()
+			return err
+		}
+		records = append(records, data)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, data := range records {
+		reservation, err := migrateReservationRecord([]byte(data), version)
+		if err != nil {
+			return err
+		}
+		if err := updateSQLiteReservation(tx, reservation); err != nil {
+			return err
+		}
+	}
+
+	// PRAGMAはプレースホルダを使えないため、定数から組み立てる
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, currentSchemaVersion)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if backupPath != "" {
+		log.Printf("🔄 Migrated %s from schema v%d to v%d (previous database: %s)", s.path, version, currentSchemaVersion, backupPath)
+	}
+	return nil
+}
+
+// Save はSQLiteバックエンドでは何もしない（各操作が即座にコミットされる）
+func (s *SQLiteStorage) Save() error {
+	return nil
+}
+
+// Close はデータベース接続を閉じる
+func (s *SQLiteStorage) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// AddReservation は新しい予約を追加する
+func (s *SQLiteStorage) AddReservation(reservation *models.Reservation) error {
+	return insertSQLiteReservation(s.db, reservation)
+}
+
+// GetReservation は指定されたIDの予約を取得する
+func (s *SQLiteStorage) GetReservation(id string) (*models.Reservation, error) {
+	return getSQLiteReservation(s.db, id)
+}
+
+// UpdateReservation は予約情報を更新する
+func (s *SQLiteStorage) UpdateReservation(reservation *models.Reservation) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkSQLiteHistory(tx, reservation); err != nil {
+		return err
+	}
+	if err := updateSQLiteReservation(tx, reservation); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Mutate は指定されたIDの予約をトランザクション内で変更し、変更後のコピーを返す
+// fn がエラーを返した場合、予約は変更されない
+func (s *SQLiteStorage) Mutate(id string, fn func(*models.Reservation) error) (*models.Reservation, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stored, err := getSQLiteReservation(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	reservation := stored.Clone()
+	if err := fn(reservation); err != nil {
+		return nil, err
+	}
+	reservation.ID = id
+	if err := checkHistoryAppendOnly(stored, reservation); err != nil {
+		return nil, err
+	}
+
+	if err := updateSQLiteReservation(tx, reservation); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// DeleteReservation は指定されたIDの予約を削除する
+func (s *SQLiteStorage) DeleteReservation(id string) error {
+	result, err := s.db.Exec(`DELETE FROM reservations WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetAllReservations はすべての予約を取得する
+func (s *SQLiteStorage) GetAllReservations() []*models.Reservation {
+	reservations, err := querySQLiteReservations(s.db, `SELECT data FROM reservations`)
+	if err != nil {
+		log.Printf("Failed to query reservations: %v", err)
+		return []*models.Reservation{}
+	}
+	return reservations
+}
+
+// GetUserReservations は指定されたユーザーの予約を取得する
+func (s *SQLiteStorage) GetUserReservations(userID string) []*models.Reservation {
+	reservations, err := querySQLiteReservations(s.db, `SELECT data FROM reservations WHERE user_id = ?`, userID)
+	if err != nil {
+		log.Printf("Failed to query reservations for user %s: %v", userID, err)
+		return []*models.Reservation{}
+	}
+	return reservations
+}
+
+// QueryReservations は条件に一致する予約を取得する
+func (s *SQLiteStorage) QueryReservations(query Query) ([]*models.Reservation, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	if query.UserID != "" {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, query.UserID)
+	}
+	if query.ResourceID != "" {
+		conditions = append(conditions, "COALESCE(NULLIF(json_extract(data, '$.resource_id'), ''), ?) = ?")
+		args = append(args, models.DefaultResourceID, query.ResourceID)
+	}
+	if len(query.Statuses) > 0 {
+		placeholders := make([]string, len(query.Statuses))
+		for idx, status := range query.Statuses {
+			placeholders[idx] = "?"
+			args = append(args, string(status))
+		}
+		conditions = append(conditions, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if query.DateFrom != "" {
+		conditions = append(conditions, "end_date >= ?")
+		args = append(args, query.DateFrom)
+	}
+	if query.DateTo != "" {
+		conditions = append(conditions, "date <= ?")
+		args = append(args, query.DateTo)
+	}
+	if query.SeriesID != "" {
+		conditions = append(conditions, "json_extract(data, '$.series_id') = ?")
+		args = append(args, query.SeriesID)
+	}
+
+	sqlQuery := `SELECT data FROM reservations`
+	if len(conditions) > 0 {
+		sqlQuery += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	return querySQLiteReservations(s.db, sqlQuery, args...)
+}
+
+// CheckOverlap は時間の重複をチェックする
+func (s *SQLiteStorage) CheckOverlap(newReservation *models.Reservation) (*models.Reservation, error) {
+	return checkSQLiteOverlap(s.db, newReservation)
+}
+
+// ReserveIfFree は重複する予約がなければ予約を追加する
+// 重複チェックと追加は同じトランザクション内で行われ、重複があれば追加せずにその予約を返す
+func (s *SQLiteStorage) ReserveIfFree(reservation *models.Reservation) (*models.Reservation, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := getSQLiteReservation(tx, reservation.ID); err == nil {
+		return nil, ErrAlreadyExists
+	} else if err != ErrNotFound {
+		return nil, err
+	}
+
+	conflict, err := checkSQLiteOverlap(tx, reservation)
+	if err != nil || conflict != nil {
+		return conflict, err
+	}
+
+	if err := insertSQLiteReservation(tx, reservation); err != nil {
+		return nil, err
+	}
+	return nil, tx.Commit()
+}
+
+// UpdateIfFree は指定されたIDの予約をトランザクション内で変更し、重複する予約がなければ保存して変更後の予約を返す
+// 変更・重複チェック・更新は同じトランザクション内で行われ、重複があれば更新せずにその予約を返す
+// fn がエラーを返した場合、予約は変更されない
+func (s *SQLiteStorage) UpdateIfFree(id string, fn func(*models.Reservation) error) (updated, conflict *models.Reservation, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	stored, err := getSQLiteReservation(tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	updated = stored.Clone()
+	if err := fn(updated); err != nil {
+		return nil, nil, err
+	}
+	updated.ID = id
+	if err := checkHistoryAppendOnly(stored, updated); err != nil {
+		return nil, nil, err
+	}
+
+	conflict, err = checkSQLiteOverlap(tx, updated)
+	if err != nil || conflict != nil {
+		return nil, conflict, err
+	}
+
+	if err := updateSQLiteReservation(tx, updated); err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return updated, nil, nil
+}
+
+// AutoCompleteExpiredReservations は終了時刻が過ぎた予約中・利用中の予約を自動的にcompletedに変更する
+func (s *SQLiteStorage) AutoCompleteExpiredReservations() (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	pending, err := querySQLiteReservations(tx, `SELECT data FROM reservations WHERE status IN (?, ?)`,
+		string(models.StatusPending), string(models.StatusCheckedIn))
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	count := 0
+	for _, reservation := range pending {
+		endDateTime, err := reservation.GetEndDateTime()
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse end time for reservation %s: %w", reservation.ID, err)
+		}
+
+		if endDateTime.Before(now) {
+			before := reservation.Clone()
+			reservation.Status = models.StatusCompleted
+			reservation.UpdatedAt = now
+			reservation.RecordHistory(before, autoCompleteHistoryEntry(now))
+			if err := updateSQLiteReservation(tx, reservation); err != nil {
+				return 0, err
+			}
+			count++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CleanupOldReservations は古い完了済み・キャンセル済み・無断欠席の予約を削除する
+// retentionDays: 保持期間（日数）
+func (s *SQLiteStorage) CleanupOldReservations(retentionDays int) (int, error) {
+	cutoffTime := time.Now().AddDate(0, 0, -retentionDays)
+
+	result, err := s.db.Exec(
+		`DELETE FROM reservations WHERE status IN (?, ?, ?) AND updated_at < ?`,
+		string(models.StatusCompleted), string(models.StatusCancelled), string(models.StatusNoShow), cutoffTime.UnixNano(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// getSQLiteReservation はIDで予約を1件取得する
+func getSQLiteReservation(db execer, id string) (*models.Reservation, error) {
+	var data string
+	err := db.QueryRow(`SELECT data FROM reservations WHERE id = ?`, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var reservation models.Reservation
+	if err := json.Unmarshal([]byte(data), &reservation); err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// checkSQLiteHistory は保存済みの予約と比べて変更履歴が追記のみであるかを確認する
+func checkSQLiteHistory(db execer, reservation *models.Reservation) error {
+	stored, err := getSQLiteReservation(db, reservation.ID)
+	if err != nil {
+		return err
+	}
+	return checkHistoryAppendOnly(stored, reservation)
+}
+
+// insertSQLiteReservation は新しい予約を挿入する
+func insertSQLiteReservation(db execer, reservation *models.Reservation) error {
+	data, err := json.Marshal(reservation)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(
+		`INSERT INTO reservations (id, user_id, date, end_date, start_time, end_time, status, updated_at, data)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		reservation.ID, reservation.UserID, reservation.Date, reservation.GetEndDate(), reservation.StartTime, reservation.EndTime,
+		string(reservation.Status), reservation.UpdatedAt.UnixNano(), string(data),
+	)
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// updateSQLiteReservation は既存の予約を上書きする
+func updateSQLiteReservation(db execer, reservation *models.Reservation) error {
+	data, err := json.Marshal(reservation)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Exec(
+		`UPDATE reservations
+		 SET user_id = ?, date = ?, end_date = ?, start_time = ?, end_time = ?, status = ?, updated_at = ?, data = ?
+		 WHERE id = ?`,
+		reservation.UserID, reservation.Date, reservation.GetEndDate(), reservation.StartTime, reservation.EndTime,
+		string(reservation.Status), reservation.UpdatedAt.UnixNano(), string(data), reservation.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// checkSQLiteOverlap は期間の日付が重なる有効な予約だけを読み出して重複を判定する
+func checkSQLiteOverlap(db execer, newReservation *models.Reservation) (*models.Reservation, error) {
+	candidates, err := querySQLiteReservations(db,
+		`SELECT data FROM reservations WHERE date <= ? AND end_date >= ? AND id != ? AND status IN (?, ?)`,
+		newReservation.GetEndDate(), newReservation.Date, newReservation.ID, string(models.StatusPending), string(models.StatusCheckedIn),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, existing := range candidates {
+		overlaps, err := newReservation.OverlapsWith(existing)
+		if err != nil {
+			return nil, err
+		}
+		if overlaps {
+			return existing, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// querySQLiteReservations はdata列を返すクエリを実行して予約に変換する
+func querySQLiteReservations(db execer, query string, args ...interface{}) ([]*models.Reservation, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := make([]*models.Reservation, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		var reservation models.Reservation
+		if err := json.Unmarshal([]byte(data), &reservation); err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, &reservation)
+	}
+
+	return reservations, rows.Err()
+}
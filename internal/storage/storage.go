@@ -1,9 +1,11 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"sync"
 	"time"
@@ -13,39 +15,123 @@ import (
 
 const dataFilePath = "data/reservations.json"
 
-// Storage は予約データを管理する
+var (
+	// ErrNotFound は指定された予約が存在しない場合に返される
+	ErrNotFound = errors.New("reservation not found")
+	// ErrAlreadyExists は同じIDの予約が既に存在する場合に返される
+	ErrAlreadyExists = errors.New("reservation with this ID already exists")
+)
+
+// Storage は予約データをJSONファイルで管理する
+// 予約は常にコピーで受け渡し、内部のポインタを外部に公開しない
 type Storage struct {
 	mu           sync.RWMutex
-	Reservations map[string]*models.Reservation `json:"reservations"`
+	path         string
+	reservations map[string]*models.Reservation
 }
 
-// NewStorage は新しいStorageインスタンスを作成する
+// NewStorage は既定のデータファイルを使う新しいStorageインスタンスを作成する
 func NewStorage() *Storage {
+	return NewStorageWithPath(dataFilePath)
+}
+
+// NewStorageWithPath は指定したデータファイルを使う新しいStorageインスタンスを作成する
+func NewStorageWithPath(path string) *Storage {
 	return &Storage{
-		Reservations: make(map[string]*models.Reservation),
+		path:         path,
+		reservations: make(map[string]*models.Reservation),
 	}
 }
 
 // Load はファイルから予約データを読み込む
+// データファイルが壊れている場合は、最新の正常なバックアップから復旧する
 func (s *Storage) Load() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	// ファイルが存在しない場合は新規作成
-	if _, err := os.Stat(dataFilePath); os.IsNotExist(err) {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
 		return nil
 	}
 
-	data, err := os.ReadFile(dataFilePath)
+	data, err := os.ReadFile(s.path)
+	if err == nil {
+		if len(data) == 0 {
+			err = errors.New("data file is empty")
+		} else {
+			var reservations map[string]*models.Reservation
+			var fromVersion int
+			reservations, fromVersion, err = decodeDataFile(data)
+			if errors.Is(err, ErrSchemaTooNew) {
+				// 新しいリリースで書かれたファイルはバックアップで上書きせずに停止する
+				return err
+			}
+			if err == nil {
+				s.reservations = reservations
+				if fromVersion < currentSchemaVersion {
+					return s.completeMigrationLocked(data, fromVersion)
+				}
+				return nil
+			}
+		}
+	}
+
+	return s.recoverFromBackupLocked(err)
+}
+
+// completeMigrationLocked は移行前のファイルを保存してから、移行後のデータを書き込む（呼び出し側でロックを保持すること）
+func (s *Storage) completeMigrationLocked(original []byte, fromVersion int) error {
+	backupPath, err := backupBeforeMigration(s.path, original, fromVersion)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to back up data file before migration: %w", err)
+	}
+
+	if err := s.writeLocked(); err != nil {
+		return fmt.Errorf("failed to write migrated data file: %w", err)
 	}
 
-	if len(data) == 0 {
+	log.Printf("🔄 Migrated %s from schema v%d to v%d (previous file: %s)", s.path, fromVersion, currentSchemaVersion, backupPath)
+	return nil
+}
+
+// recoverFromBackupLocked は最新の正常なバックアップからデータを復元する（呼び出し側でロックを保持すること）
+// 壊れたデータファイルは調査用に別名で残す
+func (s *Storage) recoverFromBackupLocked(cause error) error {
+	backups, err := listBackups(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to load %s (%v) and failed to list backups: %w", s.path, cause, err)
+	}
+
+	for _, backup := range backups {
+		data, err := os.ReadFile(backup)
+		if err != nil {
+			log.Printf("⚠️ Skipping unreadable backup %s: %v", backup, err)
+			continue
+		}
+
+		reservations, _, err := decodeDataFile(data)
+		if err != nil {
+			log.Printf("⚠️ Skipping invalid backup %s: %v", backup, err)
+			continue
+		}
+
+		corruptPath := fmt.Sprintf("%s.corrupt-%s", s.path, time.Now().Format("20060102-150405"))
+		if err := os.Rename(s.path, corruptPath); err != nil {
+			log.Printf("⚠️ Failed to preserve corrupted data file: %v", err)
+		} else {
+			log.Printf("⚠️ Corrupted data file preserved as %s", corruptPath)
+		}
+
+		s.reservations = reservations
+		if err := s.writeLocked(); err != nil {
+			return fmt.Errorf("failed to restore %s from backup %s: %w", s.path, backup, err)
+		}
+
+		log.Printf("♻️ Restored %d reservation(s) from backup %s (cause: %v)", len(reservations), backup, cause)
 		return nil
 	}
 
-	return json.Unmarshal(data, &s.Reservations)
+	return fmt.Errorf("failed to load %s and no valid backup found: %w", s.path, cause)
 }
 
 // Save は予約データをファイルに保存する
@@ -53,12 +139,32 @@ func (s *Storage) Save() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	data, err := json.MarshalIndent(s.Reservations, "", "  ")
+	return s.writeLocked()
+}
+
+// Close はJSONバックエンドでは何もしない（保存はSaveで行う）
+func (s *Storage) Close() error {
+	return nil
+}
+
+// writeLocked は予約データをファイルに書き込む（呼び出し側でロックを保持すること）
+// 内容が変わる場合は、上書き前のファイルを直近の正常なデータとしてバックアップする
+func (s *Storage) writeLocked() error {
+	data, err := json.MarshalIndent(dataFile{
+		SchemaVersion: currentSchemaVersion,
+		Reservations:  s.reservations,
+	}, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(dataFilePath, data, 0644)
+	if current, err := os.ReadFile(s.path); err == nil && json.Valid(current) && !bytes.Equal(current, data) {
+		if err := backupFile(s.path, current); err != nil {
+			log.Printf("⚠️ Failed to back up %s: %v", s.path, err)
+		}
+	}
+
+	return writeFileAtomic(s.path, data, 0644)
 }
 
 // AddReservation は新しい予約を追加する
@@ -66,11 +172,11 @@ func (s *Storage) AddReservation(reservation *models.Reservation) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.Reservations[reservation.ID]; exists {
-		return errors.New("reservation with this ID already exists")
+	if _, exists := s.reservations[reservation.ID]; exists {
+		return ErrAlreadyExists
 	}
 
-	s.Reservations[reservation.ID] = reservation
+	s.reservations[reservation.ID] = reservation.Clone()
 	return nil
 }
 
@@ -79,12 +185,12 @@ func (s *Storage) GetReservation(id string) (*models.Reservation, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	reservation, exists := s.Reservations[id]
+	reservation, exists := s.reservations[id]
 	if !exists {
-		return nil, errors.New("reservation not found")
+		return nil, ErrNotFound
 	}
 
-	return reservation, nil
+	return reservation.Clone(), nil
 }
 
 // UpdateReservation は予約情報を更新する
@@ -92,22 +198,50 @@ func (s *Storage) UpdateReservation(reservation *models.Reservation) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.Reservations[reservation.ID]; !exists {
-		return errors.New("reservation not found")
+	stored, exists := s.reservations[reservation.ID]
+	if !exists {
+		return ErrNotFound
+	}
+	if err := checkHistoryAppendOnly(stored, reservation); err != nil {
+		return err
 	}
 
-	s.Reservations[reservation.ID] = reservation
+	s.reservations[reservation.ID] = reservation.Clone()
 	return nil
 }
 
+// Mutate は指定されたIDの予約をロック内で変更し、変更後のコピーを返す
+// fn がエラーを返した場合、予約は変更されない
+func (s *Storage) Mutate(id string, fn func(*models.Reservation) error) (*models.Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservation, exists := s.reservations[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	updated := reservation.Clone()
+	if err := fn(updated); err != nil {
+		return nil, err
+	}
+	updated.ID = id
+	if err := checkHistoryAppendOnly(reservation, updated); err != nil {
+		return nil, err
+	}
+
+	s.reservations[id] = updated
+	return updated.Clone(), nil
+}
+
 // GetAllReservations はすべての予約を取得する
 func (s *Storage) GetAllReservations() []*models.Reservation {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	reservations := make([]*models.Reservation, 0, len(s.Reservations))
-	for _, r := range s.Reservations {
-		reservations = append(reservations, r)
+	reservations := make([]*models.Reservation, 0, len(s.reservations))
+	for _, r := range s.reservations {
+		reservations = append(reservations, r.Clone())
 	}
 
 	return reservations
@@ -119,21 +253,41 @@ func (s *Storage) GetUserReservations(userID string) []*models.Reservation {
 	defer s.mu.RUnlock()
 
 	reservations := make([]*models.Reservation, 0)
-	for _, r := range s.Reservations {
+	for _, r := range s.reservations {
 		if r.UserID == userID {
-			reservations = append(reservations, r)
+			reservations = append(reservations, r.Clone())
 		}
 	}
 
 	return reservations
 }
 
+// QueryReservations は条件に一致する予約を取得する
+func (s *Storage) QueryReservations(query Query) ([]*models.Reservation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reservations := make([]*models.Reservation, 0)
+	for _, r := range s.reservations {
+		if query.Matches(r) {
+			reservations = append(reservations, r.Clone())
+		}
+	}
+
+	return reservations, nil
+}
+
 // CheckOverlap は時間の重複をチェックする
 func (s *Storage) CheckOverlap(newReservation *models.Reservation) (*models.Reservation, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	for _, existing := range s.Reservations {
+	return s.checkOverlapLocked(newReservation)
+}
+
+// checkOverlapLocked は時間の重複をチェックする（呼び出し側でロックを保持すること）
+func (s *Storage) checkOverlapLocked(newReservation *models.Reservation) (*models.Reservation, error) {
+	for _, existing := range s.reservations {
 		// 同じIDの場合はスキップ
 		if existing.ID == newReservation.ID {
 			continue
@@ -145,27 +299,76 @@ func (s *Storage) CheckOverlap(newReservation *models.Reservation) (*models.Rese
 		}
 
 		if overlaps {
-			return existing, nil
+			return existing.Clone(), nil
 		}
 	}
 
 	return nil, nil
 }
 
+// ReserveIfFree は重複する予約がなければ予約を追加する
+// 重複チェックと追加は同じロック内で行われ、重複があれば追加せずにその予約を返す
+func (s *Storage) ReserveIfFree(reservation *models.Reservation) (*models.Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.reservations[reservation.ID]; exists {
+		return nil, ErrAlreadyExists
+	}
+
+	conflict, err := s.checkOverlapLocked(reservation)
+	if err != nil || conflict != nil {
+		return conflict, err
+	}
+
+	s.reservations[reservation.ID] = reservation.Clone()
+	return nil, nil
+}
+
+// UpdateIfFree は指定されたIDの予約をロック内で変更し、重複する予約がなければ保存して変更後のコピーを返す
+// 変更・重複チェック・更新は同じロック内で行われ、重複があれば更新せずにその予約を返す
+// fn がエラーを返した場合、予約は変更されない
+func (s *Storage) UpdateIfFree(id string, fn func(*models.Reservation) error) (updated, conflict *models.Reservation, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, exists := s.reservations[id]
+	if !exists {
+		return nil, nil, ErrNotFound
+	}
+
+	updated = stored.Clone()
+	if err := fn(updated); err != nil {
+		return nil, nil, err
+	}
+	updated.ID = id
+	if err := checkHistoryAppendOnly(stored, updated); err != nil {
+		return nil, nil, err
+	}
+
+	conflict, err = s.checkOverlapLocked(updated)
+	if err != nil || conflict != nil {
+		return nil, conflict, err
+	}
+
+	s.reservations[id] = updated
+	return updated.Clone(), nil, nil
+}
+
 // DeleteReservation は指定されたIDの予約を削除する
 func (s *Storage) DeleteReservation(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.Reservations[id]; !exists {
-		return errors.New("reservation not found")
+	if _, exists := s.reservations[id]; !exists {
+		return ErrNotFound
 	}
 
-	delete(s.Reservations, id)
+	delete(s.reservations, id)
 	return nil
 }
 
-// AutoCompleteExpiredReservations は終了時刻が過ぎたpending予約を自動的にcompletedに変更する
+// AutoCompleteExpiredReservations は終了時刻が過ぎた予約中・利用中の予約を自動的にcompletedに変更する
 func (s *Storage) AutoCompleteExpiredReservations() (int, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -173,9 +376,9 @@ func (s *Storage) AutoCompleteExpiredReservations() (int, error) {
 	now := time.Now()
 	count := 0
 
-	for _, reservation := range s.Reservations {
-		// pending状態の予約のみ対象
-		if reservation.Status != models.StatusPending {
+	for _, reservation := range s.reservations {
+		// 予約中・利用中の予約のみ対象
+		if !reservation.Status.IsActive() {
 			continue
 		}
 
@@ -187,19 +390,17 @@ func (s *Storage) AutoCompleteExpiredReservations() (int, error) {
 
 		// 終了時刻が過ぎていればcompletedに変更
 		if endDateTime.Before(now) {
+			before := reservation.Clone()
 			reservation.Status = models.StatusCompleted
 			reservation.UpdatedAt = now
+			reservation.RecordHistory(before, autoCompleteHistoryEntry(now))
 			count++
 		}
 	}
 
 	// 変更があった場合は即座に保存
 	if count > 0 {
-		data, err := json.MarshalIndent(s.Reservations, "", "  ")
-		if err != nil {
-			return count, err
-		}
-		if err := os.WriteFile(dataFilePath, data, 0644); err != nil {
+		if err := s.writeLocked(); err != nil {
 			return count, err
 		}
 	}
@@ -207,7 +408,7 @@ func (s *Storage) AutoCompleteExpiredReservations() (int, error) {
 	return count, nil
 }
 
-// CleanupOldReservations は古い完了済み・キャンセル済み予約を削除する
+// CleanupOldReservations は古い完了済み・キャンセル済み・無断欠席の予約を削除する
 // retentionDays: 保持期間（日数）
 func (s *Storage) CleanupOldReservations(retentionDays int) (int, error) {
 	s.mu.Lock()
@@ -218,9 +419,9 @@ func (s *Storage) CleanupOldReservations(retentionDays int) (int, error) {
 	count := 0
 	idsToDelete := make([]string, 0)
 
-	for id, reservation := range s.Reservations {
-		// 完了済みまたはキャンセル済みの予約のみ対象
-		if reservation.Status != models.StatusCompleted && reservation.Status != models.StatusCancelled {
+	for id, reservation := range s.reservations {
+		// 完了済み・キャンセル済み・無断欠席の予約のみ対象
+		if reservation.Status.IsActive() {
 			continue
 		}
 
@@ -233,16 +434,12 @@ func (s *Storage) CleanupOldReservations(retentionDays int) (int, error) {
 
 	// 削除を実行
 	for _, id := range idsToDelete {
-		delete(s.Reservations, id)
+		delete(s.reservations, id)
 	}
 
 	// 削除があった場合は即座に保存
 	if count > 0 {
-		data, err := json.MarshalIndent(s.Reservations, "", "  ")
-		if err != nil {
-			return count, err
-		}
-		if err := os.WriteFile(dataFilePath, data, 0644); err != nil {
+		if err := s.writeLocked(); err != nil {
 			return count, err
 		}
 	}
@@ -1,6 +1,13 @@
 package storage
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -8,7 +15,7 @@ import (
 )
 
 func TestAutoCompleteExpiredReservations(t *testing.T) {
-	store := NewStorage()
+	store := newTestStorage(t)
 
 	// 過去の予約を作成（終了時刻が過ぎている）
 	pastReservation := &models.Reservation{
@@ -97,7 +104,7 @@ func TestAutoCompleteExpiredReservations(t *testing.T) {
 }
 
 func TestCleanupOldReservations(t *testing.T) {
-	store := NewStorage()
+	store := newTestStorage(t)
 
 	// 31日前に完了した予約（削除されるはず）
 	oldCompleted := &models.Reservation{
@@ -198,7 +205,7 @@ func TestCleanupOldReservations(t *testing.T) {
 }
 
 func TestDeleteReservation(t *testing.T) {
-	store := NewStorage()
+	store := newTestStorage(t)
 
 	// テスト用予約を作成
 	reservation := &models.Reservation{
@@ -244,3 +251,374 @@ func TestDeleteReservation(t *testing.T) {
 		t.Error("Expected error when deleting non-existent reservation")
 	}
 }
+
+func TestSaveKeepsRollingBackups(t *testing.T) {
+	store := newTestStorage(t)
+	dir := filepath.Dir(store.path)
+
+	for n := 0; n < maxBackups+3; n++ {
+		r := newTestReservation(fmt.Sprintf("backup-%d", n), "user1", "2030-01-10", "10:00", "11:00")
+		if err := store.AddReservation(r); err != nil {
+			t.Fatalf("Failed to add reservation: %v", err)
+		}
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	// 変更がない保存ではバックアップを増やさない
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	backups, err := listBackups(store.path)
+	if err != nil {
+		t.Fatalf("listBackups failed: %v", err)
+	}
+	if len(backups) != maxBackups {
+		t.Errorf("Expected %d backups, got %d", maxBackups, len(backups))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Errorf("Temporary file left behind: %s", entry.Name())
+		}
+	}
+}
+
+func TestLoadRecoversFromBackup(t *testing.T) {
+	store := newTestStorage(t)
+
+	for _, id := range []string{"recover-1", "recover-2"} {
+		if err := store.AddReservation(newTestReservation(id, "user1", "2030-01-10", "10:00", "11:00")); err != nil {
+			t.Fatalf("Failed to add reservation: %v", err)
+		}
+		if err := store.Save(); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	// 書き込み途中で途切れたファイルを再現する
+	if err := os.WriteFile(store.path, []byte(`{"recover-1": {"id": "rec`), 0644); err != nil {
+		t.Fatalf("Failed to corrupt data file: %v", err)
+	}
+
+	reloaded := NewStorageWithPath(store.path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load should recover from backup: %v", err)
+	}
+
+	// 最新のバックアップは1件目を保存した時点の内容
+	if _, err := reloaded.GetReservation("recover-1"); err != nil {
+		t.Errorf("Expected recover-1 to be restored: %v", err)
+	}
+
+	matches, err := filepath.Glob(store.path + ".corrupt-*")
+	if err != nil || len(matches) != 1 {
+		t.Errorf("Expected corrupted file to be preserved, got %v (%v)", matches, err)
+	}
+
+	// 復元後のデータファイルは正常に読み込める
+	again := NewStorageWithPath(store.path)
+	if err := again.Load(); err != nil {
+		t.Errorf("Restored data file should load: %v", err)
+	}
+}
+
+func TestLoadFailsWithoutBackup(t *testing.T) {
+	store := newTestStorage(t)
+	if err := os.WriteFile(store.path, []byte("{broken"), 0644); err != nil {
+		t.Fatalf("Failed to write data file: %v", err)
+	}
+
+	if err := store.Load(); err == nil {
+		t.Error("Expected Load to fail when no backup exists")
+	}
+}
+
+func TestReserveIfFreeConcurrent(t *testing.T) {
+	const workers = 50
+
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			succeeded := make([]string, 0)
+			conflicts := 0
+
+			start := make(chan struct{})
+			for n := 0; n < workers; n++ {
+				wg.Add(1)
+				go func(n int) {
+					defer wg.Done()
+					<-start
+
+					// 全員が少しずつずれた同じ時間帯を狙う
+					r := newTestReservation(fmt.Sprintf("race-%d", n), fmt.Sprintf("user%d", n), "2030-02-01", "14:00", "15:00")
+					if n%2 == 1 {
+						r.StartTime, r.EndTime = "14:30", "15:30"
+					}
+
+					conflict, err := repo.ReserveIfFree(r)
+					if err != nil {
+						t.Errorf("ReserveIfFree failed: %v", err)
+						return
+					}
+
+					mu.Lock()
+					defer mu.Unlock()
+					if conflict == nil {
+						succeeded = append(succeeded, r.ID)
+					} else {
+						conflicts++
+					}
+				}(n)
+			}
+			close(start)
+			wg.Wait()
+
+			if len(succeeded) != 1 {
+				t.Fatalf("Expected exactly 1 reservation to succeed, got %d (%v)", len(succeeded), succeeded)
+			}
+			if conflicts != workers-1 {
+				t.Errorf("Expected %d conflicts, got %d", workers-1, conflicts)
+			}
+
+			stored, err := repo.QueryReservations(Query{DateFrom: "2030-02-01", DateTo: "2030-02-01"})
+			if err != nil {
+				t.Fatalf("QueryReservations failed: %v", err)
+			}
+			if len(stored) != 1 || stored[0].ID != succeeded[0] {
+				t.Errorf("Expected only %s to be stored, got %d reservation(s)", succeeded[0], len(stored))
+			}
+		})
+	}
+}
+
+func TestUpdateIfFreeConcurrent(t *testing.T) {
+	const workers = 20
+
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			// 別々の時間帯にある予約を、全員が同じ空き時間帯へ同時に移動しようとする
+			for n := 0; n < workers; n++ {
+				r := newTestReservation(fmt.Sprintf("move-%d", n), "user1", "2030-03-01", fmt.Sprintf("%02d:00", n), fmt.Sprintf("%02d:30", n))
+				if err := repo.AddReservation(r); err != nil {
+					t.Fatalf("AddReservation failed: %v", err)
+				}
+			}
+
+			var wg sync.WaitGroup
+			var moved int32
+			start := make(chan struct{})
+			for n := 0; n < workers; n++ {
+				wg.Add(1)
+				go func(n int) {
+					defer wg.Done()
+					<-start
+
+					_, conflict, err := repo.UpdateIfFree(fmt.Sprintf("move-%d", n), func(r *models.Reservation) error {
+						r.Date = "2030-03-02"
+						r.StartTime = "10:00"
+						r.EndTime = "12:00"
+						return nil
+					})
+					if err != nil {
+						t.Errorf("UpdateIfFree failed: %v", err)
+						return
+					}
+					if conflict == nil {
+						atomic.AddInt32(&moved, 1)
+					}
+				}(n)
+			}
+			close(start)
+			wg.Wait()
+
+			if moved != 1 {
+				t.Errorf("Expected exactly 1 reservation to be moved, got %d", moved)
+			}
+
+			if _, _, err := repo.UpdateIfFree("missing", func(r *models.Reservation) error { return nil }); err != ErrNotFound {
+				t.Errorf("Expected ErrNotFound for missing reservation, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdateIfFreeKeepsOtherChanges(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := repo.AddReservation(newTestReservation("keep-1", "user1", "2030-03-05", "10:00", "11:00")); err != nil {
+				t.Fatalf("AddReservation failed: %v", err)
+			}
+
+			// 編集を始めた後にリマインダーの送信が記録されても、編集で取り消されない
+			remindedAt := time.Date(2030, 3, 5, 9, 0, 0, 0, time.UTC)
+			if _, err := repo.Mutate("keep-1", func(r *models.Reservation) error {
+				r.RemindedAt = &remindedAt
+				return nil
+			}); err != nil {
+				t.Fatalf("Mutate failed: %v", err)
+			}
+
+			updated, conflict, err := repo.UpdateIfFree("keep-1", func(r *models.Reservation) error {
+				r.Comment = "edited"
+				return nil
+			})
+			if err != nil || conflict != nil {
+				t.Fatalf("UpdateIfFree failed: conflict=%v err=%v", conflict, err)
+			}
+			if updated.Comment != "edited" {
+				t.Errorf("Expected returned comment %q, got %q", "edited", updated.Comment)
+			}
+
+			got, _ := repo.GetReservation("keep-1")
+			if got.Comment != "edited" || got.RemindedAt == nil || !got.RemindedAt.Equal(remindedAt) {
+				t.Errorf("Expected comment and reminder to be kept, got comment %q and reminded_at %v", got.Comment, got.RemindedAt)
+			}
+
+			// fn がエラーを返した場合は変更されない
+			errStop := errors.New("stop")
+			if _, _, err := repo.UpdateIfFree("keep-1", func(r *models.Reservation) error {
+				r.Comment = "discarded"
+				return errStop
+			}); err != errStop {
+				t.Errorf("Expected fn error to be returned, got %v", err)
+			}
+			if got, _ := repo.GetReservation("keep-1"); got.Comment != "edited" {
+				t.Errorf("Expected comment to be unchanged, got %q", got.Comment)
+			}
+		})
+	}
+}
+
+func TestStorageReturnsCopies(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			original := newTestReservation("copy-1", "user1", "2030-04-01", "10:00", "11:00")
+			if err := repo.AddReservation(original); err != nil {
+				t.Fatalf("AddReservation failed: %v", err)
+			}
+
+			// 追加後に呼び出し側のポインタを変更しても保存済みデータは変わらない
+			original.Comment = "changed after add"
+
+			got, err := repo.GetReservation("copy-1")
+			if err != nil {
+				t.Fatalf("GetReservation failed: %v", err)
+			}
+			got.Status = models.StatusCancelled
+
+			for _, r := range repo.GetAllReservations() {
+				r.Status = models.StatusCompleted
+			}
+
+			stored, err := repo.GetReservation("copy-1")
+			if err != nil {
+				t.Fatalf("GetReservation failed: %v", err)
+			}
+			if stored.Status != models.StatusPending || stored.Comment != "" {
+				t.Errorf("Stored reservation was modified through a returned copy: %+v", stored)
+			}
+		})
+	}
+}
+
+func TestMutate(t *testing.T) {
+	for name, repo := range newTestRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := repo.AddReservation(newTestReservation("mutate-1", "user1", "2030-04-01", "10:00", "11:00")); err != nil {
+				t.Fatalf("AddReservation failed: %v", err)
+			}
+
+			updated, err := repo.Mutate("mutate-1", func(r *models.Reservation) error {
+				r.Status = models.StatusCancelled
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Mutate failed: %v", err)
+			}
+			if updated.Status != models.StatusCancelled {
+				t.Errorf("Expected returned reservation to be cancelled, got %s", updated.Status)
+			}
+
+			// fnがエラーを返した場合は変更されない
+			rejected := errors.New("rejected")
+			_, err = repo.Mutate("mutate-1", func(r *models.Reservation) error {
+				r.Status = models.StatusCompleted
+				return rejected
+			})
+			if err != rejected {
+				t.Errorf("Expected fn error to be returned, got %v", err)
+			}
+
+			stored, err := repo.GetReservation("mutate-1")
+			if err != nil {
+				t.Fatalf("GetReservation failed: %v", err)
+			}
+			if stored.Status != models.StatusCancelled {
+				t.Errorf("Expected status to stay cancelled, got %s", stored.Status)
+			}
+
+			if _, err := repo.Mutate("missing", func(r *models.Reservation) error { return nil }); err != ErrNotFound {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+// TestConcurrentMutationAndBackgroundJobs は go test -race で実行して、
+// ハンドラーからの変更とバックグラウンドジョブが競合しないことを確認する
+func TestConcurrentMutationAndBackgroundJobs(t *testing.T) {
+	store := newTestStorage(t)
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	for n := 0; n < 20; n++ {
+		r := newTestReservation(fmt.Sprintf("bg-%d", n), "user1", yesterday, fmt.Sprintf("%02d:00", n), fmt.Sprintf("%02d:30", n))
+		if err := store.AddReservation(r); err != nil {
+			t.Fatalf("AddReservation failed: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < 20; n++ {
+		wg.Add(3)
+		go func(n int) {
+			defer wg.Done()
+			store.Mutate(fmt.Sprintf("bg-%d", n), func(r *models.Reservation) error {
+				r.Status = models.StatusCancelled
+				r.UpdatedAt = time.Now()
+				return nil
+			})
+		}(n)
+		go func() {
+			defer wg.Done()
+			if _, err := store.AutoCompleteExpiredReservations(); err != nil {
+				t.Errorf("AutoCompleteExpiredReservations failed: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for _, r := range store.GetAllReservations() {
+				_ = r.Status
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range store.GetAllReservations() {
+		if r.Status == models.StatusPending {
+			t.Errorf("Reservation %s should not remain pending", r.ID)
+		}
+	}
+}
+
+// newTestStorage は一時ディレクトリにデータファイルを置くStorageを作成する
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+	return NewStorageWithPath(filepath.Join(t.TempDir(), "reservations.json"))
+}
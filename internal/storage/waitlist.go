@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+// DefaultWaitlistPath はキャンセル待ちファイルの既定のパス
+const DefaultWaitlistPath = "data/waitlist.json"
+
+// Waitlist はキャンセル待ちをJSONファイルで管理する
+type Waitlist struct {
+	mu      sync.RWMutex
+	path    string
+	entries map[string]*models.WaitlistEntry
+}
+
+// NewWaitlist は指定したファイルを使うキャンセル待ちを作成する
+func NewWaitlist(path string) *Waitlist {
+	return &Waitlist{
+		path:    path,
+		entries: make(map[string]*models.WaitlistEntry),
+	}
+}
+
+// Load はファイルからキャンセル待ちを読み込む（ファイルがない場合は空）
+func (w *Waitlist) Load() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data, err := os.ReadFile(w.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var list []*models.WaitlistEntry
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", w.path, err)
+	}
+	entries := make(map[string]*models.WaitlistEntry, len(list))
+	for _, e := range list {
+		entries[e.ID] = e
+	}
+	w.entries = entries
+	return nil
+}
+
+// Entries はすべてのキャンセル待ちのコピーを登録の古い順に返す
+func (w *Waitlist) Entries() []*models.WaitlistEntry {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return sortedEntries(w.entries)
+}
+
+// Get は指定したIDのキャンセル待ちのコピーを返す
+func (w *Waitlist) Get(id string) (*models.WaitlistEntry, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	e, ok := w.entries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return e.Clone(), nil
+}
+
+// Add はキャンセル待ちを追加してファイルに書き込む
+func (w *Waitlist) Add(entry *models.WaitlistEntry) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	entries := w.copyEntries()
+	entries[entry.ID] = entry.Clone()
+	return w.write(entries)
+}
+
+// Update は指定したIDのキャンセル待ちを fn で変更してファイルに書き込み、変更後のコピーを返す
+// fn がエラーを返した場合は変更せずにそのエラーを返す
+func (w *Waitlist) Update(id string, fn func(*models.WaitlistEntry) error) (*models.WaitlistEntry, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	e, ok := w.entries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	updated := e.Clone()
+	if err := fn(updated); err != nil {
+		return nil, err
+	}
+	entries := w.copyEntries()
+	entries[id] = updated
+	if err := w.write(entries); err != nil {
+		return nil, err
+	}
+	return updated.Clone(), nil
+}
+
+// Remove は指定したIDのキャンセル待ちを削除してファイルに書き込む（ない場合は何もせず false を返す）
+func (w *Waitlist) Remove(id string) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.entries[id]; !ok {
+		return false, nil
+	}
+	entries := w.copyEntries()
+	delete(entries, id)
+	if err := w.write(entries); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// copyEntries は現在のキャンセル待ちの一覧を複製する（書き込みに失敗した場合に元の一覧を残すため）
+// 呼び出し側でロックを取得しておくこと
+func (w *Waitlist) copyEntries() map[string]*models.WaitlistEntry {
+	entries := make(map[string]*models.WaitlistEntry, len(w.entries)+1)
+	for id, e := range w.entries {
+		entries[id] = e
+	}
+	return entries
+}
+
+// write は一覧を登録の古い順にファイルに書き込み、成功した場合に置き換える
+// 呼び出し側でロックを取得しておくこと
+func (w *Waitlist) write(entries map[string]*models.WaitlistEntry) error {
+	data, err := json.MarshalIndent(sortedEntries(entries), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(w.path, data, 0644); err != nil {
+		return err
+	}
+	w.entries = entries
+	return nil
+}
+
+// sortedEntries はキャンセル待ちのコピーを登録の古い順（同時刻はID順）に並べて返す
+func sortedEntries(entries map[string]*models.WaitlistEntry) []*models.WaitlistEntry {
+	list := make([]*models.WaitlistEntry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, e.Clone())
+	}
+	sort.Slice(list, func(a, b int) bool {
+		if !list[a].CreatedAt.Equal(list[b].CreatedAt) {
+			return list[a].CreatedAt.Before(list[b].CreatedAt)
+		}
+		return list[a].ID < list[b].ID
+	})
+	return list
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dice/hxs_reservation_system/internal/models"
+)
+
+func TestWaitlistPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "waitlist.json")
+	waitlist := NewWaitlist(path)
+	if err := waitlist.Load(); err != nil {
+		t.Fatalf("Load without a file failed: %v", err)
+	}
+
+	created := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	for idx, id := range []string{"later", "first"} {
+		entry := &models.WaitlistEntry{ID: id, UserID: "user1", Date: "2030-01-11", StartTime: "14:00", EndTime: "15:00", CreatedAt: created.Add(-time.Duration(idx) * time.Minute)}
+		if err := waitlist.Add(entry); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	expiresAt := created.Add(15 * time.Minute)
+	if _, err := waitlist.Update("first", func(e *models.WaitlistEntry) error {
+		e.OfferExpiresAt = &expiresAt
+		return nil
+	}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := waitlist.Update("missing", func(*models.WaitlistEntry) error { return nil }); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound for a missing entry, got %v", err)
+	}
+
+	// 登録の古い順に読み込まれる
+	reloaded := NewWaitlist(path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	entries := reloaded.Entries()
+	if len(entries) != 2 || entries[0].ID != "first" || entries[1].ID != "later" {
+		t.Fatalf("Unexpected entries after reload: %+v", entries)
+	}
+	if entries[0].OfferExpiresAt == nil || !entries[0].OfferExpiresAt.Equal(expiresAt) {
+		t.Errorf("Expected the offer to be persisted, got %+v", entries[0])
+	}
+
+	if removed, err := reloaded.Remove("first"); err != nil || !removed {
+		t.Fatalf("Remove failed: %v (removed %v)", err, removed)
+	}
+	if _, err := reloaded.Get("first"); err != ErrNotFound {
+		t.Errorf("Expected the removed entry to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,370 @@
+package timeparse
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidDate は日付として解釈できない入力
+	ErrInvalidDate = errors.New("invalid date")
+	// ErrInvalidClock は時刻として解釈できない入力
+	ErrInvalidClock = errors.New("invalid time")
+	// ErrInvalidDuration は時間の長さとして解釈できない入力
+	ErrInvalidDuration = errors.New("invalid duration")
+)
+
+// Result は日時の入力を解釈した結果（日付と時刻の片方だけが含まれる場合もある）
+type Result struct {
+	Date    time.Time // 日付（now と同じタイムゾーンの0時、HasDate が false の場合はゼロ値）
+	HasDate bool
+	Clock   string // 時刻（HH:MM形式、HasTime が false の場合は空）
+	HasTime bool
+}
+
+// Normalize は全角の英数字・記号・空白を半角にし、小文字にして前後の空白を取り除く
+func Normalize(input string) string {
+	var builder strings.Builder
+	for _, c := range input {
+		switch {
+		case c >= '！' && c <= '～':
+			c -= '！' - '!'
+		case c == '　':
+			c = ' '
+		}
+		builder.WriteRune(c)
+	}
+	return strings.Join(strings.Fields(strings.ToLower(builder.String())), " ")
+}
+
+// ParseDateTime は日付と時刻を含む入力を解釈する（例: "金曜 15時"、"明日の15時半"、"next fri 3pm"）
+// 日付だけ・時刻だけの入力も受け付ける
+func ParseDateTime(input string, now time.Time) (Result, error) {
+	value := Normalize(input)
+	if value == "" {
+		return Result{}, ErrInvalidDate
+	}
+	if date, err := parseDate(value, now); err == nil {
+		return Result{Date: date, HasDate: true}, nil
+	}
+
+	// 末尾からできるだけ長い部分を時刻として解釈し、残りを日付として解釈する
+	runes := []rune(value)
+	for split := 0; split < len(runes); split++ {
+		clock, err := parseClock(strings.TrimSpace(string(runes[split:])))
+		if err != nil {
+			continue
+		}
+		rest := strings.TrimSpace(string(runes[:split]))
+		rest = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(rest, "の"), " at"), ","))
+		if rest == "" {
+			return Result{Clock: clock, HasTime: true}, nil
+		}
+		if date, err := parseDate(rest, now); err == nil {
+			return Result{Date: date, HasDate: true, Clock: clock, HasTime: true}, nil
+		}
+	}
+	return Result{}, ErrInvalidDate
+}
+
+// ParseDate は日付の入力を解釈する（例: "2025/10/15"、"10/15"、"10月15日"、"明日"、"来週火曜"、"next fri"、"3日後"）
+// 年を省略した日付は、今日以降で最も近い日とする
+func ParseDate(input string, now time.Time) (time.Time, error) {
+	return parseDate(Normalize(input), now)
+}
+
+// ParseClock は時刻の入力を解釈して HH:MM 形式にする（例: "14:00"、"9:5"、"1530"、"15時半"、"午後3時"、"3pm"、"正午"）
+func ParseClock(input string) (string, error) {
+	return parseClock(Normalize(input))
+}
+
+// ParseDuration は時間の長さの入力を解釈する（例: "60"、"90分"、"1:30"、"1時間30分"、"2時間"、"1.5h"、"2h 15m"、数字のみの場合は分）
+func ParseDuration(input string) (time.Duration, error) {
+	value := strings.ReplaceAll(Normalize(input), " ", "")
+	if minutes, err := strconv.Atoi(value); err == nil {
+		return time.Duration(minutes) * time.Minute, nil
+	}
+	if hours, minutes, found := strings.Cut(value, ":"); found {
+		h, errH := strconv.Atoi(hours)
+		m, errM := strconv.Atoi(minutes)
+		if errH != nil || errM != nil || m >= 60 {
+			return 0, ErrInvalidDuration
+		}
+		return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+	}
+	match := durationPattern.FindStringSubmatch(value)
+	if match == nil || value == "" {
+		return 0, ErrInvalidDuration
+	}
+	var duration time.Duration
+	if match[1] != "" {
+		hours, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return 0, ErrInvalidDuration
+		}
+		duration += time.Duration(hours * float64(time.Hour))
+	}
+	if match[2] != "" {
+		minutes, _ := strconv.Atoi(match[2])
+		duration += time.Duration(minutes) * time.Minute
+	}
+	return duration, nil
+}
+
+// durationPattern は「1時間30分」「1.5h」「90分」形式の時間の長さに一致する
+var durationPattern = regexp.MustCompile(`^(?:(\d+(?:\.\d+)?)(?:時間|hours?|hrs?|h))?(?:(\d+)(?:分|minutes?|mins?|m))?$`)
+
+// 日付の入力形式
+var (
+	fullDatePattern    = regexp.MustCompile(`^(\d{4})[-/.](\d{1,2})[-/.](\d{1,2})$`)
+	fullDateJPPattern  = regexp.MustCompile(`^(\d{4})年(\d{1,2})月(\d{1,2})日?$`)
+	monthDayPattern    = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})$`)
+	monthDayJPPattern  = regexp.MustCompile(`^(\d{1,2})月(\d{1,2})日?$`)
+	dayJPPattern       = regexp.MustCompile(`^(\d{1,2})日$`)
+	daysLaterPattern   = regexp.MustCompile(`^(?:(\d+)日後|in (\d+) days?)$`)
+	weeksLaterPattern  = regexp.MustCompile(`^(?:(\d+)週間後|in (\d+) weeks?)$`)
+	weekdayJPPattern   = regexp.MustCompile(`^(今週|来週|再来週)?の?([月火水木金土日])(?:曜日?)?$`)
+	weekdayENPattern   = regexp.MustCompile(`^(?:(this|next|next week|week after next)\s+)?([a-z]+)\.?$`)
+	monthNameDayENExpr = regexp.MustCompile(`^([a-z]+)\.? (\d{1,2})(?:st|nd|rd|th)?$`)
+	dayMonthNameENExpr = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)? ([a-z]+)\.?$`)
+)
+
+// relativeDays は今日からの日数で表す日付の言葉
+var relativeDays = map[string]int{
+	"今日": 0, "きょう": 0, "本日": 0, "today": 0,
+	"明日": 1, "あした": 1, "あす": 1, "tomorrow": 1, "tmr": 1, "tmrw": 1,
+	"明後日": 2, "あさって": 2, "day after tomorrow": 2, "the day after tomorrow": 2,
+	"来週": 7, "next week": 7,
+}
+
+// weekdaysJP は曜日の漢字
+var weekdaysJP = map[string]time.Weekday{
+	"日": time.Sunday, "月": time.Monday, "火": time.Tuesday, "水": time.Wednesday,
+	"木": time.Thursday, "金": time.Friday, "土": time.Saturday,
+}
+
+// weekdaysEN は英語の曜日（略称を含む）
+var weekdaysEN = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "weds": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// weekOffsets は「今週」「来週」などの週の指定（今週からの週数）
+var weekOffsets = map[string]int{
+	"今週": 0, "this": 0,
+	"来週": 1, "next": 1, "next week": 1,
+	"再来週": 2, "week after next": 2,
+}
+
+// parseDate は正規化済みの入力を日付として解釈する
+func parseDate(value string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if days, ok := relativeDays[value]; ok {
+		return today.AddDate(0, 0, days), nil
+	}
+	if match := daysLaterPattern.FindStringSubmatch(value); match != nil {
+		days, _ := strconv.Atoi(match[1] + match[2])
+		return today.AddDate(0, 0, days), nil
+	}
+	if match := weeksLaterPattern.FindStringSubmatch(value); match != nil {
+		weeks, _ := strconv.Atoi(match[1] + match[2])
+		return today.AddDate(0, 0, 7*weeks), nil
+	}
+
+	for _, pattern := range []*regexp.Regexp{fullDatePattern, fullDateJPPattern} {
+		if match := pattern.FindStringSubmatch(value); match != nil {
+			year, _ := strconv.Atoi(match[1])
+			return makeDate(year, match[2], match[3], now.Location())
+		}
+	}
+	for _, pattern := range []*regexp.Regexp{monthDayPattern, monthDayJPPattern} {
+		if match := pattern.FindStringSubmatch(value); match != nil {
+			return nextMonthDay(today, match[1], match[2])
+		}
+	}
+	if match := dayJPPattern.FindStringSubmatch(value); match != nil {
+		day, _ := strconv.Atoi(match[1])
+		// 今月のその日が過ぎていれば来月（その日がない月は飛ばす）
+		for offset := 0; offset <= 2; offset++ {
+			first := time.Date(today.Year(), today.Month()+time.Month(offset), 1, 0, 0, 0, 0, now.Location())
+			date := first.AddDate(0, 0, day-1)
+			if date.Month() == first.Month() && !date.Before(today) {
+				return date, nil
+			}
+		}
+		return time.Time{}, ErrInvalidDate
+	}
+
+	if match := weekdayJPPattern.FindStringSubmatch(value); match != nil {
+		// 「来週金」のような省略は週の指定がある場合のみ、「金」だけの入力は受け付けない
+		if match[1] == "" && !strings.Contains(value, "曜") {
+			return time.Time{}, ErrInvalidDate
+		}
+		return weekdayDate(today, weekdaysJP[match[2]], match[1]), nil
+	}
+	if match := weekdayENPattern.FindStringSubmatch(value); match != nil {
+		if weekday, ok := weekdaysEN[match[2]]; ok {
+			return weekdayDate(today, weekday, match[1]), nil
+		}
+	}
+
+	if match := monthNameDayENExpr.FindStringSubmatch(value); match != nil {
+		if month, ok := monthName(match[1]); ok {
+			return nextMonthDay(today, strconv.Itoa(int(month)), match[2])
+		}
+	}
+	if match := dayMonthNameENExpr.FindStringSubmatch(value); match != nil {
+		if month, ok := monthName(match[2]); ok {
+			return nextMonthDay(today, strconv.Itoa(int(month)), match[1])
+		}
+	}
+	return time.Time{}, ErrInvalidDate
+}
+
+// weekdayDate は曜日の指定を日付にする
+// 週の指定がない場合は今日以降で最も近いその曜日、指定がある場合は月曜始まりの週のその曜日とする
+func weekdayDate(today time.Time, weekday time.Weekday, week string) time.Time {
+	if week == "" {
+		return today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7)
+	}
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	return monday.AddDate(0, 0, 7*weekOffsets[week]+(int(weekday)+6)%7)
+}
+
+// nextMonthDay は年を省略した月日を、今日以降で最も近い日付にする
+func nextMonthDay(today time.Time, month, day string) (time.Time, error) {
+	date, err := makeDate(today.Year(), month, day, today.Location())
+	if err != nil {
+		// 2月29日は次のうるう年まで探す
+		for year := today.Year() + 1; year <= today.Year()+4 && err != nil; year++ {
+			date, err = makeDate(year, month, day, today.Location())
+		}
+		return date, err
+	}
+	if date.Before(today) {
+		if next, err := makeDate(today.Year()+1, month, day, today.Location()); err == nil {
+			return next, nil
+		}
+	}
+	return date, nil
+}
+
+// makeDate は年月日から日付を作成する（存在しない日付はエラー）
+func makeDate(year int, month, day string, loc *time.Location) (time.Time, error) {
+	m, errM := strconv.Atoi(month)
+	d, errD := strconv.Atoi(day)
+	if errM != nil || errD != nil || m < 1 || m > 12 || d < 1 {
+		return time.Time{}, ErrInvalidDate
+	}
+	date := time.Date(year, time.Month(m), d, 0, 0, 0, 0, loc)
+	if date.Month() != time.Month(m) || date.Day() != d {
+		return time.Time{}, ErrInvalidDate
+	}
+	return date, nil
+}
+
+// monthName は英語の月名（3文字以上の略称を含む）を月にする
+func monthName(name string) (time.Month, bool) {
+	if len(name) < 3 {
+		return 0, false
+	}
+	for month := time.January; month <= time.December; month++ {
+		full := strings.ToLower(month.String())
+		if strings.HasPrefix(full, name) || (name == "sept" && month == time.September) {
+			return month, true
+		}
+	}
+	return 0, false
+}
+
+// 時刻の入力形式
+var (
+	colonClockPattern   = regexp.MustCompile(`^(\d{1,2}):(\d{1,2})$`)
+	compactClockPattern = regexp.MustCompile(`^(\d{1,2})(\d{2})$`)
+	// 「午後3」のように「時」も分もない入力は受け付けない
+	jpClockPattern = regexp.MustCompile(`^(午前|午後|朝|昼|夕方|夜)?(\d{1,2})(?:時(?:(半)|(\d{1,2})分?)?|:(\d{2}))$`)
+	enClockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))? ?(am|pm|a\.m\.|p\.m\.)$`)
+)
+
+// namedClocks は時刻を表す言葉
+var namedClocks = map[string]string{
+	"正午": "12:00", "noon": "12:00", "midday": "12:00",
+	"midnight": "00:00",
+}
+
+// parseClock は正規化済みの入力を時刻として解釈する
+func parseClock(value string) (string, error) {
+	if clock, ok := namedClocks[value]; ok {
+		return clock, nil
+	}
+
+	var hour, minute int
+	switch {
+	case colonClockPattern.MatchString(value):
+		match := colonClockPattern.FindStringSubmatch(value)
+		hour, _ = strconv.Atoi(match[1])
+		minute, _ = strconv.Atoi(match[2])
+	case compactClockPattern.MatchString(value):
+		match := compactClockPattern.FindStringSubmatch(value)
+		hour, _ = strconv.Atoi(match[1])
+		minute, _ = strconv.Atoi(match[2])
+	case jpClockPattern.MatchString(value):
+		match := jpClockPattern.FindStringSubmatch(value)
+		hour, _ = strconv.Atoi(match[2])
+		switch {
+		case match[3] != "":
+			minute = 30
+		case match[4] != "":
+			minute, _ = strconv.Atoi(match[4])
+		case match[5] != "":
+			minute, _ = strconv.Atoi(match[5])
+		}
+		if match[1] != "" && hour > 12 {
+			return "", ErrInvalidClock
+		}
+		switch match[1] {
+		case "午前", "朝":
+			if hour == 12 {
+				hour = 0
+			}
+		case "午後", "昼", "夕方":
+			if hour < 12 {
+				hour += 12
+			}
+		case "夜":
+			// 「夜12時」は0時、「夜1時」のような深夜の時刻はそのまま
+			if hour == 12 {
+				hour = 0
+			} else if hour >= 6 {
+				hour += 12
+			}
+		}
+	case enClockPattern.MatchString(value):
+		match := enClockPattern.FindStringSubmatch(value)
+		hour, _ = strconv.Atoi(match[1])
+		minute, _ = strconv.Atoi(match[2])
+		if hour < 1 || hour > 12 {
+			return "", ErrInvalidClock
+		}
+		hour %= 12
+		if strings.HasPrefix(match[3], "p") {
+			hour += 12
+		}
+	default:
+		return "", ErrInvalidClock
+	}
+
+	if hour > 23 || minute > 59 {
+		return "", ErrInvalidClock
+	}
+	return time.Date(2000, 1, 1, hour, minute, 0, 0, time.UTC).Format("15:04"), nil
+}
@@ -0,0 +1,288 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+)
+
+// referenceNow はテストの基準日時（2030/05/15 水曜 10:00）
+var referenceNow = time.Date(2030, 5, 15, 10, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string // 空の場合はエラー
+	}{
+		// 数字の形式
+		{"2030-05-20", "2030-05-20"},
+		{"2030/05/20", "2030-05-20"},
+		{"2030/5/2", "2030-05-02"},
+		{"2030.5.20", "2030-05-20"},
+		{"２０３０／０５／２０", "2030-05-20"},
+		{"2030年5月20日", "2030-05-20"},
+		{"2030年5月20", "2030-05-20"},
+		{"5/20", "2030-05-20"},
+		{"5/15", "2030-05-15"},
+		{"5/14", "2031-05-14"}, // 過ぎた日は来年
+		{"12/31", "2030-12-31"},
+		{"5月20日", "2030-05-20"},
+		{"1月3日", "2031-01-03"},
+		{"20日", "2030-05-20"},
+		{"15日", "2030-05-15"},
+		{"1日", "2030-06-01"},
+		{"31日", "2030-05-31"},
+		{"2/29", "2032-02-29"}, // 次のうるう年
+		{"2030/02/29", ""},
+		{"2030/13/01", ""},
+		{"13/01", ""},
+		{"32日", ""},
+
+		// 相対的な日付
+		{"今日", "2030-05-15"},
+		{"きょう", "2030-05-15"},
+		{"本日", "2030-05-15"},
+		{"today", "2030-05-15"},
+		{"Today", "2030-05-15"},
+		{"明日", "2030-05-16"},
+		{"あした", "2030-05-16"},
+		{"あす", "2030-05-16"},
+		{"tomorrow", "2030-05-16"},
+		{"tmr", "2030-05-16"},
+		{"明後日", "2030-05-17"},
+		{"あさって", "2030-05-17"},
+		{"day after tomorrow", "2030-05-17"},
+		{"3日後", "2030-05-18"},
+		{"３日後", "2030-05-18"},
+		{"in 10 days", "2030-05-25"},
+		{"in 1 day", "2030-05-16"},
+		{"2週間後", "2030-05-29"},
+		{"in 2 weeks", "2030-05-29"},
+		{"来週", "2030-05-22"},
+		{"next week", "2030-05-22"},
+
+		// 曜日（今日は水曜）
+		{"金曜", "2030-05-17"},
+		{"金曜日", "2030-05-17"},
+		{"水曜", "2030-05-15"}, // 今日
+		{"火曜", "2030-05-21"}, // 今日以降で最も近い火曜
+		{"今週月曜", "2030-05-13"},
+		{"今週の日曜", "2030-05-19"},
+		{"来週火曜", "2030-05-21"},
+		{"来週の金曜日", "2030-05-24"},
+		{"来週金", "2030-05-24"},
+		{"来週月曜", "2030-05-20"},
+		{"再来週水曜", "2030-05-29"},
+		{"fri", "2030-05-17"},
+		{"Friday", "2030-05-17"},
+		{"tue", "2030-05-21"},
+		{"wed", "2030-05-15"},
+		{"this fri", "2030-05-17"},
+		{"this mon", "2030-05-13"},
+		{"next fri", "2030-05-24"},
+		{"next week fri", "2030-05-24"},
+		{"next thurs", "2030-05-23"},
+		{"week after next mon", "2030-05-27"},
+		{"金", ""},
+		{"next", ""},
+		{"someday", ""},
+
+		// 英語の月名
+		{"May 20", "2030-05-20"},
+		{"may 20th", "2030-05-20"},
+		{"Jan 3", "2031-01-03"},
+		{"sept 1", "2030-09-01"},
+		{"1st june", "2030-06-01"},
+		{"20 december", "2030-12-20"},
+		{"ma 20", ""},
+
+		{"", ""},
+		{"2030-05", ""},
+		{"15時", ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.input, referenceNow)
+		if tt.expected == "" {
+			if err == nil {
+				t.Errorf("ParseDate(%q) = %s, expected an error", tt.input, got.Format("2006-01-02"))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDate(%q) returned an error: %v", tt.input, err)
+			continue
+		}
+		if got.Format("2006-01-02") != tt.expected {
+			t.Errorf("ParseDate(%q) = %s, expected %s", tt.input, got.Format("2006-01-02"), tt.expected)
+		}
+		if got.Location() != referenceNow.Location() || got.Hour() != 0 || got.Minute() != 0 {
+			t.Errorf("ParseDate(%q) = %v, expected midnight in the reference location", tt.input, got)
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string // 空の場合はエラー
+	}{
+		// 数字の形式
+		{"14:00", "14:00"},
+		{"9:00", "09:00"},
+		{"9:5", "09:05"},
+		{"09:30", "09:30"},
+		{"0:00", "00:00"},
+		{"23:59", "23:59"},
+		{"１４：３０", "14:30"},
+		{"1530", "15:30"},
+		{"930", "09:30"},
+		{"24:00", ""},
+		{"12:60", ""},
+		{"2400", ""},
+		{"15", ""},
+
+		// 日本語
+		{"15時", "15:00"},
+		{"15時半", "15:30"},
+		{"15時30分", "15:30"},
+		{"15時05分", "15:05"},
+		{"15時5分", "15:05"},
+		{"9時", "09:00"},
+		{"0時", "00:00"},
+		{"１５時半", "15:30"},
+		{"午後3時", "15:00"},
+		{"午後3時半", "15:30"},
+		{"午後12時", "12:00"},
+		{"午後0時", "12:00"},
+		{"午前10時", "10:00"},
+		{"午前12時", "00:00"},
+		{"午後3:15", "15:15"},
+		{"朝9時", "09:00"},
+		{"昼1時", "13:00"},
+		{"夕方5時", "17:00"},
+		{"夜9時", "21:00"},
+		{"夜12時", "00:00"},
+		{"夜1時", "01:00"},
+		{"正午", "12:00"},
+		{"午後15時", ""},
+		{"25時", ""},
+		{"午後3", ""},
+
+		// 英語
+		{"3pm", "15:00"},
+		{"3 pm", "15:00"},
+		{"3PM", "15:00"},
+		{"3:30pm", "15:30"},
+		{"3:30 p.m.", "15:30"},
+		{"10am", "10:00"},
+		{"12am", "00:00"},
+		{"12pm", "12:00"},
+		{"noon", "12:00"},
+		{"midnight", "00:00"},
+		{"13pm", ""},
+		{"0am", ""},
+
+		{"", ""},
+		{"abc", ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseClock(tt.input)
+		if tt.expected == "" {
+			if err == nil {
+				t.Errorf("ParseClock(%q) = %s, expected an error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseClock(%q) returned an error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseClock(%q) = %s, expected %s", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestParseDateTime(t *testing.T) {
+	tests := []struct {
+		input string
+		date  string // 空の場合は日付なし
+		clock string // 空の場合は時刻なし
+		err   bool
+	}{
+		{input: "金曜 15時", date: "2030-05-17", clock: "15:00"},
+		{input: "金曜15時", date: "2030-05-17", clock: "15:00"},
+		{input: "明日の15時半", date: "2030-05-16", clock: "15:30"},
+		{input: "明日 午後3時", date: "2030-05-16", clock: "15:00"},
+		{input: "来週火曜 10:00", date: "2030-05-21", clock: "10:00"},
+		{input: "来週火曜夜9時", date: "2030-05-21", clock: "21:00"},
+		{input: "5月20日14時", date: "2030-05-20", clock: "14:00"},
+		{input: "2030/05/20 9:30", date: "2030-05-20", clock: "09:30"},
+		{input: "5/20 1530", date: "2030-05-20", clock: "15:30"},
+		{input: "next fri 3pm", date: "2030-05-24", clock: "15:00"},
+		{input: "tomorrow at noon", date: "2030-05-16", clock: "12:00"},
+		{input: "fri, 10:30am", date: "2030-05-17", clock: "10:30"},
+		{input: "May 20 3:30 pm", date: "2030-05-20", clock: "15:30"},
+		{input: "明日", date: "2030-05-16"},
+		{input: "2030-05-20", date: "2030-05-20"},
+		{input: "15時半", clock: "15:30"},
+		{input: "3pm", clock: "15:00"},
+		{input: "明日 15", err: true},
+		{input: "someday 3pm", err: true},
+		{input: "", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDateTime(tt.input, referenceNow)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseDateTime(%q) = %+v, expected an error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDateTime(%q) returned an error: %v", tt.input, err)
+			continue
+		}
+		date := ""
+		if got.HasDate {
+			date = got.Date.Format("2006-01-02")
+		}
+		if date != tt.date || got.Clock != tt.clock || got.HasTime != (tt.clock != "") {
+			t.Errorf("ParseDateTime(%q) = %q %q, expected %q %q", tt.input, date, got.Clock, tt.date, tt.clock)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		err      bool
+	}{
+		{input: "60", expected: time.Hour},
+		{input: "90分", expected: 90 * time.Minute},
+		{input: "1:30", expected: 90 * time.Minute},
+		{input: "2時間", expected: 2 * time.Hour},
+		{input: "２時間", expected: 2 * time.Hour},
+		{input: "1時間30分", expected: 90 * time.Minute},
+		{input: "1.5h", expected: 90 * time.Minute},
+		{input: "2h 15m", expected: 135 * time.Minute},
+		{input: "2 hours", expected: 2 * time.Hour},
+		{input: "1hr 30min", expected: 90 * time.Minute},
+		{input: "45 minutes", expected: 45 * time.Minute},
+		{input: "1:60", err: true},
+		{input: "abc", err: true},
+		{input: "", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.input)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseDuration(%q) = %v, expected an error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("ParseDuration(%q) = %v, %v, expected %v", tt.input, got, err, tt.expected)
+		}
+	}
+}